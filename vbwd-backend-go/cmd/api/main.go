@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/dantweb/vbwd-backend-go/internal/config"
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/openapi"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/server"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// Build metadata, injected at build time with
+// -ldflags "-X main.version=<version> -X main.commit=<sha> -X main.buildTime=<time>".
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildTime = "unknown"
+)
+
+// loadConfig reads the configuration from the file named by CONFIG_FILE
+// when it is set, and from the environment alone otherwise.
+func loadConfig() (config.Config, error) {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return config.LoadFromFile(path)
+	}
+	return config.Load()
+}
+
+// oidcDiscoveryTimeout bounds each request to the OpenID Connect provider.
+const oidcDiscoveryTimeout = 10 * time.Second
+
+func main() {
+	startTime := time.Now()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.LogLevel})))
+	if cfg.JWTSecretEphemeral {
+		slog.Warn("JWT_SECRET is not set; signing tokens with an ephemeral secret that is lost on restart")
+	}
+
+	// Services
+	tokenService, err := newTokenService(cfg, services.TokenOptions{
+		AccessTTL:  cfg.AccessTokenTTL,
+		RefreshTTL: cfg.RefreshTokenTTL,
+		Leeway:     cfg.TokenLeeway,
+	})
+	if err != nil {
+		log.Fatalf("Invalid signing key: %v", err)
+	}
+	// Tokens carry the ID of their login session and stop validating once
+	// it is revoked. The JWKS is published from the signing service.
+	signingService := tokenService
+	sessionStore := repository.NewInMemorySessionStore()
+	var redisClient *redis.Client
+	if cfg.RedisAddr != "" {
+		redisClient = redis.NewClient(&redis.Options{Addr: cfg.RedisAddr, Password: cfg.RedisPassword})
+		defer redisClient.Close()
+		sessionStore = repository.NewRedisSessionStore(redisClient, cfg.RefreshTokenTTL)
+	}
+	sessionService := services.NewSessionService(sessionStore, cfg.RefreshTokenTTL, nil)
+	tokenService = services.NewSessionTokenService(signingService, sessionService, services.SessionTokenOptions{
+		Policy:  services.RevocationPolicy(cfg.RevocationFailurePolicy),
+		Timeout: cfg.SessionStoreTimeout,
+	})
+	loginThrottler := services.NewLoginThrottler(services.DefaultMaxFailedAttempts, services.DefaultLockoutWindow, nil)
+	seed := []models.User{services.DemoUser()}
+	if cfg.SeedUsersFile != "" {
+		if seed, err = services.LoadSeedFile(cfg.SeedUsersFile); err != nil {
+			log.Fatalf("Invalid user seed: %v", err)
+		}
+	}
+	hasher, err := services.NewHasher(cfg.PasswordHasher, cfg.BcryptCost)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	userRepository, err := services.NewSeededUserRepository(seed, hasher)
+	if err != nil {
+		log.Fatalf("Invalid user seed: %v", err)
+	}
+	if cfg.UserCacheTTL > 0 {
+		userRepository = repository.NewCachingUserRepository(userRepository, repository.CacheOptions{
+			Capacity: cfg.UserCacheCapacity,
+			TTL:      cfg.UserCacheTTL,
+		})
+	}
+	// Protected endpoints also accept API keys in X-API-Key.
+	apiKeyService := services.NewAPIKeyService(repository.NewInMemoryAPIKeyStore(), userRepository, nil)
+	tokenService = services.NewAPIKeyTokenService(tokenService, apiKeyService)
+	mfaService := services.NewMFAService(repository.NewInMemoryMFAStore(), cfg.ServiceName, nil)
+	authService := services.NewAuthService(
+		services.WithRepository(userRepository),
+		services.WithTokenService(tokenService),
+		services.WithThrottler(loginThrottler),
+		services.WithHasher(hasher),
+		services.WithLogger(slog.Default()),
+		services.WithSessions(sessionService),
+		services.WithAPIKeys(apiKeyService),
+		services.WithMFA(mfaService),
+		services.WithRefreshTokens(cfg.IssueRefreshTokens),
+	)
+	userService := services.NewUserService(userRepository, sessionService, apiKeyService)
+	passwordResetService := services.NewPasswordResetService(userRepository, repository.NewInMemoryResetTokenStore(), hasher, services.DefaultPasswordPolicy(), cfg.ResetTokenTTL, newNotifier(cfg), nil, nil, sessionService, apiKeyService)
+	healthService := services.NewHealthService(cfg.ServiceName, version, startTime, nil)
+	healthService.SetReadinessCacheTTL(cfg.ReadinessCacheTTL)
+	healthService.SetHistorySize(cfg.ReadinessHistorySize)
+	if redisClient != nil {
+		healthService.RegisterCheck("redis", func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+	}
+	if cfg.MinFreeDiskBytes > 0 || cfg.MinFreeMemoryBytes > 0 {
+		healthService.RegisterCheck("resources", services.NewResourceChecker(services.ResourceThresholds{
+			DiskPath:           cfg.DiskCheckPath,
+			MinFreeDiskBytes:   uint64(cfg.MinFreeDiskBytes),
+			MinFreeMemoryBytes: uint64(cfg.MinFreeMemoryBytes),
+		}, services.ResourceStats{}))
+	}
+
+	// Handlers
+	auditLogger := services.NewLogAuditLogger(slog.Default())
+	authHandler := handlers.NewAuthHandler(authService, auditLogger)
+	authHandlerV2 := handlers.NewAuthHandlerV2(authService, tokenService, auditLogger)
+	healthHandler := handlers.NewHealthHandler(healthService)
+	versionHandler := handlers.NewVersionHandler(version, commit, buildTime)
+	userHandler := handlers.NewUserHandler(userService)
+	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	var jwksHandler *handlers.JWKSHandler
+	if keys, ok := signingService.(services.KeySetProvider); ok && cfg.JWTAlgorithm == services.SigningRS256 {
+		jwksHandler = handlers.NewJWKSHandler(keys)
+	}
+	var keyRotationHandler *handlers.KeyRotationHandler
+	if keys, ok := signingService.(services.KeyRotator); ok {
+		keyRotationHandler = handlers.NewKeyRotationHandler(keys, cfg.KeyRotationGrace)
+	}
+	var oauthHandler *handlers.OAuthHandler
+	if cfg.OIDCDiscoveryURL != "" {
+		provider, err := newOIDCProvider(cfg)
+		if err != nil {
+			log.Fatalf("Invalid OpenID Connect provider: %v", err)
+		}
+		oauthHandler = handlers.NewOAuthHandler(authService, provider)
+	}
+
+	// Routes
+	var hstsMaxAge time.Duration
+	if cfg.TLSEnabled() {
+		hstsMaxAge = cfg.HSTSMaxAge
+	}
+	handler := router.NewRouter(router.Dependencies{
+		AuthHandler:          authHandler,
+		AuthHandlerV2:        authHandlerV2,
+		HealthHandler:        healthHandler,
+		VersionHandler:       versionHandler,
+		UserHandler:          userHandler,
+		PasswordResetHandler: passwordResetHandler,
+		SessionHandler:       sessionHandler,
+		APIKeyHandler:        handlers.NewAPIKeyHandler(apiKeyService),
+		MFAHandler:           handlers.NewMFAHandler(mfaService, authService, tokenService, auditLogger),
+		OAuthHandler:         oauthHandler,
+		IntrospectionHandler: handlers.NewIntrospectionHandler(tokenService),
+		JWKSHandler:          jwksHandler,
+		KeyRotationHandler:   keyRotationHandler,
+		TokenService:         tokenService,
+		LoginIPBlocker:       services.NewIPBlocker(cfg.LoginMaxFailuresPerIP, cfg.LoginIPBlockWindow, nil),
+		OpenAPI:              openapi.New(cfg.ServiceName, version),
+		IdempotencyTTL:       cfg.IdempotencyTTL,
+		DisableRegistration:  !cfg.EnableRegistration,
+
+		RoutePrefix:  cfg.RoutePrefix,
+		PrefixProbes: cfg.PrefixProbes,
+
+		CORSAllowedOrigins:    cfg.CORSAllowedOrigins,
+		TrustedProxies:        cfg.TrustedProxies,
+		RedirectHTTPS:         cfg.RedirectHTTPS,
+		HSTSMaxAge:            hstsMaxAge,
+		MaxBodyBytes:          cfg.MaxBodyBytes,
+		MaxConcurrentRequests: cfg.MaxConcurrentRequests,
+		ConcurrencyWait:       cfg.ConcurrencyWait,
+		RequestTimeout:        cfg.RequestTimeout,
+	})
+
+	slog.Info("starting",
+		slog.String("service", cfg.ServiceName),
+		slog.String("version", version),
+		slog.String("addr", cfg.Addr()),
+		slog.String("environment", cfg.Environment),
+		slog.Bool("tls", cfg.TLSEnabled()),
+	)
+	for _, route := range handler.Routes() {
+		slog.Info("route", slog.String("method", route.Method), slog.String("pattern", route.Pattern))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := server.New(cfg.Addr(), handler, cfg.ShutdownTimeout, server.Timeouts{
+		ReadHeader: cfg.ReadHeaderTimeout,
+		Read:       cfg.ReadTimeout,
+		Write:      cfg.WriteTimeout,
+		Idle:       cfg.IdleTimeout,
+	})
+	if cfg.TLSEnabled() {
+		srv.EnableTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+	}
+	if err := srv.Run(ctx); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
+
+// newTokenService signs tokens with the HMAC secret or, for RS256, with the
+// RSA private key. A configured public key must belong to the private key;
+// verification keys from earlier rotations stay accepted.
+func newTokenService(cfg config.Config, opts services.TokenOptions) (services.TokenService, error) {
+	if cfg.JWTAlgorithm != services.SigningRS256 {
+		return services.NewTokenService(cfg.JWTSecret, opts), nil
+	}
+
+	privateKey, err := services.LoadRSAPrivateKey(cfg.JWTPrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.JWTPublicKeyFile != "" {
+		publicKey, err := services.LoadRSAPublicKey(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if !publicKey.Equal(&privateKey.PublicKey) {
+			return nil, errors.New("JWT_PUBLIC_KEY_FILE does not match JWT_PRIVATE_KEY_FILE")
+		}
+	}
+	for _, path := range cfg.JWTVerificationKeyFiles {
+		key, err := services.LoadRSAPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		opts.VerificationKeys = append(opts.VerificationKeys, key)
+	}
+	return services.NewRS256TokenService(privateKey, opts), nil
+}
+
+// newOIDCProvider reads the discovery document of the configured OpenID
+// Connect provider, giving up after oidcDiscoveryTimeout.
+func newOIDCProvider(cfg config.Config) (services.IdentityProvider, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), oidcDiscoveryTimeout)
+	defer cancel()
+
+	return services.NewOIDCProvider(ctx, services.OIDCConfig{
+		Name:         cfg.OIDCProviderName,
+		DiscoveryURL: cfg.OIDCDiscoveryURL,
+		ClientID:     cfg.OIDCClientID,
+		ClientSecret: cfg.OIDCClientSecret,
+		RedirectURL:  cfg.OIDCRedirectURL,
+		HTTPClient:   &http.Client{Timeout: oidcDiscoveryTimeout},
+	})
+}
+
+// newNotifier mails notifications when an SMTP relay is configured. Without
+// one, notifications are logged in development and dropped in production so
+// reset tokens never end up in production logs.
+func newNotifier(cfg config.Config) services.Notifier {
+	switch {
+	case cfg.SMTPHost != "":
+		return services.NewSMTPNotifier(services.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	case cfg.IsProduction():
+		slog.Warn("SMTP_HOST is not set; password reset emails are disabled")
+		return services.NopNotifier()
+	default:
+		return services.NewLogNotifier(slog.Default())
+	}
+}
@@ -0,0 +1,585 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults applied when the corresponding environment variable is unset.
+const (
+	DefaultPort        = "8082"
+	DefaultServiceName = "vbwd-backend-go"
+	DefaultEnvironment = EnvironmentDevelopment
+
+	DefaultShutdownTimeout   = 10 * time.Second
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultReadTimeout       = 15 * time.Second
+	DefaultWriteTimeout      = 15 * time.Second
+	DefaultIdleTimeout       = 60 * time.Second
+	DefaultRequestTimeout    = 10 * time.Second
+
+	DefaultMaxBodyBytes = 1 << 20
+	DefaultSMTPPort     = 587
+	DefaultBcryptCost   = 10
+
+	DefaultPasswordHasher = "bcrypt"
+	DefaultJWTAlgorithm   = "HS256"
+
+	DefaultRevocationFailurePolicy = "fail_closed"
+	DefaultSessionStoreTimeout     = 2 * time.Second
+
+	DefaultAccessTokenTTL  = time.Hour
+	DefaultRefreshTokenTTL = 24 * time.Hour
+	DefaultResetTokenTTL   = 15 * time.Minute
+	DefaultIdempotencyTTL  = 24 * time.Hour
+
+	DefaultHSTSMaxAge = 180 * 24 * time.Hour
+
+	DefaultDiskCheckPath = "/"
+
+	DefaultLoginMaxFailuresPerIP = 20
+	DefaultLoginIPBlockWindow    = 15 * time.Minute
+
+	DefaultOIDCProviderName = "oidc"
+
+	DefaultUserCacheCapacity = 1000
+	DefaultUserCacheTTL      = time.Minute
+
+	DefaultReadinessHistorySize = 50
+)
+
+// MinJWTSecretLength is the minimum length in bytes of JWT_SECRET in every
+// environment, matching the 256-bit output of HS256.
+const MinJWTSecretLength = 32
+
+// APP_ENV values. Every environment but development requires JWT_SECRET;
+// production also refuses other insecure settings.
+const (
+	EnvironmentDevelopment = "development"
+	EnvironmentProduction  = "production"
+)
+
+// Configuration errors.
+var (
+	ErrInvalidPort             = errors.New("PORT must be a number between 1 and 65535")
+	ErrJWTSecretRequired       = errors.New("JWT_SECRET is required outside development")
+	ErrJWTSecretTooShort       = fmt.Errorf("JWT_SECRET must be at least %d bytes", MinJWTSecretLength)
+	ErrInvalidJWTAlgorithm     = errors.New("JWT_ALGORITHM must be HS256 or RS256")
+	ErrJWTPrivateKeyRequired   = errors.New("JWT_PRIVATE_KEY_FILE is required for RS256")
+	ErrInvalidDuration         = errors.New("invalid duration")
+	ErrInvalidNumber           = errors.New("invalid number")
+	ErrInvalidBool             = errors.New("invalid boolean")
+	ErrInvalidTrustedProxy     = errors.New("TRUSTED_PROXIES entries must be IP addresses or CIDR ranges")
+	ErrInvalidLogLevel         = errors.New("LOG_LEVEL must be debug, info, warn or error")
+	ErrInvalidRevocationPolicy = errors.New("REVOCATION_FAILURE_POLICY must be fail_open or fail_closed")
+	ErrSMTPFromRequired        = errors.New("SMTP_FROM is required when SMTP_HOST is set")
+	ErrTLSPairIncomplete       = errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	ErrOIDCIncomplete          = errors.New("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_DISCOVERY_URL is set")
+)
+
+// Config holds the runtime configuration of the service.
+type Config struct {
+	Port        string
+	ServiceName string
+	JWTSecret   string
+	Environment string
+	// LogLevel is the minimum level of log entries. At debug, access log
+	// entries include redacted request and response bodies.
+	LogLevel slog.Level
+	// JWTSecretEphemeral reports that JWT_SECRET was unset in development
+	// and JWTSecret was generated at random. Tokens signed with it do not
+	// survive a restart.
+	JWTSecretEphemeral bool
+
+	// JWTAlgorithm selects token signing: "HS256" with JWTSecret or
+	// "RS256" with the RSA key in JWTPrivateKeyFile. JWTPublicKeyFile
+	// optionally names the matching public key used for verification.
+	JWTAlgorithm      string
+	JWTPrivateKeyFile string
+	JWTPublicKeyFile  string
+	// JWTVerificationKeyFiles are further RSA public keys accepted for
+	// RS256 tokens and published in the JWKS, e.g. during key rotation.
+	JWTVerificationKeyFiles []string
+
+	ShutdownTimeout time.Duration
+
+	// HTTP server timeouts guarding against slow clients.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// RequestTimeout bounds how long a handler may run before the client
+	// gets 503; zero disables it.
+	RequestTimeout time.Duration
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	TokenLeeway     time.Duration
+	// IssueRefreshTokens sets whether logins get a refresh token when the
+	// client chooses no grant.
+	IssueRefreshTokens bool
+	// KeyRotationGrace is how long tokens signed with a key keep verifying
+	// after POST /admin/rotate-key replaced it.
+	KeyRotationGrace time.Duration
+
+	// EnableRegistration serves POST /register. When false the route is
+	// not registered and requests to it get 404.
+	EnableRegistration bool
+	// RevocationFailurePolicy decides whether tokens are accepted
+	// ("fail_open") or rejected ("fail_closed") when their session cannot
+	// be checked because the session store fails.
+	RevocationFailurePolicy string
+	// SessionStoreTimeout bounds the session lookup made for each token;
+	// a lookup that runs out of time counts as a store failure.
+	SessionStoreTimeout time.Duration
+
+	// A client IP with LoginMaxFailuresPerIP failed logins within
+	// LoginIPBlockWindow is blocked from logging in for that window.
+	LoginMaxFailuresPerIP int
+	LoginIPBlockWindow    time.Duration
+
+	// ResetTokenTTL is the lifetime of password reset tokens.
+	ResetTokenTTL time.Duration
+
+	// ReadinessCacheTTL is how long a readiness result is reused before
+	// the checks run again; zero disables caching.
+	ReadinessCacheTTL time.Duration
+	// ReadinessHistorySize is how many readiness evaluations are listed
+	// at GET /healthz/history.
+	ReadinessHistorySize int
+
+	// IdempotencyTTL is how long responses to requests carrying an
+	// Idempotency-Key are kept for replay.
+	IdempotencyTTL time.Duration
+
+	// UserCacheCapacity and UserCacheTTL bound the user lookup cache; a
+	// zero TTL disables it.
+	UserCacheCapacity int
+	UserCacheTTL      time.Duration
+
+	// PasswordHasher selects the algorithm for new password hashes
+	// ("bcrypt" or "argon2id").
+	PasswordHasher string
+	// BcryptCost is the work factor for new bcrypt hashes.
+	BcryptCost int
+
+	// TLS certificate and key files in PEM format. The server serves HTTPS
+	// with HTTP/2 when both are set and plain HTTP when neither is.
+	TLSCertFile string
+	TLSKeyFile  string
+	// HSTSMaxAge is the Strict-Transport-Security max-age sent when TLS is
+	// enabled; zero disables the header.
+	HSTSMaxAge time.Duration
+	// RedirectHTTPS answers plain HTTP requests, as reported by
+	// X-Forwarded-Proto, with a redirect to HTTPS.
+	RedirectHTTPS bool
+
+	// MaxConcurrentRequests caps the requests served at once; zero disables
+	// the cap. ConcurrencyWait is how long a request beyond the cap waits
+	// for a slot before it is rejected.
+	MaxConcurrentRequests int
+	ConcurrencyWait       time.Duration
+	// MaxBodyBytes limits the size of request bodies.
+	MaxBodyBytes int64
+
+	// Readiness fails when free space on DiskCheckPath or free memory
+	// under GOMEMLIMIT drops below these minimums; zero disables a check.
+	DiskCheckPath      string
+	MinFreeDiskBytes   int64
+	MinFreeMemoryBytes int64
+
+	// SeedUsersFile is an optional JSON file of users to seed the in-memory
+	// repository with instead of the demo user.
+	SeedUsersFile string
+
+	// RedisAddr is the host:port of a Redis server holding the login
+	// sessions, so that instances share them and a revocation on one
+	// instance applies to all. Sessions are kept in memory when it is
+	// empty.
+	RedisAddr     string
+	RedisPassword string
+
+	// SMTP relay used for notification emails. Notifications are only
+	// mailed when SMTPHost is set.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// OpenID Connect provider for GET /auth/{provider}/login, enabled when
+	// OIDCDiscoveryURL is set. OIDCProviderName is the {provider} segment.
+	OIDCProviderName string
+	OIDCDiscoveryURL string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+
+	// RoutePrefix is prepended to every route, e.g. "/api/v1". Health and
+	// readiness probes only get it when PrefixProbes is set.
+	RoutePrefix  string
+	PrefixProbes bool
+
+	// CORSAllowedOrigins lists origins allowed for cross-origin requests;
+	// "*" allows any origin.
+	CORSAllowedOrigins []string
+
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For is
+	// believed when they connect. Without any, the header is ignored and
+	// clients are identified by their connection address.
+	TrustedProxies []netip.Prefix
+}
+
+// Load reads the configuration from the environment, applies defaults and
+// validates the result.
+func Load() (Config, error) {
+	return load(os.Getenv)
+}
+
+// source returns the configuration value for an environment variable
+// name, or "" when it is unset.
+type source func(key string) string
+
+// load reads the configuration from src, applies defaults and validates
+// the result.
+func load(src source) (Config, error) {
+	cfg := Config{
+		Port:        src.getString("PORT", DefaultPort),
+		ServiceName: src.getString("SERVICE_NAME", DefaultServiceName),
+		JWTSecret:   src("JWT_SECRET"),
+		Environment: src.getString("APP_ENV", DefaultEnvironment),
+
+		JWTAlgorithm:      strings.ToUpper(src.getString("JWT_ALGORITHM", DefaultJWTAlgorithm)),
+		JWTPrivateKeyFile: src("JWT_PRIVATE_KEY_FILE"),
+		JWTPublicKeyFile:  src("JWT_PUBLIC_KEY_FILE"),
+
+		JWTVerificationKeyFiles: src.getList("JWT_VERIFICATION_KEY_FILES"),
+
+		SeedUsersFile: src("SEED_USERS_FILE"),
+		DiskCheckPath: src.getString("DISK_CHECK_PATH", DefaultDiskCheckPath),
+
+		TLSCertFile: src("TLS_CERT_FILE"),
+		TLSKeyFile:  src("TLS_KEY_FILE"),
+
+		PasswordHasher: src.getString("PASSWORD_HASHER", DefaultPasswordHasher),
+
+		RevocationFailurePolicy: strings.ToLower(src.getString("REVOCATION_FAILURE_POLICY", DefaultRevocationFailurePolicy)),
+
+		RedisAddr:     src("REDIS_ADDR"),
+		RedisPassword: src("REDIS_PASSWORD"),
+
+		SMTPHost:     src("SMTP_HOST"),
+		SMTPUsername: src("SMTP_USERNAME"),
+		SMTPPassword: src("SMTP_PASSWORD"),
+		SMTPFrom:     src("SMTP_FROM"),
+
+		OIDCProviderName: src.getString("OIDC_PROVIDER_NAME", DefaultOIDCProviderName),
+		OIDCDiscoveryURL: src("OIDC_DISCOVERY_URL"),
+		OIDCClientID:     src("OIDC_CLIENT_ID"),
+		OIDCClientSecret: src("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:  src("OIDC_REDIRECT_URL"),
+
+		RoutePrefix: src("ROUTE_PREFIX"),
+
+		CORSAllowedOrigins: src.getList("CORS_ALLOWED_ORIGINS"),
+	}
+
+	var err error
+	if cfg.ShutdownTimeout, err = src.getPositiveDuration("SHUTDOWN_TIMEOUT", DefaultShutdownTimeout); err != nil {
+		return Config{}, err
+	}
+	if cfg.ReadHeaderTimeout, err = src.getPositiveDuration("READ_HEADER_TIMEOUT", DefaultReadHeaderTimeout); err != nil {
+		return Config{}, err
+	}
+	if cfg.ReadTimeout, err = src.getPositiveDuration("READ_TIMEOUT", DefaultReadTimeout); err != nil {
+		return Config{}, err
+	}
+	if cfg.WriteTimeout, err = src.getPositiveDuration("WRITE_TIMEOUT", DefaultWriteTimeout); err != nil {
+		return Config{}, err
+	}
+	if cfg.IdleTimeout, err = src.getPositiveDuration("IDLE_TIMEOUT", DefaultIdleTimeout); err != nil {
+		return Config{}, err
+	}
+	if cfg.RequestTimeout, err = src.getDuration("REQUEST_TIMEOUT", DefaultRequestTimeout); err != nil {
+		return Config{}, err
+	}
+	if cfg.BcryptCost, err = src.getPositiveInt("BCRYPT_COST", DefaultBcryptCost); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxBodyBytes, err = src.getPositiveInt64("MAX_BODY_BYTES", DefaultMaxBodyBytes); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxConcurrentRequests, err = src.getInt("MAX_CONCURRENT_REQUESTS", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.ConcurrencyWait, err = src.getDuration("CONCURRENCY_WAIT", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.MinFreeDiskBytes, err = src.getInt64("MIN_FREE_DISK_BYTES", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.MinFreeMemoryBytes, err = src.getInt64("MIN_FREE_MEMORY_BYTES", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.SMTPPort, err = src.getPositiveInt("SMTP_PORT", DefaultSMTPPort); err != nil {
+		return Config{}, err
+	}
+	if cfg.PrefixProbes, err = src.getBool("PREFIX_PROBES", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.AccessTokenTTL, err = src.getPositiveDuration("ACCESS_TOKEN_TTL", DefaultAccessTokenTTL); err != nil {
+		return Config{}, err
+	}
+	if cfg.RefreshTokenTTL, err = src.getPositiveDuration("REFRESH_TOKEN_TTL", DefaultRefreshTokenTTL); err != nil {
+		return Config{}, err
+	}
+	if cfg.SessionStoreTimeout, err = src.getPositiveDuration("SESSION_STORE_TIMEOUT", DefaultSessionStoreTimeout); err != nil {
+		return Config{}, err
+	}
+	if cfg.TokenLeeway, err = src.getDuration("TOKEN_LEEWAY", 0); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.LogLevel.UnmarshalText([]byte(src.getString("LOG_LEVEL", "info"))); err != nil {
+		return Config{}, fmt.Errorf("%w: %q", ErrInvalidLogLevel, src("LOG_LEVEL"))
+	}
+	if cfg.IssueRefreshTokens, err = src.getBool("ISSUE_REFRESH_TOKENS", true); err != nil {
+		return Config{}, err
+	}
+	if cfg.EnableRegistration, err = src.getBool("ENABLE_REGISTRATION", true); err != nil {
+		return Config{}, err
+	}
+	if cfg.TrustedProxies, err = src.getPrefixList("TRUSTED_PROXIES"); err != nil {
+		return Config{}, err
+	}
+	if cfg.KeyRotationGrace, err = src.getPositiveDuration("KEY_ROTATION_GRACE", cfg.RefreshTokenTTL); err != nil {
+		return Config{}, err
+	}
+	if cfg.LoginMaxFailuresPerIP, err = src.getPositiveInt("LOGIN_MAX_FAILURES_PER_IP", DefaultLoginMaxFailuresPerIP); err != nil {
+		return Config{}, err
+	}
+	if cfg.LoginIPBlockWindow, err = src.getPositiveDuration("LOGIN_IP_BLOCK_WINDOW", DefaultLoginIPBlockWindow); err != nil {
+		return Config{}, err
+	}
+	if cfg.ResetTokenTTL, err = src.getPositiveDuration("RESET_TOKEN_TTL", DefaultResetTokenTTL); err != nil {
+		return Config{}, err
+	}
+	if cfg.IdempotencyTTL, err = src.getPositiveDuration("IDEMPOTENCY_TTL", DefaultIdempotencyTTL); err != nil {
+		return Config{}, err
+	}
+	if cfg.UserCacheCapacity, err = src.getPositiveInt("USER_CACHE_CAPACITY", DefaultUserCacheCapacity); err != nil {
+		return Config{}, err
+	}
+	if cfg.UserCacheTTL, err = src.getDuration("USER_CACHE_TTL", DefaultUserCacheTTL); err != nil {
+		return Config{}, err
+	}
+	if cfg.ReadinessCacheTTL, err = src.getDuration("READINESS_CACHE_TTL", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.ReadinessHistorySize, err = src.getPositiveInt("READINESS_HISTORY_SIZE", DefaultReadinessHistorySize); err != nil {
+		return Config{}, err
+	}
+	if cfg.HSTSMaxAge, err = src.getDuration("HSTS_MAX_AGE", DefaultHSTSMaxAge); err != nil {
+		return Config{}, err
+	}
+	if cfg.RedirectHTTPS, err = src.getBool("REDIRECT_HTTPS", false); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.JWTSecret == "" && cfg.JWTAlgorithm == "HS256" {
+		if cfg.JWTSecret, err = ephemeralSecret(); err != nil {
+			return Config{}, err
+		}
+		cfg.JWTSecretEphemeral = true
+	}
+	return cfg, nil
+}
+
+// Validate checks that the configuration values are usable.
+func (c Config) Validate() error {
+	port, err := strconv.Atoi(c.Port)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("%w: %q", ErrInvalidPort, c.Port)
+	}
+	switch c.JWTAlgorithm {
+	case "HS256":
+		if c.JWTSecret == "" {
+			if c.IsDevelopment() {
+				break
+			}
+			return ErrJWTSecretRequired
+		}
+		if len(c.JWTSecret) < MinJWTSecretLength {
+			return fmt.Errorf("%w: got %d", ErrJWTSecretTooShort, len(c.JWTSecret))
+		}
+	case "RS256":
+		if c.JWTPrivateKeyFile == "" {
+			return ErrJWTPrivateKeyRequired
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidJWTAlgorithm, c.JWTAlgorithm)
+	}
+	switch c.RevocationFailurePolicy {
+	case "fail_open", "fail_closed":
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidRevocationPolicy, c.RevocationFailurePolicy)
+	}
+	if c.SMTPHost != "" && c.SMTPFrom == "" {
+		return ErrSMTPFromRequired
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return ErrTLSPairIncomplete
+	}
+	if c.OIDCDiscoveryURL != "" && (c.OIDCClientID == "" || c.OIDCRedirectURL == "") {
+		return ErrOIDCIncomplete
+	}
+	return nil
+}
+
+// IsDevelopment reports whether the service runs in development mode, the
+// default, where an unset JWT_SECRET is replaced by an ephemeral one.
+func (c Config) IsDevelopment() bool {
+	return c.Environment == EnvironmentDevelopment
+}
+
+// IsProduction reports whether the service runs in production mode.
+func (c Config) IsProduction() bool {
+	return c.Environment == EnvironmentProduction
+}
+
+// ephemeralSecret returns a random HMAC secret of MinJWTSecretLength
+// bytes, hex-encoded.
+func ephemeralSecret() (string, error) {
+	secret := make([]byte, MinJWTSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate JWT secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// TLSEnabled reports whether the server should serve HTTPS.
+func (c Config) TLSEnabled() bool {
+	return c.TLSCertFile != ""
+}
+
+// Addr returns the listen address for the HTTP server.
+func (c Config) Addr() string {
+	return ":" + c.Port
+}
+
+func (s source) getString(key, fallback string) string {
+	if value := s(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getDuration accepts zero, which turns off the settings that document it.
+func (s source) getDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := s(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%w: %s=%q", ErrInvalidDuration, key, value)
+	}
+	return d, nil
+}
+
+// getPositiveDuration is getDuration for settings without an off value.
+func (s source) getPositiveDuration(key string, fallback time.Duration) (time.Duration, error) {
+	d, err := s.getDuration(key, fallback)
+	if err == nil && d == 0 {
+		return 0, fmt.Errorf("%w: %s=%q", ErrInvalidDuration, key, s(key))
+	}
+	return d, err
+}
+
+// getList splits a comma-separated variable, dropping empty entries.
+func (s source) getList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(s(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getPrefixList parses a comma-separated list of IP addresses and CIDR
+// ranges. A single address is a prefix covering only itself.
+func (s source) getPrefixList(key string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range s.getList(key) {
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(item)
+			if addrErr != nil {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidTrustedProxy, item)
+			}
+			addr = addr.Unmap()
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func (s source) getInt(key string, fallback int) (int, error) {
+	n, err := s.getInt64(key, int64(fallback))
+	return int(n), err
+}
+
+// getInt64 accepts zero, which turns off the settings that document it.
+func (s source) getInt64(key string, fallback int64) (int64, error) {
+	value := s(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s=%q", ErrInvalidNumber, key, value)
+	}
+	return n, nil
+}
+
+func (s source) getPositiveInt(key string, fallback int) (int, error) {
+	n, err := s.getPositiveInt64(key, int64(fallback))
+	return int(n), err
+}
+
+// getPositiveInt64 is getInt64 for settings without an off value.
+func (s source) getPositiveInt64(key string, fallback int64) (int64, error) {
+	n, err := s.getInt64(key, fallback)
+	if err == nil && n == 0 {
+		return 0, fmt.Errorf("%w: %s=%q", ErrInvalidNumber, key, s(key))
+	}
+	return n, err
+}
+
+func (s source) getBool(key string, fallback bool) (bool, error) {
+	value := s(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s=%q", ErrInvalidBool, key, value)
+	}
+	return b, nil
+}
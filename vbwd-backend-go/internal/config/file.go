@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration file errors.
+var (
+	ErrUnsupportedConfigFormat = errors.New("config file must end in .yaml, .yml or .json")
+	ErrUnknownConfigKey        = errors.New("unknown config key")
+	ErrInvalidConfigValue      = errors.New("config value must be a scalar or a list of scalars")
+)
+
+// LoadFromFile reads the configuration from a YAML or JSON file, chosen by
+// its extension, and applies defaults and validation like Load. File keys
+// are the environment variable names in any case, e.g. "port" or
+// "access_token_ttl", with the same value syntax; lists may also be
+// written as arrays. Environment variables that are set take precedence
+// over the file. Keys that name no setting are rejected.
+func LoadFromFile(path string) (Config, error) {
+	values, err := readConfigFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	read := make(map[string]bool, len(values))
+	cfg, err := load(func(key string) string {
+		read[key] = true
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+		return values[key]
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	var unknown []string
+	for key := range values {
+		if !read[key] {
+			unknown = append(unknown, strings.ToLower(key))
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return Config{}, fmt.Errorf("%s: %w: %s", path, ErrUnknownConfigKey, strings.Join(unknown, ", "))
+	}
+	return cfg, nil
+}
+
+// readConfigFile decodes the file at path into values keyed by upper-case
+// environment variable name.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&raw)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedConfigFormat, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		s, err := configValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w: %s", path, err, key)
+		}
+		values[strings.ToUpper(key)] = s
+	}
+	return values, nil
+}
+
+// configValue renders a decoded value in environment variable syntax.
+// Lists become comma-separated.
+func configValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, nested := item.([]any); nested {
+				return "", ErrInvalidConfigValue
+			}
+			s, err := configValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", ErrInvalidConfigValue
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// APIKeyHandler lets users create and revoke API keys.
+type APIKeyHandler struct {
+	keys services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler.
+func NewAPIKeyHandler(keys services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{keys: keys}
+}
+
+// Create handles POST /apikeys and returns the new key in plaintext, the
+// only time it is shown. The router rejects API keys here, so a leaked key
+// cannot be used to mint further keys.
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	req, ok := DecodeJSON[models.CreateAPIKeyRequest](w, r)
+	if !ok {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user := models.User{ID: claims.Subject, Username: claims.Username, Role: claims.Role}
+	resp, err := h.keys.Create(r.Context(), user, req.Name)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, resp)
+}
+
+// Revoke handles DELETE /apikeys/{id}. Requests with the key are rejected
+// from then on.
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	if err := h.keys.Revoke(r.Context(), claims.Subject, r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
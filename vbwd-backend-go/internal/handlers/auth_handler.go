@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// AuthHandler handles authentication HTTP requests.
+type AuthHandler struct {
+	authService services.AuthService
+	audit       services.AuditLogger
+}
+
+// NewAuthHandler creates a new AuthHandler that records login attempts with
+// audit. A nil audit uses services.NopAuditLogger().
+func NewAuthHandler(authService services.AuthService, audit services.AuditLogger) *AuthHandler {
+	if audit == nil {
+		audit = services.NopAuditLogger()
+	}
+	return &AuthHandler{authService: authService, audit: audit}
+}
+
+// Login handles POST /login.
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	req, ok := DecodeJSON[models.LoginRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx, ok := loginContext(w, r)
+	if !ok {
+		return
+	}
+	resp, err := h.authService.Authenticate(ctx, req.Username, req.Password)
+	recordLogin(h.audit, r, req.Username, resp, err)
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		response.JSON(w, http.StatusUnauthorized, models.LoginResponse{
+			Success: false,
+			Message: "Invalid credentials",
+		})
+		return
+	}
+	if errors.Is(err, models.ErrAccountLocked) {
+		response.JSON(w, http.StatusLocked, models.LoginResponse{
+			Success: false,
+			Message: "Account temporarily locked",
+		})
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Authentication failed")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// recordLogin audits the outcome of a password login. A login answered
+// with an MFA challenge is not complete yet and is left to POST
+// /mfa/login, which records whether the code was accepted.
+func recordLogin(audit services.AuditLogger, r *http.Request, username string, resp *models.LoginResponse, err error) {
+	if err == nil && resp.MFARequired {
+		return
+	}
+	audit.RecordLogin(r.Context(), username, err == nil, middleware.ClientIP(r))
+}
+
+// loginContext returns the request context carrying the client device and
+// the grant chosen with the grant query parameter. On an invalid grant it
+// writes the error response and returns false.
+func loginContext(w http.ResponseWriter, r *http.Request) (context.Context, bool) {
+	grant, err := models.ParseGrant(r.URL.Query().Get("grant"))
+	if err != nil {
+		writeError(w, err)
+		return nil, false
+	}
+	ctx := services.WithDevice(r.Context(), r.UserAgent())
+	return services.WithGrant(ctx, grant), true
+}
+
+// Refresh handles POST /refresh.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	req, ok := DecodeJSON[models.RefreshRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resp, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		response.JSON(w, http.StatusUnauthorized, models.LoginResponse{
+			Success: false,
+			Message: "Invalid refresh token",
+		})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// Register handles POST /register.
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	req, ok := DecodeJSON[models.RegisterRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, err := h.authService.Register(r.Context(), req.Username, req.Email, req.Password)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, models.RegisterResponse{
+		Success: true,
+		Message: "Registration successful",
+		UserID:  user.ID,
+	})
+}
+
+// ChangePassword handles POST /password for the authenticated user.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	req, ok := DecodeJSON[models.ChangePasswordRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.authService.ChangePassword(r.Context(), claims.Username, claims.SessionID, req.OldPassword, req.NewPassword); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WhoAmI handles GET /whoami and returns the identity carried by the
+// caller's access token.
+func (h *AuthHandler) WhoAmI(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	response.Render(w, r, http.StatusOK, models.UserDTO{
+		ID:       claims.Subject,
+		Username: claims.Username,
+		Role:     claims.Role,
+	})
+}
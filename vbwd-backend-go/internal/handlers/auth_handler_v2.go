@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// AuthHandlerV2 serves the version 2 authentication endpoints. It shares
+// the AuthService with AuthHandler and differs only in response shapes.
+type AuthHandlerV2 struct {
+	authService  services.AuthService
+	tokenService services.TokenService
+	audit        services.AuditLogger
+}
+
+// NewAuthHandlerV2 creates a new AuthHandlerV2. The TokenService is used to
+// read the expiry of issued tokens and login attempts are recorded with
+// audit. A nil audit uses services.NopAuditLogger().
+func NewAuthHandlerV2(authService services.AuthService, tokenService services.TokenService, audit services.AuditLogger) *AuthHandlerV2 {
+	if audit == nil {
+		audit = services.NopAuditLogger()
+	}
+	return &AuthHandlerV2{authService: authService, tokenService: tokenService, audit: audit}
+}
+
+// Login handles POST /v2/login. Failures use the standard error envelope.
+func (h *AuthHandlerV2) Login(w http.ResponseWriter, r *http.Request) {
+	req, ok := DecodeJSON[models.LoginRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx, ok := loginContext(w, r)
+	if !ok {
+		return
+	}
+	resp, err := h.authService.Authenticate(ctx, req.Username, req.Password)
+	recordLogin(h.audit, r, req.Username, resp, err)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if resp.MFARequired {
+		mfaToken, err := h.tokenInfo(resp.MFAToken)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "Authentication failed")
+			return
+		}
+		response.JSON(w, http.StatusOK, models.LoginResponseV2{
+			Success:     true,
+			Message:     resp.Message,
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		})
+		return
+	}
+
+	token, err := h.tokenInfo(resp.Token)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Authentication failed")
+		return
+	}
+	v2 := models.LoginResponseV2{
+		Success: true,
+		Message: resp.Message,
+		Token:   token,
+	}
+	if resp.RefreshToken != "" {
+		if v2.RefreshToken, err = h.tokenInfo(resp.RefreshToken); err != nil {
+			response.Error(w, http.StatusInternalServerError, "Authentication failed")
+			return
+		}
+	}
+
+	response.JSON(w, http.StatusOK, v2)
+}
+
+// tokenInfo wraps a freshly issued token with its expiry.
+func (h *AuthHandlerV2) tokenInfo(token string) (*models.TokenInfo, error) {
+	claims, err := h.tokenService.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TokenInfo{
+		Value:     token,
+		ExpiresAt: claims.ExpiresAt.Time.UTC(),
+		Type:      models.TokenTypeBearer,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// MaxJSONBodyBytes is the largest request body DecodeJSON reads. Smaller
+// limits set by middleware.MaxBodySize still apply.
+const MaxJSONBodyBytes = middleware.DefaultMaxBodyBytes
+
+// DecodeJSON decodes the request body into a T. Requests whose
+// Content-Type is not application/json get 415, bodies over
+// MaxJSONBodyBytes 413, and malformed JSON or fields T does not declare
+// 400. On failure the error response is written and DecodeJSON returns
+// false, so the handler only has to return.
+func DecodeJSON[T any](w http.ResponseWriter, r *http.Request) (T, bool) {
+	var dst T
+	if !middleware.IsJSONContentType(r.Header.Get("Content-Type")) {
+		response.Error(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return dst, false
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxJSONBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&dst); err != nil {
+		writeDecodeError(w, err)
+		return dst, false
+	}
+	return dst, true
+}
+
+// writeDecodeError writes the response for a failed decode: 413 for
+// oversized bodies, 400 otherwise.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		response.Error(w, http.StatusRequestEntityTooLarge, "Request body too large")
+		return
+	}
+	response.Error(w, http.StatusBadRequest, decodeErrorMessage(err))
+}
+
+// decodeErrorMessage describes a JSON decoding error for the client.
+func decodeErrorMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return "Request body is required"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "Malformed JSON: unexpected end of body"
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("Malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Sprintf("Request body must be a JSON %s", jsonTypeName(typeErr.Type))
+		}
+		return fmt.Sprintf("Field %q expected type %s", typeErr.Field, jsonTypeName(typeErr.Type))
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		return "Unknown field " + strings.TrimPrefix(err.Error(), unknownFieldPrefix)
+	default:
+		return "Invalid request body"
+	}
+}
+
+// unknownFieldPrefix starts the error encoding/json returns for fields the
+// target does not declare. The package has no error type for it.
+const unknownFieldPrefix = "json: unknown field "
+
+// jsonTypeName returns the JSON name of the type a Go type decodes from.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// validationErrorResponse is the body returned for invalid requests.
+type validationErrorResponse struct {
+	Success bool                `json:"success"`
+	Errors  []models.FieldError `json:"errors"`
+}
+
+// writeError renders validation errors as a 422 with every field violation,
+// localized like response.Error, and delegates all other errors to
+// response.FromError.
+func writeError(w http.ResponseWriter, err error) {
+	var verr *models.ValidationError
+	if errors.As(err, &verr) {
+		fieldErrors := make([]models.FieldError, 0, len(verr.Errors))
+		for _, fe := range verr.Errors {
+			fe.Message = response.Localize(w, fe.Code, fe.Message)
+			fieldErrors = append(fieldErrors, fe)
+		}
+		response.JSON(w, http.StatusUnprocessableEntity, validationErrorResponse{
+			Success: false,
+			Errors:  fieldErrors,
+		})
+		return
+	}
+	response.FromError(w, err)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// HealthHandler handles health check HTTP requests.
+type HealthHandler struct {
+	healthService services.HealthService
+}
+
+// NewHealthHandler creates a new HealthHandler.
+func NewHealthHandler(healthService services.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// Health handles GET /health. The response carries a weak ETag and a
+// request whose If-None-Match lists it gets 304 without a body.
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	health := h.healthService.GetHealthStatus(r.Context())
+
+	etag := healthETag(health)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	response.JSON(w, http.StatusOK, health)
+}
+
+// healthETag returns a weak ETag over the fields of a health response that
+// only change with the state of the service. The timestamp and uptime
+// change on every call and are left out, which makes the tag weak.
+func healthETag(health *models.HealthResponse) string {
+	sum := sha256.Sum256([]byte(health.Status + "\x00" + health.Service + "\x00" + health.Version))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, using
+// the weak comparison RFC 9110 prescribes for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Ready handles GET /readyz, responding 503 when any check fails.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	readiness := h.healthService.GetReadiness(r.Context())
+
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	response.JSON(w, status, readiness)
+}
+
+// History handles GET /healthz/history and lists the latest readiness
+// evaluations, oldest first.
+func (h *HealthHandler) History(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.healthService.History())
+}
+
+// SetMaintenance handles PUT /admin/maintenance, switching maintenance mode
+// on or off.
+func (h *HealthHandler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	req, ok := DecodeJSON[models.MaintenanceRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.healthService.SetMaintenance(*req.Enabled)
+	response.JSON(w, http.StatusOK, models.MaintenanceResponse{Maintenance: h.healthService.Maintenance()})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// IntrospectionHandler lets other services check tokens server-side.
+type IntrospectionHandler struct {
+	tokens services.TokenService
+}
+
+// NewIntrospectionHandler creates an IntrospectionHandler that verifies
+// tokens with tokens. Pass the session-aware TokenService so revoked
+// sessions are reported inactive.
+func NewIntrospectionHandler(tokens services.TokenService) *IntrospectionHandler {
+	return &IntrospectionHandler{tokens: tokens}
+}
+
+// Introspect handles POST /introspect. Tokens that fail verification for
+// any reason, including expiry and revocation, are reported as
+// {"active":false} with 200.
+func (h *IntrospectionHandler) Introspect(w http.ResponseWriter, r *http.Request) {
+	req, ok := DecodeJSON[models.IntrospectRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	claims, err := h.tokens.Parse(req.Token)
+	if err != nil {
+		response.JSON(w, http.StatusOK, models.IntrospectResponse{Active: false})
+		return
+	}
+
+	resp := models.IntrospectResponse{
+		Active:    true,
+		Subject:   claims.Subject,
+		Username:  claims.Username,
+		Role:      claims.Role,
+		TokenType: claims.TokenType,
+	}
+	if claims.ExpiresAt != nil {
+		resp.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// jwksMaxAge lets verifiers cache the key set briefly; rotated keys show up
+// within this time.
+const jwksMaxAge = "max-age=300"
+
+// JWKSHandler publishes the token verification keys.
+type JWKSHandler struct {
+	keys services.KeySetProvider
+}
+
+// NewJWKSHandler creates a JWKSHandler serving the keys of keys.
+func NewJWKSHandler(keys services.KeySetProvider) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// JWKS handles GET /.well-known/jwks.json.
+func (h *JWKSHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, "+jwksMaxAge)
+	response.JSON(w, http.StatusOK, h.keys.JWKS())
+}
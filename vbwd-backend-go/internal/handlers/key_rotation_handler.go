@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// KeyRotationHandler lets admins replace the token signing key.
+type KeyRotationHandler struct {
+	keys  services.KeyRotator
+	grace time.Duration
+}
+
+// NewKeyRotationHandler creates a KeyRotationHandler rotating keys. Tokens
+// signed before a rotation keep verifying for grace.
+func NewKeyRotationHandler(keys services.KeyRotator, grace time.Duration) *KeyRotationHandler {
+	return &KeyRotationHandler{keys: keys, grace: grace}
+}
+
+// Rotate handles POST /admin/rotate-key.
+func (h *KeyRotationHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.keys.Rotate(h.grace)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Key rotation failed")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
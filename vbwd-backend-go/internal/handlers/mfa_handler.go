@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// MFAHandler serves TOTP enrollment and the second step of MFA logins.
+type MFAHandler struct {
+	mfa          services.MFAService
+	authService  services.AuthService
+	tokenService services.TokenService
+	audit        services.AuditLogger
+}
+
+// NewMFAHandler creates a new MFAHandler. The AuthService must be
+// configured with the same MFAService through services.WithMFA. Logins
+// completed with a code are recorded with audit, under the username the
+// TokenService reads from the challenge token. A nil audit uses
+// services.NopAuditLogger().
+func NewMFAHandler(mfa services.MFAService, authService services.AuthService, tokenService services.TokenService, audit services.AuditLogger) *MFAHandler {
+	if audit == nil {
+		audit = services.NopAuditLogger()
+	}
+	return &MFAHandler{mfa: mfa, authService: authService, tokenService: tokenService, audit: audit}
+}
+
+// Enroll handles POST /mfa/enroll and returns a new secret for the caller.
+// MFA is not enforced until the secret is confirmed at POST /mfa/verify.
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	resp, err := h.mfa.Enroll(r.Context(), claims.Subject, claims.Username)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// Verify handles POST /mfa/verify and enables MFA for the caller when the
+// code matches the pending secret.
+func (h *MFAHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	req, ok := DecodeJSON[models.MFAVerifyRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.mfa.Confirm(r.Context(), claims.Subject, req.Code); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, models.MessageResponse{
+		Success: true,
+		Message: "MFA enabled",
+	})
+}
+
+// Login handles POST /mfa/login and exchanges the challenge token of a
+// password login and a valid code for tokens.
+func (h *MFAHandler) Login(w http.ResponseWriter, r *http.Request) {
+	req, ok := DecodeJSON[models.MFALoginRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx, ok := loginContext(w, r)
+	if !ok {
+		return
+	}
+	resp, err := h.authService.AuthenticateMFA(ctx, req.MFAToken, req.Code)
+	h.audit.RecordLogin(r.Context(), h.challengeUsername(req.MFAToken), err == nil, middleware.ClientIP(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// challengeUsername returns the user an MFA challenge token was issued
+// to, or "" when the token is invalid or expired.
+func (h *MFAHandler) challengeUsername(mfaToken string) string {
+	claims, err := h.tokenService.Parse(mfaToken)
+	if err != nil || claims.TokenType != services.TokenTypeMFA {
+		return ""
+	}
+	return claims.Username
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// oauthStateCookie carries the state of a login started at an external
+// identity provider until its callback.
+const oauthStateCookie = "oauth_state"
+
+// oauthLinkCookie carries the link token of a started identity link until
+// its callback.
+const oauthLinkCookie = "oauth_link"
+
+// oauthStateTTL is how long a started external login can be completed.
+const oauthStateTTL = 10 * time.Minute
+
+// OAuthHandler handles logins through external identity providers and the
+// linking of their identities to local users.
+type OAuthHandler struct {
+	authService services.AuthService
+	providers   map[string]services.IdentityProvider
+}
+
+// NewOAuthHandler creates an OAuthHandler for the given providers, which
+// are addressed by their names.
+func NewOAuthHandler(authService services.AuthService, providers ...services.IdentityProvider) *OAuthHandler {
+	byName := make(map[string]services.IdentityProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+	return &OAuthHandler{authService: authService, providers: byName}
+}
+
+// Login handles GET /auth/{provider}/login. It redirects to the provider
+// and remembers a random state in a cookie scoped to the callback. A link
+// started earlier is abandoned, so the callback logs in.
+func (h *OAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.providers[r.PathValue("provider")]
+	if !ok {
+		response.Error(w, http.StatusNotFound, "Unknown identity provider")
+		return
+	}
+
+	state, err := randomState()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to start login")
+		return
+	}
+	setOAuthCookie(w, r, "/login", oauthStateCookie, state)
+	if _, err := r.Cookie(oauthLinkCookie); err == nil {
+		clearOAuthCookie(w, r, "/login", oauthLinkCookie)
+	}
+	http.Redirect(w, r, provider.AuthCodeURL(state), http.StatusFound)
+}
+
+// Link handles POST /auth/{provider}/link for an authenticated user. It
+// answers with the provider URL to send the user to and remembers the
+// state and a link token in cookies, so the callback links the identity
+// instead of logging in.
+func (h *OAuthHandler) Link(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.providers[r.PathValue("provider")]
+	if !ok {
+		response.Error(w, http.StatusNotFound, "Unknown identity provider")
+		return
+	}
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	linkToken, err := h.authService.LinkToken(r.Context(), claims.Subject, claims.Username)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	state, err := randomState()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to start link")
+		return
+	}
+	setOAuthCookie(w, r, "/link", oauthStateCookie, state)
+	setOAuthCookie(w, r, "/link", oauthLinkCookie, linkToken)
+	response.JSON(w, http.StatusOK, models.OAuthLinkResponse{AuthorizationURL: provider.AuthCodeURL(state)})
+}
+
+// Callback handles GET /auth/{provider}/callback. It checks the state and
+// exchanges the code for the user's identity. A callback of Link links the
+// identity and answers 204; otherwise the local user the identity is
+// linked to is logged in, answering like POST /login.
+func (h *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.providers[r.PathValue("provider")]
+	if !ok {
+		response.Error(w, http.StatusNotFound, "Unknown identity provider")
+		return
+	}
+
+	cookie, err := r.Cookie(oauthStateCookie)
+	state := r.URL.Query().Get("state")
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		response.Error(w, http.StatusBadRequest, "Invalid or expired login state")
+		return
+	}
+	clearOAuthCookie(w, r, "/callback", oauthStateCookie)
+	linkCookie, err := r.Cookie(oauthLinkCookie)
+	if err == nil {
+		clearOAuthCookie(w, r, "/callback", oauthLinkCookie)
+	}
+
+	if r.URL.Query().Get("error") != "" {
+		response.Error(w, http.StatusUnauthorized, "Login was denied by the identity provider")
+		return
+	}
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		response.Error(w, http.StatusBadRequest, "Authorization code is required")
+		return
+	}
+
+	identity, err := provider.Exchange(r.Context(), code)
+	if err != nil {
+		response.Error(w, http.StatusBadGateway, "Identity provider login failed")
+		return
+	}
+
+	if linkCookie != nil {
+		if err := h.authService.LinkExternal(r.Context(), linkCookie.Value, *identity); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	ctx := services.WithDevice(r.Context(), r.UserAgent())
+	resp, err := h.authService.AuthenticateExternal(ctx, *identity)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// setOAuthCookie sets a cookie that lives until the callback. It is scoped
+// to the provider's routes, whose path is the request path without suffix.
+func setOAuthCookie(w http.ResponseWriter, r *http.Request, suffix, name, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     strings.TrimSuffix(r.URL.Path, suffix),
+		MaxAge:   int(oauthStateTTL / time.Second),
+		HttpOnly: true,
+		Secure:   middleware.IsHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearOAuthCookie deletes a cookie set by setOAuthCookie.
+func clearOAuthCookie(w http.ResponseWriter, r *http.Request, suffix, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Path:     strings.TrimSuffix(r.URL.Path, suffix),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   middleware.IsHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// randomState returns an unguessable OAuth2 state value.
+func randomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// forgotPasswordMessage is returned whether or not the email is registered.
+const forgotPasswordMessage = "If the email is registered, a reset link has been sent"
+
+// PasswordResetHandler handles the forgot-password HTTP requests.
+type PasswordResetHandler struct {
+	resetService services.PasswordResetService
+}
+
+// NewPasswordResetHandler creates a new PasswordResetHandler.
+func NewPasswordResetHandler(resetService services.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{resetService: resetService}
+}
+
+// Forgot handles POST /password/forgot. It answers 200 for unknown emails
+// too so the endpoint cannot be used to enumerate accounts.
+func (h *PasswordResetHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	req, ok := DecodeJSON[models.ForgotPasswordRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if _, err := h.resetService.RequestReset(r.Context(), req.Email); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Password reset failed")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, models.MessageResponse{
+		Success: true,
+		Message: forgotPasswordMessage,
+	})
+}
+
+// Reset handles POST /password/reset.
+func (h *PasswordResetHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	req, ok := DecodeJSON[models.ResetPasswordRequest](w, r)
+	if !ok {
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.resetService.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, models.MessageResponse{
+		Success: true,
+		Message: "Password has been reset",
+	})
+}
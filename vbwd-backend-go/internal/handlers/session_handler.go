@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// SessionHandler lets users see and end their login sessions.
+type SessionHandler struct {
+	sessions services.SessionService
+}
+
+// NewSessionHandler creates a new SessionHandler.
+func NewSessionHandler(sessions services.SessionService) *SessionHandler {
+	return &SessionHandler{sessions: sessions}
+}
+
+// List handles GET /sessions and returns the caller's sessions, marking
+// the one the access token belongs to as current.
+func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	sessions, err := h.sessions.List(r.Context(), claims.Subject)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	dtos := make([]models.SessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		dtos = append(dtos, models.ToSessionDTO(session, claims.SessionID))
+	}
+	response.Render(w, r, http.StatusOK, models.SessionListResponse{Sessions: dtos})
+}
+
+// Revoke handles DELETE /sessions/{id}. Tokens of the session are rejected
+// from then on; other sessions of the user stay valid.
+func (h *SessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	if err := h.sessions.Revoke(r.Context(), claims.Subject, r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll handles POST /logout-all. It revokes every session of the
+// caller, including the current one, so all their tokens are rejected.
+func (h *SessionHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	if _, err := h.sessions.RevokeAll(r.Context(), claims.Subject); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
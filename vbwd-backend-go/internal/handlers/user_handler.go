@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// UserHandler handles user administration HTTP requests.
+type UserHandler struct {
+	userService services.UserService
+}
+
+// NewUserHandler creates a new UserHandler.
+func NewUserHandler(userService services.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// List handles GET /users?offset=&limit=. The page is rendered in the
+// format negotiated from the Accept header.
+func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pageReq, err := models.ParsePageRequest(query.Get("offset"), query.Get("limit"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page, err := h.userService.List(r.Context(), pageReq)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to list users")
+		return
+	}
+
+	response.Render(w, r, http.StatusOK, models.Page[models.UserDTO]{
+		Items:  models.ToDTOs(page.Items),
+		Total:  page.Total,
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	})
+}
+
+// Delete handles DELETE /users/{id}. Admins cannot delete their own
+// account.
+func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	if err := h.userService.Delete(r.Context(), claims.Subject, r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
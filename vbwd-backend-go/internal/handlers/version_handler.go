@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// VersionHandler reports build metadata.
+type VersionHandler struct {
+	info models.VersionResponse
+}
+
+// NewVersionHandler creates a VersionHandler for the given build. The Go
+// version is taken from the running binary.
+func NewVersionHandler(version, commit, buildTime string) *VersionHandler {
+	return &VersionHandler{info: models.VersionResponse{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+	}}
+}
+
+// Version handles GET /version.
+func (h *VersionHandler) Version(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.info)
+}
@@ -0,0 +1,58 @@
+// Package metrics defines the Prometheus collectors exported by the service.
+// Collectors are registered with the default Prometheus registry so that
+// promhttp.Handler serves them together with the Go runtime metrics.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UnmatchedPath is the path label recorded for requests that matched no
+// route, keeping label cardinality bounded.
+const UnmatchedPath = "unmatched"
+
+var (
+	// RequestsTotal counts handled requests by method, path and status code.
+	RequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests by method, path and status code.",
+		},
+		[]string{"method", "path", "status"},
+	)
+
+	// RequestDuration observes request latency by method and path.
+	RequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds by method and path.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "path"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(RequestsTotal, RequestDuration)
+}
+
+// OtherMethod is the method label recorded for requests with a method
+// outside the standard HTTP methods.
+const OtherMethod = "OTHER"
+
+// ObserveRequest records a completed request. Nonstandard methods are
+// recorded as OtherMethod.
+func ObserveRequest(method, path string, status int, duration time.Duration) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+	default:
+		method = OtherMethod
+	}
+	RequestsTotal.WithLabelValues(method, path, strconv.Itoa(status)).Inc()
+	RequestDuration.WithLabelValues(method, path).Observe(duration.Seconds())
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+type contextKey string
+
+const claimsContextKey contextKey = "claims"
+
+// APIKeyHeader carries an API key in place of a bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// RequireAuth rejects requests without a valid bearer token and stores the
+// parsed claims in the request context for the wrapped handler. When ts
+// implements services.APIKeyVerifier, an API key in the X-API-Key header
+// is accepted instead of a bearer token.
+func RequireAuth(next http.HandlerFunc, ts services.TokenService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if verifier, ok := ts.(services.APIKeyVerifier); ok && r.Header.Get(APIKeyHeader) != "" {
+			claims, err := verifier.VerifyAPIKey(r.Context(), r.Header.Get(APIKeyHeader))
+			if err != nil {
+				response.Error(w, http.StatusUnauthorized, "Invalid API key")
+				return
+			}
+			next(w, r.WithContext(context.WithValue(r.Context(), claimsContextKey, claims)))
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			response.Error(w, http.StatusUnauthorized, "Missing bearer token")
+			return
+		}
+
+		claims, err := ts.Parse(token)
+		if err != nil || claims.TokenType != services.TokenTypeAccess {
+			response.Error(w, http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), claimsContextKey, claims)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// ClaimsFromContext returns the claims stored by RequireAuth.
+func ClaimsFromContext(ctx context.Context) (*services.Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey).(*services.Claims)
+	return claims, ok
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// RequireRole rejects authenticated requests whose role claim does not match
+// role with 403. It must run inside RequireAuth.
+func RequireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok {
+			response.Error(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+		if claims.Role != role {
+			response.Error(w, http.StatusForbidden, "Insufficient permissions")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// RejectAPIKeys rejects requests authenticated with an API key with 403.
+// It guards account management, such as MFA, sessions and the password,
+// so a leaked key cannot take over the account. It must run inside
+// RequireAuth.
+func RejectAPIKeys(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok {
+			response.Error(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+		if claims.TokenType == services.TokenTypeAPIKey {
+			response.Error(w, http.StatusForbidden, "API keys cannot manage the account")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import "net/http"
+
+// DefaultMaxBodyBytes is the request body limit used when none is configured.
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// MaxBodySize limits request bodies to limit bytes. Reads past the limit
+// fail with *http.MaxBytesError, which handlers translate into 413. A
+// non-positive limit selects DefaultMaxBodyBytes.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import "net/http"
+
+// Middleware wraps an http.Handler with additional behavior.
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes middlewares into one. The first middleware is the
+// outermost: it sees the request first and the response last, so
+// Chain(a, b, c).Then(h) is equivalent to a(b(c(h))).
+func Chain(middlewares ...Middleware) Middleware {
+	return func(final http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			final = middlewares[i](final)
+		}
+		return final
+	}
+}
+
+// Then wraps final with the middleware.
+func (m Middleware) Then(final http.Handler) http.Handler {
+	return m(final)
+}
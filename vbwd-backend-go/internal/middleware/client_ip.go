@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const clientIPContextKey contextKey = "client_ip"
+
+// TrustProxies resolves the client address of each request and stores it
+// for ClientIP. X-Forwarded-For is only read when the peer is one of the
+// trusted proxies; the client is then the rightmost forwarded hop that is
+// not a trusted proxy. Without trusted proxies the header is ignored, so
+// clients cannot choose the address they are rate limited and blocked by.
+func TrustProxies(trusted []netip.Prefix) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trusted)
+			ctx := context.WithValue(r.Context(), clientIPContextKey, ip)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClientIP returns the client address resolved by TrustProxies, or the
+// peer address of the connection when the request did not pass through
+// it.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPContextKey).(string); ok {
+		return ip
+	}
+	return remoteHost(r)
+}
+
+// resolveClientIP walks X-Forwarded-For from the right while the hops are
+// trusted proxies. A malformed entry ends the walk at the last trusted hop,
+// since nothing to its left was vouched for.
+func resolveClientIP(r *http.Request, trusted []netip.Prefix) string {
+	remote := remoteHost(r)
+	if !isTrusted(remote, trusted) {
+		return remote
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = hop.Unmap().String()
+		if !isTrusted(client, trusted) {
+			break
+		}
+	}
+	return client
+}
+
+// isTrusted reports whether ip lies in one of the trusted prefixes.
+func isTrusted(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteHost returns the host part of the connection's peer address.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// LimitConcurrency serves at most max requests at a time and rejects
+// requests beyond the limit with 503 instead of queueing them. A
+// non-positive max disables the limit.
+func LimitConcurrency(max int, next http.Handler) http.Handler {
+	return LimitConcurrencyWait(max, 0, next)
+}
+
+// LimitConcurrencyWait is LimitConcurrency with requests beyond the limit
+// waiting up to wait for a slot before they are rejected with 503. Waiting
+// stops early when the client goes away. A non-positive wait rejects
+// immediately.
+func LimitConcurrencyWait(max int, wait time.Duration, next http.Handler) http.Handler {
+	if max <= 0 {
+		return next
+	}
+	slots := make(chan struct{}, max)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !acquire(r, slots, wait) {
+			response.Error(w, http.StatusServiceUnavailable, "Too many concurrent requests")
+			return
+		}
+		defer func() { <-slots }()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acquire takes a slot, waiting up to wait for one to free up.
+func acquire(r *http.Request, slots chan struct{}, wait time.Duration) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+	if wait <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
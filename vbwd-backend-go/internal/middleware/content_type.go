@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// RequireJSON rejects requests whose Content-Type is not application/json
+// with 415. A charset parameter is allowed as long as it is UTF-8, the only
+// encoding JSON permits.
+func RequireJSON(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !IsJSONContentType(r.Header.Get("Content-Type")) {
+			response.Error(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// IsJSONContentType reports whether a Content-Type header value is
+// application/json, optionally with a UTF-8 charset.
+func IsJSONContentType(header string) bool {
+	mediaType, params, err := mime.ParseMediaType(header)
+	if err != nil || mediaType != "application/json" {
+		return false
+	}
+	for name, value := range params {
+		if name != "charset" || !strings.EqualFold(value, "utf-8") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CORS response header values.
+const (
+	corsAllowMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders = "Authorization, Content-Type, X-API-Key, X-Request-ID"
+	corsMaxAge       = "600"
+)
+
+// CORS returns a middleware that adds CORS headers for the allowed origins.
+// An entry of "*" allows any origin without credentials; exact matches echo
+// the origin and allow credentials. Preflight requests are answered with
+// 204 without reaching the wrapped handler.
+func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
+	allowAny := false
+	exact := make(map[string]struct{}, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			allowAny = true
+			continue
+		}
+		if origin != "" {
+			exact[origin] = struct{}{}
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			header := w.Header()
+
+			if origin != "" {
+				header.Add("Vary", "Origin")
+
+				if _, ok := exact[origin]; ok {
+					header.Set("Access-Control-Allow-Origin", origin)
+					header.Set("Access-Control-Allow-Credentials", "true")
+				} else if allowAny {
+					header.Set("Access-Control-Allow-Origin", "*")
+				}
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				if header.Get("Access-Control-Allow-Origin") != "" {
+					header.Set("Access-Control-Allow-Methods", corsAllowMethods)
+					header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+					header.Set("Access-Control-Max-Age", corsMaxAge)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipMinSize is the smallest body worth compressing; below it the gzip
+// header and trailer outweigh the savings.
+const gzipMinSize = 1024
+
+var gzipWriters = sync.Pool{
+	New: func() any { return gzip.NewWriter(nil) },
+}
+
+// Gzip compresses responses for clients that accept gzip. The body is held
+// back until gzipMinSize bytes are written so small responses are sent
+// plain with their Content-Length. Compressed responses drop
+// Content-Length, since the compressed size is not known up front, and
+// responses that already carry a Content-Encoding are left untouched.
+// Under Logging the uncompressed body is handed to it for debug logging.
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// finish is not deferred: after a panic nothing must be written
+		// so that Recover can still answer with 500.
+		gw := &gzipResponseWriter{ResponseWriter: w, status: http.StatusOK, capture: bodyCaptureFromContext(r.Context())}
+		next.ServeHTTP(gw, r)
+		gw.finish()
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		name, value, found := strings.Cut(strings.TrimSpace(params), "=")
+		if !found || strings.TrimSpace(name) != "q" {
+			return true
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return err == nil && q > 0
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the start of a response to decide whether to
+// compress it.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+	gz          *gzip.Writer
+	// plain is set once the response is passed through uncompressed.
+	plain bool
+	// capture, when set, receives the body before compression.
+	capture *bodyCapture
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		w.passThrough()
+	}
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case w.plain:
+		return w.ResponseWriter.Write(b)
+	case w.gz != nil:
+		if w.capture != nil {
+			w.capture.Write(b)
+		}
+		return w.gz.Write(b)
+	}
+
+	w.buf.Write(b)
+	if w.buf.Len() < gzipMinSize {
+		return len(b), nil
+	}
+	if err := w.startGzip(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// passThrough sends the status and any buffered bytes uncompressed.
+func (w *gzipResponseWriter) passThrough() {
+	w.plain = true
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// startGzip switches to compression and flushes the buffer into it.
+func (w *gzipResponseWriter) startGzip() error {
+	h := w.Header()
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if w.capture != nil {
+		w.capture.compressed = true
+		w.capture.Write(w.buf.Bytes())
+	}
+	w.gz = gzipWriters.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+	_, err := w.gz.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// finish completes the response after the handler returned.
+func (w *gzipResponseWriter) finish() {
+	if w.gz != nil {
+		w.gz.Close()
+		gzipWriters.Put(w.gz)
+		return
+	}
+	if w.plain {
+		return
+	}
+
+	// The body stayed below gzipMinSize: send it as is.
+	if w.Header().Get("Content-Length") == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(w.buf.Len()))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(w.buf.Bytes())
+}
+
+// Flush sends buffered data to the client, compressing it when the body has
+// not been classified yet.
+func (w *gzipResponseWriter) Flush() {
+	if w.gz == nil && !w.plain {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		if !w.plain {
+			w.startGzip()
+		}
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// Idempotency headers.
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// DefaultIdempotencyTTL is how long responses are kept for replay when no
+// TTL is configured.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength bounds the size of client-supplied keys.
+const maxIdempotencyKeyLength = 255
+
+// Idempotency replays the first response for a repeated Idempotency-Key
+// header instead of calling next again. Responses are stored in store for
+// ttl; server errors are not stored so the client can retry them. Requests
+// without the header pass through unchanged. A key reused with a different
+// body is rejected with 422, and a duplicate arriving while the first
+// request is still running gets 409. A non-positive ttl selects
+// DefaultIdempotencyTTL.
+func Idempotency(next http.HandlerFunc, store IdempotencyStore, ttl time.Duration) http.HandlerFunc {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	var inFlight sync.Map
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.Error(w, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				response.Error(w, http.StatusRequestEntityTooLarge, "Request body too large")
+				return
+			}
+			response.Error(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(sum[:])
+
+		// Keys are scoped to the route so one key cannot replay another
+		// endpoint's response.
+		storeKey := r.Method + " " + r.URL.Path + " " + key
+
+		if _, busy := inFlight.LoadOrStore(storeKey, struct{}{}); busy {
+			response.Error(w, http.StatusConflict, "A request with this Idempotency-Key is in progress")
+			return
+		}
+		defer inFlight.Delete(storeKey)
+
+		stored, err := store.Get(r.Context(), storeKey)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		if stored != nil {
+			if stored.RequestHash != requestHash {
+				response.Error(w, http.StatusUnprocessableEntity, "Idempotency-Key was used with a different request body")
+				return
+			}
+			replay(w, stored)
+			return
+		}
+
+		rec := &capturingWriter{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+
+		if rec.status >= http.StatusInternalServerError {
+			return
+		}
+		// The response has already been sent; a failed write only means a
+		// retry is processed again.
+		_ = store.Put(r.Context(), storeKey, StoredResponse{
+			RequestHash: requestHash,
+			StatusCode:  rec.status,
+			Header:      w.Header().Clone(),
+			Body:        rec.body.Bytes(),
+		}, ttl)
+	}
+}
+
+// replay writes a stored response, marking it as replayed.
+func replay(w http.ResponseWriter, stored *StoredResponse) {
+	for name, values := range stored.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.StatusCode)
+	_, _ = w.Write(stored.Body)
+}
+
+// capturingWriter passes the response through while keeping a copy of the
+// status and body.
+type capturingWriter struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (c *capturingWriter) WriteHeader(status int) {
+	if c.wroteHeader {
+		return
+	}
+	c.status = status
+	c.wroteHeader = true
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *capturingWriter) Write(b []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (c *capturingWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// StoredResponse is a response recorded for an idempotency key.
+type StoredResponse struct {
+	// RequestHash fingerprints the request body so a key reused with a
+	// different body can be detected.
+	RequestHash string
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+}
+
+// IdempotencyStore keeps the first response seen for each idempotency key.
+type IdempotencyStore interface {
+	// Get returns the response stored for key, or nil when there is none
+	// or it has expired.
+	Get(ctx context.Context, key string) (*StoredResponse, error)
+	// Put stores resp under key for ttl.
+	Put(ctx context.Context, key string, resp StoredResponse, ttl time.Duration) error
+}
+
+type storedEntry struct {
+	resp      StoredResponse
+	expiresAt time.Time
+}
+
+// inMemoryIdempotencyStore keeps responses in a map and drops expired
+// entries on write.
+type inMemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	clock   services.Clock
+	entries map[string]storedEntry
+}
+
+// NewInMemoryIdempotencyStore creates an empty in-memory IdempotencyStore.
+// A nil clock uses the system clock.
+func NewInMemoryIdempotencyStore(clock services.Clock) IdempotencyStore {
+	if clock == nil {
+		clock = services.RealClock()
+	}
+	return &inMemoryIdempotencyStore{clock: clock, entries: make(map[string]storedEntry)}
+}
+
+// Get returns the unexpired response stored for key.
+func (s *inMemoryIdempotencyStore) Get(ctx context.Context, key string) (*StoredResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !s.clock.Now().Before(entry.expiresAt) {
+		return nil, nil
+	}
+	resp := entry.resp
+	return &resp, nil
+}
+
+// Put stores resp and evicts expired entries.
+func (s *inMemoryIdempotencyStore) Put(ctx context.Context, key string, resp StoredResponse, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	for k, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = storedEntry{resp: resp, expiresAt: now.Add(ttl)}
+	return nil
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/dantweb/vbwd-backend-go/pkg/i18n"
+)
+
+// Localize negotiates a locale from the Accept-Language header among the
+// locales of catalog and translates error messages written with
+// package response into it. The chosen locale is echoed in
+// Content-Language. Place it inside any middleware that buffers the
+// response without exposing Unwrap.
+func Localize(catalog i18n.Catalog) Middleware {
+	supported := catalog.Locales()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale := i18n.Negotiate(r.Header.Get("Accept-Language"), supported)
+			w.Header().Set("Content-Language", locale)
+			w.Header().Add("Vary", "Accept-Language")
+			next.ServeHTTP(&localizedWriter{ResponseWriter: w, catalog: catalog, locale: locale}, r)
+		})
+	}
+}
+
+// localizedWriter carries the negotiated locale to response.Localize.
+type localizedWriter struct {
+	http.ResponseWriter
+	catalog i18n.Catalog
+	locale  string
+}
+
+// Localize translates message into the negotiated locale.
+func (w *localizedWriter) Localize(code, message string) string {
+	return w.catalog.Translate(w.locale, code, message)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *localizedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// maxLoggedBodyBytes caps the request and response bodies captured for
+// debug logging.
+const maxLoggedBodyBytes = 4 << 10
+
+const bodyCaptureContextKey contextKey = "body_capture"
+
+// bodyCapture receives the response body before Gzip compresses it, so
+// that Logging can redact and log it when it only sees compressed bytes.
+type bodyCapture struct {
+	cappedBuffer
+	compressed bool
+}
+
+// bodyCaptureFromContext returns the capture Logging placed in ctx, or nil
+// when bodies are not logged.
+func bodyCaptureFromContext(ctx context.Context) *bodyCapture {
+	capture, _ := ctx.Value(bodyCaptureContextKey).(*bodyCapture)
+	return capture
+}
+
+// Logging writes one structured access log entry per request through the
+// default slog logger, tagged with the request ID when RequestID runs first.
+// When the logger is enabled for debug, the entry also carries the request
+// and response bodies with SensitiveFields masked by RedactJSON. Bodies
+// that are not JSON or exceed 4 KiB are left out. Responses compressed by
+// a Gzip inside Logging are logged as they were before compression.
+func Logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		logger := slog.Default()
+		rec := newResponseRecorder(w)
+
+		var requestBody *cappedBuffer
+		var capture *bodyCapture
+		debug := logger.Enabled(r.Context(), slog.LevelDebug)
+		if debug {
+			requestBody = &cappedBuffer{}
+			if r.Body != nil {
+				r.Body = &teeReadCloser{ReadCloser: r.Body, copy: requestBody}
+			}
+			rec.body = &cappedBuffer{}
+			capture = &bodyCapture{}
+			r = r.WithContext(context.WithValue(r.Context(), bodyCaptureContextKey, capture))
+		}
+
+		next.ServeHTTP(rec, r)
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int("bytes", rec.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("request_id", RequestIDFromContext(r.Context())),
+		}
+		if debug {
+			responseBody := rec.body
+			if capture.compressed {
+				responseBody = &capture.cappedBuffer
+			}
+			attrs = append(attrs,
+				slog.String("request_body", requestBody.logValue()),
+				slog.String("response_body", responseBody.logValue()),
+			)
+		}
+		logger.LogAttrs(r.Context(), slog.LevelInfo, "http request", attrs...)
+	})
+}
+
+// cappedBuffer keeps the first maxLoggedBodyBytes written to it and
+// remembers whether more followed.
+type cappedBuffer struct {
+	bytes.Buffer
+	truncated bool
+}
+
+// Write never fails so it cannot disturb the request it observes.
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := maxLoggedBodyBytes - b.Len(); len(p) > room {
+		b.truncated = true
+		p = p[:max(room, 0)]
+	}
+	b.Buffer.Write(p)
+	return len(p), nil
+}
+
+// logValue returns the captured body redacted for the log, or a note why
+// it was left out.
+func (b *cappedBuffer) logValue() string {
+	switch {
+	case b.truncated:
+		return fmt.Sprintf("[omitted: over %d bytes]", maxLoggedBodyBytes)
+	case b.Len() == 0:
+		return ""
+	}
+	redacted, err := RedactJSON(b.Bytes(), SensitiveFields)
+	if err != nil {
+		return "[omitted: not JSON]"
+	}
+	return string(redacted)
+}
+
+// teeReadCloser copies what the handler reads from the request body.
+type teeReadCloser struct {
+	io.ReadCloser
+	copy *cappedBuffer
+}
+
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	t.copy.Write(p[:n])
+	return n, err
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// BlockFailedLogins returns a middleware for login handlers that counts
+// rejected logins (401 and 423 responses) per client IP with blocker and
+// answers requests from blocked IPs with 429 and Retry-After. Wrapping
+// several login routes with the returned middleware shares the counters.
+func BlockFailedLogins(blocker services.IPBlocker) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ip := ClientIP(r)
+			if wait := blocker.RetryAfter(ip); wait > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				response.Error(w, http.StatusTooManyRequests, "Too many failed login attempts")
+				return
+			}
+
+			rec := newResponseRecorder(w)
+			next(rec, r)
+			if rec.status == http.StatusUnauthorized || rec.status == http.StatusLocked {
+				blocker.RecordFailure(ip)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/metrics"
+)
+
+type routeContextKey struct{}
+
+// Metrics records the request count, status code and latency of every
+// request in the Prometheus collectors of the metrics package. Requests
+// are labelled with the route pattern reported by RecordRoute, so path
+// parameters do not create label values. Requests that reached no route,
+// such as 404s, 405s and redirects, are recorded under
+// metrics.UnmatchedPath.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := newResponseRecorder(w)
+		var route atomic.Pointer[string]
+
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), routeContextKey{}, &route)))
+
+		path := metrics.UnmatchedPath
+		if pattern := route.Load(); pattern != nil {
+			path = *pattern
+		}
+		metrics.ObserveRequest(r.Method, path, rec.status, time.Since(start))
+	})
+}
+
+// RecordRoute reports the path pattern of the route that serves r, such
+// as "/users/{id}", to Metrics. Routers call it before the route's
+// handler runs.
+func RecordRoute(r *http.Request, pattern string) {
+	if route, ok := r.Context().Value(routeContextKey{}).(*atomic.Pointer[string]); ok {
+		route.Store(&pattern)
+	}
+}
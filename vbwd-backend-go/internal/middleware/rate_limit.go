@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// limiterIdleTTL is how long a client's limiter is kept without traffic.
+const limiterIdleTTL = 3 * time.Minute
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// ipRateLimiter holds one token bucket per client IP.
+type ipRateLimiter struct {
+	mu        sync.Mutex
+	rps       rate.Limit
+	burst     int
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+func (l *ipRateLimiter) allow(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= limiterIdleTTL {
+		l.evictIdle(now)
+	}
+
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &clientLimiter{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.clients[ip] = client
+	}
+	client.lastSeen = now
+
+	return client.limiter.Allow()
+}
+
+// evictIdle drops limiters for clients that have not been seen recently.
+func (l *ipRateLimiter) evictIdle(now time.Time) {
+	for ip, client := range l.clients {
+		if now.Sub(client.lastSeen) >= limiterIdleTTL {
+			delete(l.clients, ip)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimit applies a per-client-IP token bucket allowing rps requests per
+// second with the given burst, rejecting excess requests with 429.
+func RateLimit(next http.HandlerFunc, rps float64, burst int) http.HandlerFunc {
+	return NewRateLimiter(rps, burst)(next)
+}
+
+// NewRateLimiter returns a middleware like RateLimit whose buckets are
+// shared by every handler it wraps, so several routes can draw from one
+// per-client budget.
+func NewRateLimiter(rps float64, burst int) func(http.HandlerFunc) http.HandlerFunc {
+	limiter := &ipRateLimiter{
+		rps:       rate.Limit(rps),
+		burst:     burst,
+		clients:   make(map[string]*clientLimiter),
+		lastSweep: time.Now(),
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.allow(ClientIP(r)) {
+				response.Error(w, http.StatusTooManyRequests, "Too many requests")
+				return
+			}
+			next(w, r)
+		}
+	}
+}
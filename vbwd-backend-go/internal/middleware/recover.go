@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// Recover converts handler panics into a generic 500 response and logs the
+// panic value with its stack trace. If the handler already wrote headers
+// the response cannot be replaced, so the panic is only logged.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := newResponseRecorder(w)
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logPanic(r, "panic recovered", recovered, rec.wroteHeader)
+
+			if !rec.wroteHeader {
+				response.Error(rec, http.StatusInternalServerError, "Internal server error")
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// logPanic logs a recovered panic of the handler serving r with the stack
+// of the calling goroutine.
+func logPanic(r *http.Request, msg string, recovered any, headersWritten bool) {
+	slog.Default().ErrorContext(r.Context(), msg,
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.String("request_id", RequestIDFromContext(r.Context())),
+		slog.String("panic", fmt.Sprint(recovered)),
+		slog.String("stack", string(debug.Stack())),
+		slog.Bool("headers_written", headersWritten),
+	)
+}
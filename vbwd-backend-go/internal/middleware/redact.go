@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// RedactedValue replaces the values of sensitive fields in logged bodies.
+const RedactedValue = "[REDACTED]"
+
+// SensitiveFields are the JSON fields of request and response bodies that
+// carry credentials or secrets and are masked before bodies are logged.
+var SensitiveFields = []string{
+	"password", "old_password", "new_password",
+	"token", "refresh_token", "mfa_token",
+	"code", "secret", "otpauth_url", "key",
+}
+
+// RedactJSON returns a copy of the JSON body with the values of fields
+// replaced by RedactedValue, at any depth and whatever their type. Field
+// names match case-insensitively, as encoding/json matches them when
+// decoding. Other values are preserved; object keys come out sorted. It
+// returns an error when body is not valid JSON.
+func RedactJSON(body []byte, fields []string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(redactValue(value, fields))
+}
+
+// redactValue masks the sensitive fields of objects within value.
+func redactValue(value any, fields []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for name, field := range v {
+			if isSensitive(name, fields) {
+				v[name] = RedactedValue
+				continue
+			}
+			v[name] = redactValue(field, fields)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item, fields)
+		}
+	}
+	return value
+}
+
+func isSensitive(name string, fields []string) bool {
+	for _, field := range fields {
+		if strings.EqualFold(name, field) {
+			return true
+		}
+	}
+	return false
+}
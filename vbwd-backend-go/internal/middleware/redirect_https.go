@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// defaultProbePaths are the health probes RedirectHTTPS serves over plain
+// HTTP.
+var defaultProbePaths = []string{"/health", "/readyz"}
+
+// RedirectHTTPS redirects requests that arrived over plain HTTP to the same
+// URL with https, using 308 so the method and body are kept. Behind a TLS
+// terminating proxy the original scheme is read from X-Forwarded-Proto.
+// GET /health and GET /readyz are served as they are so load balancer
+// probes over HTTP keep working.
+func RedirectHTTPS(next http.Handler) http.Handler {
+	return RedirectHTTPSExcept(defaultProbePaths...)(next)
+}
+
+// RedirectHTTPSExcept is like RedirectHTTPS with its own list of paths that
+// are served over plain HTTP.
+func RedirectHTTPSExcept(paths ...string) Middleware {
+	exempt := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		exempt[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsHTTPS(r) || exempt[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			target := "https://" + r.Host + r.URL.RequestURI()
+			http.Redirect(w, r, target, http.StatusPermanentRedirect)
+		})
+	}
+}
+
+// IsHTTPS reports whether the client used HTTPS, directly or through a
+// proxy that set X-Forwarded-Proto.
+func IsHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// Timeout runs next with a request context that expires after d. Like
+// http.TimeoutHandler the response is buffered: when next finishes in time
+// it is sent as written, otherwise the client gets 503 with the standard
+// error envelope and later writes of next fail with
+// http.ErrHandlerTimeout. Handlers should watch r.Context() to stop work
+// early. A panic of next is re-raised for Recover while the request is
+// running and logged when it happens after the 503 was sent. Error
+// messages are translated by a Localize placed outside Timeout. A
+// non-positive d disables the deadline.
+func Timeout(d time.Duration, next http.Handler) http.Handler {
+	if d <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+
+		tw := &timeoutWriter{w: w, header: make(http.Header), status: http.StatusOK}
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+		go func() {
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				// Deciding under the lock ensures that a panic is either
+				// seen by the serving goroutine or logged here.
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				if tw.timedOut {
+					logPanic(r, "panic after request timeout", p, true)
+					return
+				}
+				panicked <- p
+			}()
+			next.ServeHTTP(tw, r.WithContext(ctx))
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			// Re-raise on the serving goroutine so Recover handles it.
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			dst := w.Header()
+			for key, values := range tw.header {
+				dst[key] = values
+			}
+			w.WriteHeader(tw.status)
+			w.Write(tw.body.Bytes())
+		case <-ctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.timedOut = true
+			select {
+			case p := <-panicked:
+				panic(p)
+			default:
+			}
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				response.Error(w, http.StatusServiceUnavailable, "Request timed out")
+			}
+		}
+	})
+}
+
+// timeoutWriter buffers the response of a handler run by Timeout. It does
+// not expose the underlying writer w, which only translates messages.
+type timeoutWriter struct {
+	w           http.ResponseWriter
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+// Localize translates message with a Localizer of the underlying writer.
+func (tw *timeoutWriter) Localize(code, message string) string {
+	return response.Localize(tw.w, code, message)
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.status = status
+	tw.wroteHeader = true
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.wroteHeader = true
+	return tw.body.Write(b)
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// APIKey is a long-lived credential a user creates for machine clients.
+// Only the hash of the key is stored; the key itself is shown once, when
+// it is created. Requests with the key act as the user with the role the
+// user had at that time.
+type APIKey struct {
+	ID        string
+	UserID    string
+	Username  string
+	Role      string
+	Name      string
+	Hash      string
+	CreatedAt time.Time
+}
+
+// CreateAPIKeyRequest names a new API key so its owner can tell keys
+// apart.
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,max=64"`
+}
+
+// Validate checks that the request names the key.
+func (r *CreateAPIKeyRequest) Validate() error {
+	return ValidateStruct(r)
+}
+
+// APIKeyResponse carries a newly created API key. Key is the plaintext
+// key and is never returned again.
+type APIKeyResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+}
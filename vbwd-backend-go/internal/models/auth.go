@@ -0,0 +1,138 @@
+package models
+
+// LoginRequest represents the login request payload. Username accepts
+// either the username or the email address of the account.
+type LoginRequest struct {
+	Username string `json:"username" validate:"required,min=3"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Validate checks that the login request contains the required fields and
+// returns a *ValidationError listing every violation.
+func (r *LoginRequest) Validate() error {
+	return ValidateStruct(r)
+}
+
+// LoginResponse represents the login response payload. Users with MFA
+// enabled get MFARequired and a short-lived MFAToken instead of tokens and
+// finish the login at POST /mfa/login.
+type LoginResponse struct {
+	Success      bool   `json:"success"`
+	Message      string `json:"message"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
+}
+
+// Login grants select the tokens a login issues. Clients choose one with
+// the grant query parameter of the login endpoints.
+const (
+	GrantAccessRefresh = "access_refresh"
+	GrantAccessOnly    = "access_only"
+)
+
+// ParseGrant validates the grant query parameter. An empty value is
+// returned as is and selects the configured default.
+func ParseGrant(grant string) (string, error) {
+	switch grant {
+	case "", GrantAccessRefresh, GrantAccessOnly:
+		return grant, nil
+	}
+	verr := &ValidationError{}
+	verr.Add("grant", ErrInvalidGrant)
+	return "", verr
+}
+
+// RefreshRequest represents the token refresh request payload.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// Validate checks that the refresh request contains a token.
+func (r *RefreshRequest) Validate() error {
+	return ValidateStruct(r)
+}
+
+// Registration constraints, mirrored by the validate tags of
+// RegisterRequest.
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 32
+	MinPasswordLength = 8
+)
+
+// RegisterRequest represents the registration request payload.
+type RegisterRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=32"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email_address"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// Validate checks the username length, the email format when an email is
+// given and the minimum password length, and returns a *ValidationError
+// listing every violation.
+func (r *RegisterRequest) Validate() error {
+	return ValidateStruct(r)
+}
+
+// RegisterResponse represents the registration response payload.
+type RegisterResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	UserID  string `json:"user_id,omitempty"`
+}
+
+// ChangePasswordRequest represents the change-password request payload.
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// Validate checks that both passwords are present.
+func (r *ChangePasswordRequest) Validate() error {
+	return ValidateStruct(r)
+}
+
+// ForgotPasswordRequest represents the forgot-password request payload.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email_address"`
+}
+
+// Validate checks that a well-formed email is present.
+func (r *ForgotPasswordRequest) Validate() error {
+	return ValidateStruct(r)
+}
+
+// ResetPasswordRequest represents the reset-password request payload.
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// Validate checks that the token and new password are present.
+func (r *ResetPasswordRequest) Validate() error {
+	return ValidateStruct(r)
+}
+
+// MessageResponse is a success flag with a human-readable message.
+type MessageResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// User roles.
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// User represents an application user. Password holds the bcrypt hash,
+// never the plaintext value. Email is optional and stored normalized.
+type User struct {
+	ID       string
+	Username string
+	Email    string
+	Password string
+	Role     string
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// TokenTypeBearer is the type reported for issued tokens.
+const TokenTypeBearer = "Bearer"
+
+// TokenInfo describes an issued token together with its metadata.
+type TokenInfo struct {
+	Value     string    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Type      string    `json:"type"`
+}
+
+// LoginResponseV2 is the login response of API version 2, which nests each
+// token with its expiry and type. Like LoginResponse it carries only an
+// MFAToken while a code is required.
+type LoginResponseV2 struct {
+	Success      bool       `json:"success"`
+	Message      string     `json:"message"`
+	Token        *TokenInfo `json:"token,omitempty"`
+	RefreshToken *TokenInfo `json:"refresh_token,omitempty"`
+	MFARequired  bool       `json:"mfa_required,omitempty"`
+	MFAToken     *TokenInfo `json:"mfa_token,omitempty"`
+}
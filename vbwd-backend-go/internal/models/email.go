@@ -0,0 +1,26 @@
+package models
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// MaxEmailLength is the longest email address accepted (RFC 5321).
+const MaxEmailLength = 254
+
+// NormalizeEmail trims surrounding whitespace and lower-cases an email
+// address so that lookups and uniqueness checks are case-insensitive.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidEmail reports whether email is a bare address such as
+// "alice@example.com". Display names ("Alice <alice@example.com>") are
+// rejected.
+func ValidEmail(email string) bool {
+	if email == "" || len(email) > MaxEmailLength {
+		return false
+	}
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email && strings.Contains(email[strings.LastIndex(email, "@"):], ".")
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CodedError is a domain error carrying a stable machine-readable code and
+// the HTTP status it maps to. Sentinels are compared by identity, so
+// errors.Is works as with plain errors.New values.
+type CodedError struct {
+	Code    string
+	Message string
+	Status  int
+}
+
+// Error returns the human-readable message.
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+// ErrorCode returns the stable error code.
+func (e *CodedError) ErrorCode() string {
+	return e.Code
+}
+
+// HTTPStatus returns the HTTP status the error maps to.
+func (e *CodedError) HTTPStatus() int {
+	return e.Status
+}
+
+// Domain errors returned by the service layer.
+var (
+	ErrInvalidCredentials   = &CodedError{"INVALID_CREDENTIALS", "invalid credentials", http.StatusUnauthorized}
+	ErrUserNotFound         = &CodedError{"USER_NOT_FOUND", "user not found", http.StatusNotFound}
+	ErrUsernameRequired     = &CodedError{"USERNAME_REQUIRED", "username is required", http.StatusBadRequest}
+	ErrPasswordRequired     = &CodedError{"PASSWORD_REQUIRED", "password is required", http.StatusBadRequest}
+	ErrRefreshTokenRequired = &CodedError{"REFRESH_TOKEN_REQUIRED", "refresh token is required", http.StatusBadRequest}
+	ErrTokenRequired        = &CodedError{"TOKEN_REQUIRED", "token is required", http.StatusBadRequest}
+	ErrUsernameLength       = &CodedError{"USERNAME_LENGTH", "username must be between 3 and 32 characters", http.StatusBadRequest}
+	ErrUsernameTooShort     = &CodedError{"USERNAME_TOO_SHORT", "username must be at least 3 characters", http.StatusBadRequest}
+	ErrPasswordTooShort     = &CodedError{"PASSWORD_TOO_SHORT", "password must be at least 8 characters", http.StatusBadRequest}
+	ErrUserExists           = &CodedError{"USER_EXISTS", "user already exists", http.StatusConflict}
+	ErrEmailExists          = &CodedError{"EMAIL_EXISTS", "email is already registered", http.StatusConflict}
+	ErrInvalidEmail         = &CodedError{"INVALID_EMAIL", "email must be a valid address", http.StatusBadRequest}
+	ErrAccountLocked        = &CodedError{"ACCOUNT_LOCKED", "account temporarily locked", http.StatusLocked}
+	ErrInvalidToken         = &CodedError{"INVALID_TOKEN", "invalid token", http.StatusUnauthorized}
+	ErrOldPasswordRequired  = &CodedError{"OLD_PASSWORD_REQUIRED", "old password is required", http.StatusBadRequest}
+	ErrNewPasswordRequired  = &CodedError{"NEW_PASSWORD_REQUIRED", "new password is required", http.StatusBadRequest}
+	ErrWeakPassword         = &CodedError{"WEAK_PASSWORD", "password does not meet the strength policy", http.StatusUnprocessableEntity}
+	ErrInvalidOffset        = &CodedError{"INVALID_OFFSET", "offset must be a non-negative integer", http.StatusBadRequest}
+	ErrEmailRequired        = &CodedError{"EMAIL_REQUIRED", "email is required", http.StatusBadRequest}
+	ErrResetTokenRequired   = &CodedError{"RESET_TOKEN_REQUIRED", "reset token is required", http.StatusBadRequest}
+	ErrInvalidResetToken    = &CodedError{"INVALID_RESET_TOKEN", "reset token is invalid or expired", http.StatusBadRequest}
+	ErrInvalidLimit         = &CodedError{"INVALID_LIMIT", "limit must be a positive integer", http.StatusBadRequest}
+	ErrEnabledRequired      = &CodedError{"ENABLED_REQUIRED", "enabled is required", http.StatusBadRequest}
+	ErrSessionNotFound      = &CodedError{"SESSION_NOT_FOUND", "session not found", http.StatusNotFound}
+	ErrCannotDeleteSelf     = &CodedError{"CANNOT_DELETE_SELF", "admins cannot delete their own account", http.StatusConflict}
+	ErrIdentityNotLinked    = &CodedError{"IDENTITY_NOT_LINKED", "this identity is not linked to an account", http.StatusForbidden}
+	ErrIdentityLinked       = &CodedError{"IDENTITY_ALREADY_LINKED", "this identity is linked to another account", http.StatusConflict}
+	ErrMFACodeRequired      = &CodedError{"MFA_CODE_REQUIRED", "code is required", http.StatusBadRequest}
+	ErrMFATokenRequired     = &CodedError{"MFA_TOKEN_REQUIRED", "mfa token is required", http.StatusBadRequest}
+	ErrInvalidMFACode       = &CodedError{"INVALID_MFA_CODE", "invalid authentication code", http.StatusUnauthorized}
+	ErrMFANotEnrolled       = &CodedError{"MFA_NOT_ENROLLED", "no pending MFA enrollment; call POST /mfa/enroll first", http.StatusConflict}
+	ErrMFAAlreadyEnabled    = &CodedError{"MFA_ALREADY_ENABLED", "MFA is already enabled", http.StatusConflict}
+	ErrInvalidGrant         = &CodedError{"INVALID_GRANT", "grant must be access_only or access_refresh", http.StatusBadRequest}
+	ErrNameRequired         = &CodedError{"NAME_REQUIRED", "name is required", http.StatusBadRequest}
+	ErrAPIKeyNotFound       = &CodedError{"API_KEY_NOT_FOUND", "API key not found", http.StatusNotFound}
+)
+
+// WeakPasswordError lists the password policy rules a password failed. It
+// matches ErrWeakPassword with errors.Is.
+type WeakPasswordError struct {
+	Violations []string
+}
+
+// Error explains which rules failed.
+func (e *WeakPasswordError) Error() string {
+	return "password must contain " + strings.Join(e.Violations, ", ")
+}
+
+// ErrorCode returns the code of ErrWeakPassword.
+func (e *WeakPasswordError) ErrorCode() string {
+	return ErrWeakPassword.Code
+}
+
+// HTTPStatus returns the status of ErrWeakPassword.
+func (e *WeakPasswordError) HTTPStatus() int {
+	return ErrWeakPassword.Status
+}
+
+// Is matches ErrWeakPassword.
+func (e *WeakPasswordError) Is(target error) bool {
+	return target == ErrWeakPassword
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Service statuses reported by the health endpoint.
+const (
+	StatusHealthy     = "healthy"
+	StatusMaintenance = "maintenance"
+)
+
+// HealthResponse represents the health check response payload.
+type HealthResponse struct {
+	Status        string    `json:"status"`
+	Timestamp     time.Time `json:"timestamp"`
+	Service       string    `json:"service"`
+	Version       string    `json:"version"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+}
+
+// CheckReasonTimeout marks a check that did not finish within its timeout.
+const CheckReasonTimeout = "timeout"
+
+// CheckResult is the outcome of a single readiness check. Reason is set to
+// CheckReasonTimeout when the check ran out of time. DurationMS is how long
+// the check ran, in milliseconds.
+type CheckResult struct {
+	Name       string  `json:"name"`
+	Healthy    bool    `json:"healthy"`
+	DurationMS float64 `json:"duration_ms"`
+	Reason     string  `json:"reason,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// ReadinessResponse aggregates the results of all readiness checks. In
+// maintenance mode the service is not ready and no checks are run.
+type ReadinessResponse struct {
+	Ready       bool          `json:"ready"`
+	Maintenance bool          `json:"maintenance,omitempty"`
+	Timestamp   time.Time     `json:"timestamp"`
+	Checks      []CheckResult `json:"checks"`
+}
+
+// ReadinessEvent is one readiness evaluation in the history: whether the
+// service was ready, when, and which checks failed.
+type ReadinessEvent struct {
+	Ready        bool      `json:"ready"`
+	Maintenance  bool      `json:"maintenance,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	FailedChecks []string  `json:"failed_checks,omitempty"`
+}
+
+// ReadinessHistoryResponse lists the latest readiness evaluations, oldest
+// first. At most Capacity evaluations are kept.
+type ReadinessHistoryResponse struct {
+	Capacity int              `json:"capacity"`
+	Events   []ReadinessEvent `json:"events"`
+}
+
+// MaintenanceRequest toggles maintenance mode.
+type MaintenanceRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// Validate checks that the flag is present.
+func (r *MaintenanceRequest) Validate() error {
+	return ValidateStruct(r)
+}
+
+// MaintenanceResponse reports the current maintenance mode.
+type MaintenanceResponse struct {
+	Maintenance bool `json:"maintenance"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// ExternalIdentity is a user as reported by an external identity provider
+// after a successful login there.
+type ExternalIdentity struct {
+	// Provider is the name of the identity provider.
+	Provider string
+	// Subject is the provider's stable ID of the user.
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+}
+
+// IdentityLink records that the user of an external provider is a local
+// user. Links are created by the local user, so external logins never rely
+// on an unverified local email.
+type IdentityLink struct {
+	Provider string
+	Subject  string
+	UserID   string
+	Username string
+}
+
+// OAuthLinkResponse starts linking an external identity to the caller.
+type OAuthLinkResponse struct {
+	// AuthorizationURL is the provider page to send the user to.
+	AuthorizationURL string `json:"authorization_url"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// IntrospectRequest is the payload of POST /introspect.
+type IntrospectRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Validate checks that the introspection request contains a token.
+func (r *IntrospectRequest) Validate() error {
+	return ValidateStruct(r)
+}
+
+// IntrospectResponse describes a token in the style of RFC 7662. Inactive
+// tokens carry no other members.
+type IntrospectResponse struct {
+	Active    bool   `json:"active"`
+	Subject   string `json:"sub,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Role      string `json:"role,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
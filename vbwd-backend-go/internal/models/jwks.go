@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// JWK is a JSON Web Key (RFC 7517) describing an RSA public key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyRotationResponse reports the key that signs tokens after a rotation
+// and until when tokens signed with earlier keys keep verifying.
+type KeyRotationResponse struct {
+	KeyID                  string    `json:"kid"`
+	PreviousKeysValidUntil time.Time `json:"previous_keys_valid_until"`
+}
@@ -0,0 +1,42 @@
+package models
+
+// MFAEnrollment is the TOTP secret of a user. It is pending until the user
+// confirms it with a valid code, and only enabled secrets are asked for at
+// login.
+type MFAEnrollment struct {
+	Secret  string
+	Enabled bool
+	// LastStep is the TOTP time step of the last accepted code. Codes of
+	// that or an earlier step are rejected, so each code works once.
+	LastStep int64
+}
+
+// MFAEnrollResponse carries a new TOTP secret and the otpauth:// URL
+// authenticator apps import it from, usually rendered as a QR code.
+type MFAEnrollResponse struct {
+	Secret string `json:"secret"`
+	URL    string `json:"otpauth_url"`
+}
+
+// MFAVerifyRequest confirms a pending enrollment with a code of the new
+// secret.
+type MFAVerifyRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// Validate checks that the verify request contains a code.
+func (r *MFAVerifyRequest) Validate() error {
+	return ValidateStruct(r)
+}
+
+// MFALoginRequest completes a login with the challenge token returned by
+// POST /login and a code of the user's authenticator.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// Validate checks that the challenge token and the code are present.
+func (r *MFALoginRequest) Validate() error {
+	return ValidateStruct(r)
+}
@@ -0,0 +1,51 @@
+package models
+
+import "strconv"
+
+// Pagination limits for list endpoints.
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Page is one page of a listing together with the total number of items.
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// PageRequest selects a page of a listing.
+type PageRequest struct {
+	Offset int
+	Limit  int
+}
+
+// ParsePageRequest reads the offset and limit query values. Missing values
+// default to offset 0 and DefaultPageLimit; limits above MaxPageLimit are
+// clamped.
+func ParsePageRequest(offset, limit string) (PageRequest, error) {
+	req := PageRequest{Limit: DefaultPageLimit}
+	verr := &ValidationError{}
+
+	if offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			verr.Add("offset", ErrInvalidOffset)
+		} else {
+			req.Offset = n
+		}
+	}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			verr.Add("limit", ErrInvalidLimit)
+		} else {
+			req.Limit = min(n, MaxPageLimit)
+		}
+	}
+
+	return req, verr.ErrOrNil()
+}
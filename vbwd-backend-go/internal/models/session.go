@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Session is a login of a user on one device. Tokens issued at login carry
+// the session ID and stop validating once the session is revoked.
+type Session struct {
+	ID        string
+	UserID    string
+	Device    string
+	CreatedAt time.Time
+	LastSeen  time.Time
+}
+
+// SessionDTO is the public representation of a Session. Current marks the
+// session of the token used for the request.
+type SessionDTO struct {
+	ID        string    `json:"id"`
+	Device    string    `json:"device"`
+	CreatedAt time.Time `json:"created_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	Current   bool      `json:"current"`
+}
+
+// SessionListResponse lists the sessions of the caller.
+type SessionListResponse struct {
+	Sessions []SessionDTO `json:"sessions"`
+}
+
+// ToSessionDTO maps a Session to its public representation. currentID is
+// the session ID of the requesting token.
+func ToSessionDTO(session Session, currentID string) SessionDTO {
+	return SessionDTO{
+		ID:        session.ID,
+		Device:    session.Device,
+		CreatedAt: session.CreatedAt.UTC(),
+		LastSeen:  session.LastSeen.UTC(),
+		Current:   currentID != "" && session.ID == currentID,
+	}
+}
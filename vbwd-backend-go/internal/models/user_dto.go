@@ -0,0 +1,30 @@
+package models
+
+// UserDTO is the public representation of a User. It deliberately has no
+// password field so hashes cannot be serialized by accident; always map a
+// User through ToDTO before returning it from the API.
+type UserDTO struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email,omitempty"`
+	Role     string `json:"role"`
+}
+
+// ToDTO maps a User to its public representation.
+func ToDTO(user User) UserDTO {
+	return UserDTO{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     user.Role,
+	}
+}
+
+// ToDTOs maps users to their public representations.
+func ToDTOs(users []User) []UserDTO {
+	dtos := make([]UserDTO, 0, len(users))
+	for _, user := range users {
+		dtos = append(dtos, ToDTO(user))
+	}
+	return dtos
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// tagEmailAddress validates a bare email address with ValidEmail after
+// normalization, the same rule the service layer applies.
+const tagEmailAddress = "email_address"
+
+// ErrInvalidField is reported for struct tag violations that have no
+// dedicated sentinel.
+var ErrInvalidField = &CodedError{"INVALID_FIELD", "field is invalid", http.StatusBadRequest}
+
+// fieldSentinels maps a JSON field name and the failing validate tag to the
+// sentinel reported for it, keeping the codes clients already rely on.
+// Keys qualified with the struct name ("RegisterRequest.username/min")
+// take precedence over bare field keys.
+var fieldSentinels = map[string]*CodedError{
+	"username/required":                ErrUsernameRequired,
+	"username/min":                     ErrUsernameTooShort,
+	"RegisterRequest.username/min":     ErrUsernameLength,
+	"RegisterRequest.username/max":     ErrUsernameLength,
+	"password/required":                ErrPasswordRequired,
+	"password/min":                     ErrPasswordTooShort,
+	"refresh_token/required":           ErrRefreshTokenRequired,
+	"old_password/required":            ErrOldPasswordRequired,
+	"new_password/required":            ErrNewPasswordRequired,
+	"email/required":                   ErrEmailRequired,
+	"email/" + tagEmailAddress:         ErrInvalidEmail,
+	"token/required":                   ErrResetTokenRequired,
+	"IntrospectRequest.token/required": ErrTokenRequired,
+	"enabled/required":                 ErrEnabledRequired,
+	"code/required":                    ErrMFACodeRequired,
+	"mfa_token/required":               ErrMFATokenRequired,
+	"name/required":                    ErrNameRequired,
+}
+
+// structValidator is shared because validator.Validate caches struct
+// metadata. Fields are reported by their JSON names.
+var structValidator = newStructValidator()
+
+func newStructValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// Registration only fails for empty tags or nil functions.
+	_ = v.RegisterValidation(tagEmailAddress, func(fl validator.FieldLevel) bool {
+		return ValidEmail(NormalizeEmail(fl.Field().String()))
+	})
+	return v
+}
+
+// ValidateStruct checks v against its validate struct tags and returns a
+// *ValidationError listing every violation in field order, or nil.
+// Violations map to the existing field sentinels where one exists and to
+// ErrInvalidField otherwise.
+func ValidateStruct(v any) error {
+	err := structValidator.Struct(v)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	var verr ValidationError
+	for _, fe := range fieldErrs {
+		verr.Errors = append(verr.Errors, toFieldError(fe))
+	}
+	return verr.ErrOrNil()
+}
+
+// toFieldError converts a validator error to a FieldError.
+func toFieldError(fe validator.FieldError) FieldError {
+	for _, key := range []string{fe.Namespace(), fe.Field()} {
+		if sentinel, ok := fieldSentinels[key+"/"+fe.Tag()]; ok {
+			return NewFieldError(fe.Field(), sentinel)
+		}
+	}
+
+	message := fmt.Sprintf("%s failed the %s check", fe.Field(), fe.Tag())
+	if fe.Param() != "" {
+		message = fmt.Sprintf("%s failed the %s=%s check", fe.Field(), fe.Tag(), fe.Param())
+	}
+	return FieldError{Field: fe.Field(), Message: message, Code: ErrInvalidField.Code, err: ErrInvalidField}
+}
@@ -0,0 +1,55 @@
+package models
+
+import "strings"
+
+// FieldError describes a single invalid request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Code    string `json:"code"`
+
+	err *CodedError
+}
+
+// NewFieldError creates a FieldError for field from a sentinel error.
+func NewFieldError(field string, err *CodedError) FieldError {
+	return FieldError{Field: field, Message: err.Message, Code: err.Code, err: err}
+}
+
+// ValidationError collects every violation found in a request.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Add records a violation for field.
+func (e *ValidationError) Add(field string, err *CodedError) {
+	e.Errors = append(e.Errors, NewFieldError(field, err))
+}
+
+// ErrOrNil returns the ValidationError when it holds violations, or nil.
+func (e *ValidationError) ErrOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error joins the messages of all violations.
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether any violation was caused by target, so callers can
+// keep matching individual sentinels with errors.Is.
+func (e *ValidationError) Is(target error) bool {
+	for _, fe := range e.Errors {
+		if fe.err == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,512 @@
+// Package openapi describes the HTTP API as an OpenAPI 3.0 document whose
+// schemas are derived from the json tags of the request and response models.
+package openapi
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// Document is the root of an OpenAPI 3.0 document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps lower-case HTTP methods to operations.
+type PathItem map[string]Operation
+
+// Operation describes a single endpoint.
+type Operation struct {
+	Summary     string              `json:"summary"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// RequestBody describes the JSON body an operation accepts.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes one response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas referenced by operations.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// New builds the document describing the service's endpoints, reporting
+// version as the API version.
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: title, Version: version},
+		Paths: map[string]PathItem{
+			"/health": {
+				"get": {
+					Summary: "Report service health",
+					Responses: map[string]Response{
+						"200": jsonResponse("Service is healthy", "HealthResponse"),
+						"304": {Description: "Status, service and version match the If-None-Match ETag"},
+					},
+				},
+			},
+			"/readyz": {
+				"get": {
+					Summary: "Run readiness checks",
+					Responses: map[string]Response{
+						"200": jsonResponse("All checks passed", "ReadinessResponse"),
+						"503": jsonResponse("At least one check failed", "ReadinessResponse"),
+					},
+				},
+			},
+			"/healthz/history": {
+				"get": {
+					Summary: "List the latest readiness evaluations, oldest first",
+					Responses: map[string]Response{
+						"200": jsonResponse("Readiness history", "ReadinessHistoryResponse"),
+					},
+				},
+			},
+			"/version": {
+				"get": {
+					Summary: "Report build metadata",
+					Responses: map[string]Response{
+						"200": jsonResponse("Build metadata", "VersionResponse"),
+					},
+				},
+			},
+			"/metrics": {
+				"get": {
+					Summary: "Prometheus metrics",
+					Responses: map[string]Response{
+						"200": {
+							Description: "Metrics in the Prometheus text exposition format",
+							Content:     map[string]MediaType{"text/plain": {Schema: &Schema{Type: "string"}}},
+						},
+					},
+				},
+			},
+			"/openapi.json": {
+				"get": {
+					Summary: "This OpenAPI document",
+					Responses: map[string]Response{
+						"200": {
+							Description: "OpenAPI 3.0 document",
+							Content:     map[string]MediaType{"application/json": {Schema: &Schema{Type: "object"}}},
+						},
+					},
+				},
+			},
+			"/login": {
+				"post": {
+					Summary:     "Exchange credentials for tokens",
+					RequestBody: jsonBody("LoginRequest"),
+					Responses: map[string]Response{
+						"200": jsonResponse("Login successful", "LoginResponse"),
+						"400": jsonResponse("Malformed request body", "ErrorEnvelope"),
+						"401": jsonResponse("Invalid credentials", "LoginResponse"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"423": jsonResponse("Account temporarily locked", "LoginResponse"),
+						"429": jsonResponse("Too many requests", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/v1/login": {
+				"post": {
+					Summary:     "Exchange credentials for tokens (version 1, same as /login)",
+					RequestBody: jsonBody("LoginRequest"),
+					Responses: map[string]Response{
+						"200": jsonResponse("Login successful", "LoginResponse"),
+						"400": jsonResponse("Malformed request body", "ErrorEnvelope"),
+						"401": jsonResponse("Invalid credentials", "LoginResponse"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"423": jsonResponse("Account temporarily locked", "LoginResponse"),
+						"429": jsonResponse("Too many requests", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/v2/login": {
+				"post": {
+					Summary:     "Exchange credentials for tokens with expiry metadata (version 2)",
+					RequestBody: jsonBody("LoginRequest"),
+					Responses: map[string]Response{
+						"200": jsonResponse("Login successful", "LoginResponseV2"),
+						"400": jsonResponse("Malformed request body", "ErrorEnvelope"),
+						"401": jsonResponse("Invalid credentials", "ErrorEnvelope"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"422": jsonResponse("Validation failed", "ErrorEnvelope"),
+						"423": jsonResponse("Account temporarily locked", "ErrorEnvelope"),
+						"429": jsonResponse("Too many requests", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/refresh": {
+				"post": {
+					Summary:     "Exchange a refresh token for an access token",
+					RequestBody: jsonBody("RefreshRequest"),
+					Responses: map[string]Response{
+						"200": jsonResponse("Token refreshed", "LoginResponse"),
+						"401": jsonResponse("Invalid refresh token", "LoginResponse"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/register": {
+				"post": {
+					Summary:     "Create a user account",
+					RequestBody: jsonBody("RegisterRequest"),
+					Responses: map[string]Response{
+						"201": jsonResponse("User created", "RegisterResponse"),
+						"409": jsonResponse("Username already taken", "ErrorEnvelope"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"422": jsonResponse("Validation failed", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/password": {
+				"post": {
+					Summary:     "Change the caller's password and revoke their other credentials",
+					RequestBody: jsonBody("ChangePasswordRequest"),
+					Responses: map[string]Response{
+						"204": {Description: "Password changed"},
+						"400": jsonResponse("Malformed request body", "ErrorEnvelope"),
+						"401": jsonResponse("Missing or invalid bearer token, or wrong old password", "ErrorEnvelope"),
+						"403": jsonResponse("Called with an API key", "ErrorEnvelope"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"422": jsonResponse("Validation failed", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/password/forgot": {
+				"post": {
+					Summary:     "Request a password reset token",
+					RequestBody: jsonBody("ForgotPasswordRequest"),
+					Responses: map[string]Response{
+						"200": jsonResponse("Reset requested; returned whether or not the email is registered", "MessageResponse"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"422": jsonResponse("Validation failed", "ErrorEnvelope"),
+						"429": jsonResponse("Too many requests", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/password/reset": {
+				"post": {
+					Summary:     "Set a new password with a reset token",
+					RequestBody: jsonBody("ResetPasswordRequest"),
+					Responses: map[string]Response{
+						"200": jsonResponse("Password reset", "MessageResponse"),
+						"400": jsonResponse("Invalid or expired reset token", "ErrorEnvelope"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"422": jsonResponse("Validation failed", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/admin/maintenance": {
+				"put": {
+					Summary:     "Switch maintenance mode (admin only)",
+					RequestBody: jsonBody("MaintenanceRequest"),
+					Responses: map[string]Response{
+						"200": jsonResponse("Current maintenance mode", "MaintenanceResponse"),
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Caller is not an admin", "ErrorEnvelope"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"422": jsonResponse("Validation failed", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/admin/rotate-key": {
+				"post": {
+					Summary: "Rotate the token signing key (admin only)",
+					Responses: map[string]Response{
+						"200": jsonResponse("ID of the new key and end of the grace window", "KeyRotationResponse"),
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Caller is not an admin", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/auth/{provider}/login": {
+				"get": {
+					Summary: "Start a login at an external OpenID Connect provider",
+					Responses: map[string]Response{
+						"302": {Description: "Redirect to the provider"},
+						"404": jsonResponse("Unknown provider", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/auth/{provider}/callback": {
+				"get": {
+					Summary: "Complete an external login and issue tokens, or link the identity",
+					Responses: map[string]Response{
+						"200": jsonResponse("Login successful", "LoginResponse"),
+						"204": {Description: "Identity linked to the user who started the link"},
+						"400": jsonResponse("Missing code or invalid state", "ErrorEnvelope"),
+						"401": jsonResponse("Login denied by the provider, or expired link", "ErrorEnvelope"),
+						"403": jsonResponse("Identity is not linked to an account", "ErrorEnvelope"),
+						"404": jsonResponse("Unknown provider", "ErrorEnvelope"),
+						"409": jsonResponse("Identity is linked to another account", "ErrorEnvelope"),
+						"429": jsonResponse("Too many requests", "ErrorEnvelope"),
+						"502": jsonResponse("Code exchange with the provider failed", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/auth/{provider}/link": {
+				"post": {
+					Summary: "Start linking an external identity to the caller",
+					Responses: map[string]Response{
+						"200": jsonResponse("Provider URL to send the user to", "OAuthLinkResponse"),
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Called with an API key", "ErrorEnvelope"),
+						"404": jsonResponse("Unknown provider", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/mfa/enroll": {
+				"post": {
+					Summary: "Generate a TOTP secret for the caller",
+					Responses: map[string]Response{
+						"200": jsonResponse("Pending secret and otpauth URL", "MFAEnrollResponse"),
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Called with an API key", "ErrorEnvelope"),
+						"409": jsonResponse("MFA is already enabled", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/mfa/verify": {
+				"post": {
+					Summary:     "Confirm the pending TOTP secret and enable MFA",
+					RequestBody: jsonBody("MFAVerifyRequest"),
+					Responses: map[string]Response{
+						"200": jsonResponse("MFA enabled", "MessageResponse"),
+						"400": jsonResponse("Malformed request body", "ErrorEnvelope"),
+						"401": jsonResponse("Missing or invalid bearer token, or wrong code", "ErrorEnvelope"),
+						"403": jsonResponse("Called with an API key", "ErrorEnvelope"),
+						"409": jsonResponse("No pending secret, or MFA already enabled", "ErrorEnvelope"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"422": jsonResponse("Validation failed", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/mfa/login": {
+				"post": {
+					Summary:     "Exchange an MFA challenge token and a TOTP code for tokens",
+					RequestBody: jsonBody("MFALoginRequest"),
+					Responses: map[string]Response{
+						"200": jsonResponse("Login successful", "LoginResponse"),
+						"400": jsonResponse("Malformed request body", "ErrorEnvelope"),
+						"401": jsonResponse("Invalid challenge token or wrong code", "ErrorEnvelope"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"422": jsonResponse("Validation failed", "ErrorEnvelope"),
+						"423": jsonResponse("Account temporarily locked", "ErrorEnvelope"),
+						"429": jsonResponse("Too many requests", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/whoami": {
+				"get": {
+					Summary: "Identity of the authenticated caller",
+					Responses: map[string]Response{
+						"200": jsonResponse("The caller", "UserDTO"),
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/sessions": {
+				"get": {
+					Summary: "Login sessions of the caller",
+					Responses: map[string]Response{
+						"200": jsonResponse("The caller's sessions", "SessionListResponse"),
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Called with an API key", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/sessions/{id}": {
+				"delete": {
+					Summary: "Revoke one login session of the caller",
+					Responses: map[string]Response{
+						"204": {Description: "Session revoked"},
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Called with an API key", "ErrorEnvelope"),
+						"404": jsonResponse("No such session of the caller", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/apikeys": {
+				"post": {
+					Summary:     "Create an API key acting as the caller",
+					RequestBody: jsonBody("CreateAPIKeyRequest"),
+					Responses: map[string]Response{
+						"201": jsonResponse("The new key, shown only once", "APIKeyResponse"),
+						"400": jsonResponse("Malformed request body", "ErrorEnvelope"),
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Called with an API key", "ErrorEnvelope"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"422": jsonResponse("Validation failed", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/apikeys/{id}": {
+				"delete": {
+					Summary: "Revoke an API key of the caller",
+					Responses: map[string]Response{
+						"204": {Description: "API key revoked"},
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Called with an API key", "ErrorEnvelope"),
+						"404": jsonResponse("No such API key of the caller", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/logout-all": {
+				"post": {
+					Summary: "Revoke every login session of the caller",
+					Responses: map[string]Response{
+						"204": {Description: "All sessions revoked"},
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Called with an API key", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/introspect": {
+				"post": {
+					Summary:     "Report whether a token is active (RFC 7662 style)",
+					RequestBody: jsonBody("IntrospectRequest"),
+					Responses: map[string]Response{
+						"200": jsonResponse("Token state; inactive tokens only carry active: false", "IntrospectResponse"),
+						"400": jsonResponse("Malformed request body", "ErrorEnvelope"),
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Caller is not an admin", "ErrorEnvelope"),
+						"415": jsonResponse("Content-Type is not application/json", "ErrorEnvelope"),
+						"422": jsonResponse("Validation failed", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/.well-known/jwks.json": {
+				"get": {
+					Summary: "Public keys verifying RS256 tokens",
+					Responses: map[string]Response{
+						"200": jsonResponse("JSON Web Key Set", "JWKS"),
+					},
+				},
+			},
+			"/users": {
+				"get": {
+					Summary: "List users (admin only)",
+					Responses: map[string]Response{
+						"200": jsonResponse("A page of users", "UserPage"),
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Caller is not an admin", "ErrorEnvelope"),
+					},
+				},
+			},
+			"/users/{id}": {
+				"delete": {
+					Summary: "Delete a user (admin only)",
+					Responses: map[string]Response{
+						"204": {Description: "User deleted"},
+						"401": jsonResponse("Missing or invalid bearer token", "ErrorEnvelope"),
+						"403": jsonResponse("Caller is not an admin", "ErrorEnvelope"),
+						"404": jsonResponse("No user with this ID", "ErrorEnvelope"),
+						"409": jsonResponse("Admins cannot delete their own account", "ErrorEnvelope"),
+					},
+				},
+			},
+		},
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"HealthResponse":           SchemaFor(models.HealthResponse{}),
+				"ReadinessResponse":        SchemaFor(models.ReadinessResponse{}),
+				"ReadinessHistoryResponse": SchemaFor(models.ReadinessHistoryResponse{}),
+				"VersionResponse":          SchemaFor(models.VersionResponse{}),
+				"LoginRequest":             SchemaFor(models.LoginRequest{}),
+				"LoginResponse":            SchemaFor(models.LoginResponse{}),
+				"LoginResponseV2":          SchemaFor(models.LoginResponseV2{}),
+				"RefreshRequest":           SchemaFor(models.RefreshRequest{}),
+				"IntrospectRequest":        SchemaFor(models.IntrospectRequest{}),
+				"OAuthLinkResponse":        SchemaFor(models.OAuthLinkResponse{}),
+				"IntrospectResponse":       SchemaFor(models.IntrospectResponse{}),
+				"MFAEnrollResponse":        SchemaFor(models.MFAEnrollResponse{}),
+				"MFAVerifyRequest":         SchemaFor(models.MFAVerifyRequest{}),
+				"MFALoginRequest":          SchemaFor(models.MFALoginRequest{}),
+				"RegisterRequest":          SchemaFor(models.RegisterRequest{}),
+				"RegisterResponse":         SchemaFor(models.RegisterResponse{}),
+				"ChangePasswordRequest":    SchemaFor(models.ChangePasswordRequest{}),
+				"ForgotPasswordRequest":    SchemaFor(models.ForgotPasswordRequest{}),
+				"ResetPasswordRequest":     SchemaFor(models.ResetPasswordRequest{}),
+				"MessageResponse":          SchemaFor(models.MessageResponse{}),
+				"MaintenanceRequest":       SchemaFor(models.MaintenanceRequest{}),
+				"MaintenanceResponse":      SchemaFor(models.MaintenanceResponse{}),
+				"KeyRotationResponse":      SchemaFor(models.KeyRotationResponse{}),
+				"UserDTO":                  SchemaFor(models.UserDTO{}),
+				"JWKS":                     SchemaFor(models.JWKS{}),
+				"SessionListResponse":      SchemaFor(models.SessionListResponse{}),
+				"CreateAPIKeyRequest":      SchemaFor(models.CreateAPIKeyRequest{}),
+				"APIKeyResponse":           SchemaFor(models.APIKeyResponse{}),
+				"UserPage":                 SchemaFor(models.Page[models.UserDTO]{}),
+				"ErrorEnvelope":            SchemaFor(response.ErrorEnvelope{}),
+			},
+		},
+	}
+}
+
+// WithPrefix returns a copy of the document whose paths start with prefix,
+// matching routes registered under a route prefix. Paths listed in
+// unprefixed keep their path. The copy shares operations and schemas
+// with d.
+func (d *Document) WithPrefix(prefix string, unprefixed ...string) *Document {
+	prefixed := *d
+	prefixed.Paths = make(map[string]PathItem, len(d.Paths))
+	for path, item := range d.Paths {
+		if !slices.Contains(unprefixed, path) {
+			path = prefix + path
+		}
+		prefixed.Paths[path] = item
+	}
+	return &prefixed
+}
+
+// Handler serves doc as JSON.
+func Handler(doc *Document) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusOK, doc)
+	}
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func jsonBody(schema string) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"application/json": {Schema: ref(schema)}},
+	}
+}
+
+func jsonResponse(description, schema string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: ref(schema)}},
+	}
+}
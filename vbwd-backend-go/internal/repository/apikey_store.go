@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// APIKeyStore keeps API keys by the hash of the key, so a leaked store
+// cannot be used to authenticate.
+type APIKeyStore interface {
+	// Create stores a new key.
+	Create(ctx context.Context, key models.APIKey) error
+	// FindByHash returns the key with the given hash and
+	// models.ErrAPIKeyNotFound when there is none.
+	FindByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	// Delete removes a key of the user and returns models.ErrAPIKeyNotFound
+	// when the user has no key with that ID.
+	Delete(ctx context.Context, userID, id string) error
+	// DeleteByUser removes every key of the user and returns how many
+	// were removed.
+	DeleteByUser(ctx context.Context, userID string) (int, error)
+}
+
+// inMemoryAPIKeyStore keeps API keys in a map keyed by key hash.
+type inMemoryAPIKeyStore struct {
+	mu   sync.Mutex
+	keys map[string]models.APIKey
+}
+
+// NewInMemoryAPIKeyStore creates an empty in-memory APIKeyStore.
+func NewInMemoryAPIKeyStore() APIKeyStore {
+	return &inMemoryAPIKeyStore{keys: make(map[string]models.APIKey)}
+}
+
+// Create stores the key.
+func (s *inMemoryAPIKeyStore) Create(ctx context.Context, key models.APIKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.Hash] = key
+	return nil
+}
+
+// FindByHash returns a copy of the key.
+func (s *inMemoryAPIKeyStore) FindByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[hash]
+	if !ok {
+		return nil, models.ErrAPIKeyNotFound
+	}
+	return &key, nil
+}
+
+// Delete removes the key when it belongs to the user.
+func (s *inMemoryAPIKeyStore) Delete(ctx context.Context, userID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, key := range s.keys {
+		if key.ID == id && key.UserID == userID {
+			delete(s.keys, hash)
+			return nil
+		}
+	}
+	return models.ErrAPIKeyNotFound
+}
+
+// DeleteByUser removes the keys of the user.
+func (s *inMemoryAPIKeyStore) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for hash, key := range s.keys {
+		if key.UserID == userID {
+			delete(s.keys, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// Default user cache settings.
+const (
+	DefaultUserCacheCapacity = 1000
+	DefaultUserCacheTTL      = time.Minute
+)
+
+// CacheOptions configures NewCachingUserRepository. Zero values select the
+// defaults.
+type CacheOptions struct {
+	// Capacity is the number of lookups kept; the least recently used one
+	// is evicted when it is exceeded.
+	Capacity int
+	// TTL is how long a cached user is served before the backing
+	// repository is asked again.
+	TTL time.Duration
+	// Now provides the current time; nil uses time.Now.
+	Now func() time.Time
+}
+
+// cachingUserRepository serves FindByUsername and FindByEmail from a TTL
+// and LRU bounded cache in front of next. Misses and errors are not
+// cached, so Create needs no invalidation.
+type cachingUserRepository struct {
+	UserRepository
+
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+	// generation counts invalidations. A lookup that raced with one does
+	// not cache what it loaded, which may predate the change.
+	generation uint64
+}
+
+// cacheEntry is a cached lookup result.
+type cacheEntry struct {
+	key       string
+	user      models.User
+	expiresAt time.Time
+}
+
+// NewCachingUserRepository wraps next with a lookup cache. Entries of a
+// user are dropped when UpdatePassword or Delete change that user through
+// the returned repository; changes made to the backing store by other
+// processes show up once the TTL has passed.
+func NewCachingUserRepository(next UserRepository, opts CacheOptions) UserRepository {
+	if opts.Capacity <= 0 {
+		opts.Capacity = DefaultUserCacheCapacity
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultUserCacheTTL
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &cachingUserRepository{
+		UserRepository: next,
+		capacity:       opts.Capacity,
+		ttl:            opts.TTL,
+		now:            opts.Now,
+		order:          list.New(),
+		entries:        make(map[string]*list.Element),
+	}
+}
+
+// FindByUsername returns the cached user or loads it from the backing
+// repository.
+func (r *cachingUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.lookup(ctx, "username:"+username, func() (*models.User, error) {
+		return r.UserRepository.FindByUsername(ctx, username)
+	})
+}
+
+// FindByEmail returns the cached user or loads it from the backing
+// repository.
+func (r *cachingUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.lookup(ctx, "email:"+email, func() (*models.User, error) {
+		return r.UserRepository.FindByEmail(ctx, email)
+	})
+}
+
+// UpdatePassword updates the backing repository and drops the cached
+// entries of the user.
+func (r *cachingUserRepository) UpdatePassword(ctx context.Context, id, hash string) error {
+	err := r.UserRepository.UpdatePassword(ctx, id, hash)
+	r.invalidate(id)
+	return err
+}
+
+// Delete deletes from the backing repository and drops the cached entries
+// of the user.
+func (r *cachingUserRepository) Delete(ctx context.Context, id string) error {
+	err := r.UserRepository.Delete(ctx, id)
+	r.invalidate(id)
+	return err
+}
+
+// lookup returns a copy of the cached user under key, calling load on a
+// miss or an expired entry.
+func (r *cachingUserRepository) lookup(ctx context.Context, key string, load func() (*models.User, error)) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if elem, ok := r.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if r.now().Before(entry.expiresAt) {
+			r.order.MoveToFront(elem)
+			user := entry.user
+			r.mu.Unlock()
+			return &user, nil
+		}
+		r.remove(elem)
+	}
+	generation := r.generation
+	r.mu.Unlock()
+
+	user, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if generation != r.generation {
+		return user, nil
+	}
+	if elem, ok := r.entries[key]; ok {
+		r.remove(elem)
+	}
+	r.entries[key] = r.order.PushFront(&cacheEntry{key: key, user: *user, expiresAt: r.now().Add(r.ttl)})
+	if r.order.Len() > r.capacity {
+		r.remove(r.order.Back())
+	}
+	return user, nil
+}
+
+// invalidate drops every entry of the user with the given ID. Mutations
+// are rare, so the entries are scanned rather than indexed by ID.
+func (r *cachingUserRepository) invalidate(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	for elem := r.order.Front(); elem != nil; {
+		next := elem.Next()
+		if elem.Value.(*cacheEntry).user.ID == id {
+			r.remove(elem)
+		}
+		elem = next
+	}
+}
+
+// remove drops elem from the cache. The caller holds mu.
+func (r *cachingUserRepository) remove(elem *list.Element) {
+	r.order.Remove(elem)
+	delete(r.entries, elem.Value.(*cacheEntry).key)
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// IdentityLinkStore keeps the links between external identities and local
+// users, keyed by provider and the provider's subject.
+type IdentityLinkStore interface {
+	// Find returns the link of the identity and models.ErrIdentityNotLinked
+	// when there is none.
+	Find(ctx context.Context, provider, subject string) (models.IdentityLink, error)
+	// Link stores a link. Linking an identity to its user again is a no-op,
+	// and an identity linked to another user returns
+	// models.ErrIdentityLinked.
+	Link(ctx context.Context, link models.IdentityLink) error
+}
+
+// identityKey identifies an external identity.
+type identityKey struct {
+	provider string
+	subject  string
+}
+
+// inMemoryIdentityLinkStore keeps links in a map keyed by identity.
+type inMemoryIdentityLinkStore struct {
+	mu    sync.Mutex
+	links map[identityKey]models.IdentityLink
+}
+
+// NewInMemoryIdentityLinkStore creates an empty in-memory
+// IdentityLinkStore.
+func NewInMemoryIdentityLinkStore() IdentityLinkStore {
+	return &inMemoryIdentityLinkStore{links: make(map[identityKey]models.IdentityLink)}
+}
+
+// Find returns the link of the identity.
+func (s *inMemoryIdentityLinkStore) Find(ctx context.Context, provider, subject string) (models.IdentityLink, error) {
+	if err := ctx.Err(); err != nil {
+		return models.IdentityLink{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[identityKey{provider, subject}]
+	if !ok {
+		return models.IdentityLink{}, models.ErrIdentityNotLinked
+	}
+	return link, nil
+}
+
+// Link stores the link unless the identity belongs to another user.
+func (s *inMemoryIdentityLinkStore) Link(ctx context.Context, link models.IdentityLink) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := identityKey{link.Provider, link.Subject}
+	if existing, ok := s.links[key]; ok && existing.UserID != link.UserID {
+		return models.ErrIdentityLinked
+	}
+	s.links[key] = link
+	return nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// inMemoryUserRepository stores users in a map keyed by username.
+type inMemoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]models.User
+}
+
+// NewInMemoryUserRepository creates a UserRepository seeded with the given users.
+func NewInMemoryUserRepository(seed ...models.User) UserRepository {
+	users := make(map[string]models.User, len(seed))
+	for _, user := range seed {
+		users[user.Username] = user
+	}
+	return &inMemoryUserRepository{users: users}
+}
+
+// FindByUsername returns a copy of the stored user.
+func (r *inMemoryUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[username]
+	if !exists {
+		return nil, models.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// FindByEmail returns a copy of the user with the given email.
+func (r *inMemoryUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if email != "" {
+		for _, user := range r.users {
+			if user.Email == email {
+				return &user, nil
+			}
+		}
+	}
+	return nil, models.ErrUserNotFound
+}
+
+// Create stores a new user.
+func (r *inMemoryUserRepository) Create(ctx context.Context, user models.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insert(user)
+}
+
+// BulkCreate stores the users under a single lock so the batch is not
+// interleaved with other writes.
+func (r *inMemoryUserRepository) BulkCreate(ctx context.Context, users []models.User) (int, []error) {
+	if err := ctx.Err(); err != nil {
+		return 0, []error{err}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	imported := 0
+	var errs []error
+	for _, user := range users {
+		if err := r.insert(user); err != nil {
+			errs = append(errs, fmt.Errorf("user %q: %w", user.Username, err))
+			continue
+		}
+		imported++
+	}
+	return imported, errs
+}
+
+// insert stores user unless its username or email is taken. The caller
+// holds the write lock.
+func (r *inMemoryUserRepository) insert(user models.User) error {
+	if _, exists := r.users[user.Username]; exists {
+		return models.ErrUserExists
+	}
+	if user.Email != "" {
+		for _, existing := range r.users {
+			if existing.Email == user.Email {
+				return models.ErrEmailExists
+			}
+		}
+	}
+	r.users[user.Username] = user
+	return nil
+}
+
+// UpdatePassword replaces the password hash of the user with the given ID.
+func (r *inMemoryUserRepository) UpdatePassword(ctx context.Context, id, hash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for username, user := range r.users {
+		if user.ID == id {
+			user.Password = hash
+			r.users[username] = user
+			return nil
+		}
+	}
+	return models.ErrUserNotFound
+}
+
+// Delete removes the user with the given ID.
+func (r *inMemoryUserRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for username, user := range r.users {
+		if user.ID == id {
+			delete(r.users, username)
+			return nil
+		}
+	}
+	return models.ErrUserNotFound
+}
+
+// List returns a page of users ordered by username.
+func (r *inMemoryUserRepository) List(ctx context.Context, offset, limit int) ([]models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	usernames := make([]string, 0, len(r.users))
+	for username := range r.users {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	if offset >= len(usernames) {
+		return []models.User{}, nil
+	}
+	end := min(offset+limit, len(usernames))
+
+	users := make([]models.User, 0, end-offset)
+	for _, username := range usernames[offset:end] {
+		users = append(users, r.users[username])
+	}
+	return users, nil
+}
+
+// Count returns the number of stored users.
+func (r *inMemoryUserRepository) Count(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.users), nil
+}
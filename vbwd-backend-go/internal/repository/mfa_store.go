@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// MFAStore keeps the TOTP enrollments of users by user ID. Secrets have to
+// be stored readable to compute codes, so the store must be protected like
+// a password vault.
+type MFAStore interface {
+	// Get returns the enrollment of the user and models.ErrMFANotEnrolled
+	// when the user has none.
+	Get(ctx context.Context, userID string) (models.MFAEnrollment, error)
+	// Save stores the enrollment of the user, replacing an earlier one.
+	Save(ctx context.Context, userID string, enrollment models.MFAEnrollment) error
+	// UseStep atomically records step as the last accepted TOTP time step
+	// of the user. It returns false when that or a later step was already
+	// accepted and models.ErrMFANotEnrolled when the user has no
+	// enrollment.
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+}
+
+// inMemoryMFAStore keeps enrollments in a map keyed by user ID.
+type inMemoryMFAStore struct {
+	mu          sync.Mutex
+	enrollments map[string]models.MFAEnrollment
+}
+
+// NewInMemoryMFAStore creates an empty in-memory MFAStore.
+func NewInMemoryMFAStore() MFAStore {
+	return &inMemoryMFAStore{enrollments: make(map[string]models.MFAEnrollment)}
+}
+
+// Get returns the enrollment of the user.
+func (s *inMemoryMFAStore) Get(ctx context.Context, userID string) (models.MFAEnrollment, error) {
+	if err := ctx.Err(); err != nil {
+		return models.MFAEnrollment{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enrollment, ok := s.enrollments[userID]
+	if !ok {
+		return models.MFAEnrollment{}, models.ErrMFANotEnrolled
+	}
+	return enrollment, nil
+}
+
+// Save stores the enrollment of the user.
+func (s *inMemoryMFAStore) Save(ctx context.Context, userID string, enrollment models.MFAEnrollment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enrollments[userID] = enrollment
+	return nil
+}
+
+// UseStep advances the last accepted step of the user.
+func (s *inMemoryMFAStore) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enrollment, ok := s.enrollments[userID]
+	if !ok {
+		return false, models.ErrMFANotEnrolled
+	}
+	if step <= enrollment.LastStep {
+		return false, nil
+	}
+	enrollment.LastStep = step
+	s.enrollments[userID] = enrollment
+	return true, nil
+}
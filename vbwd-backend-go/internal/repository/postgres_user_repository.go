@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// UsersTableSchema creates the table used by the PostgreSQL repository.
+const UsersTableSchema = `
+CREATE TABLE IF NOT EXISTS users (
+	id            TEXT PRIMARY KEY,
+	username      TEXT NOT NULL UNIQUE,
+	email         TEXT CONSTRAINT users_email_key UNIQUE,
+	password_hash TEXT NOT NULL,
+	role          TEXT NOT NULL DEFAULT 'user',
+	created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`
+
+// uniqueViolation is the PostgreSQL SQLSTATE for unique constraint violations.
+const uniqueViolation = "23505"
+
+// emailConstraint names the unique constraint on users.email.
+const emailConstraint = "users_email_key"
+
+// userColumns selects a user; a NULL email reads as "".
+const userColumns = `id, username, COALESCE(email, ''), password_hash, role`
+
+// postgresUserRepository stores users in PostgreSQL via database/sql.
+type postgresUserRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresUserRepository creates a UserRepository backed by db. The
+// caller owns the connection and is responsible for registering a driver.
+func NewPostgresUserRepository(db *sql.DB) UserRepository {
+	return &postgresUserRepository{db: db}
+}
+
+// FindByUsername looks up a user by username.
+func (r *postgresUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	err := r.db.QueryRowContext(ctx,
+		`SELECT `+userColumns+` FROM users WHERE username = $1`,
+		username,
+	).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find user by username: %w", err)
+	}
+	return &user, nil
+}
+
+// FindByEmail looks up a user by normalized email.
+func (r *postgresUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	if email == "" {
+		return nil, models.ErrUserNotFound
+	}
+
+	var user models.User
+	err := r.db.QueryRowContext(ctx,
+		`SELECT `+userColumns+` FROM users WHERE email = $1`,
+		email,
+	).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find user by email: %w", err)
+	}
+	return &user, nil
+}
+
+// Create inserts a new user.
+func (r *postgresUserRepository) Create(ctx context.Context, user models.User) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO users (id, username, email, password_hash, role) VALUES ($1, $2, NULLIF($3, ''), $4, $5)`,
+		user.ID, user.Username, user.Email, user.Password, user.Role,
+	)
+	if isUniqueViolation(err) {
+		// Drivers report the violated constraint in the message.
+		if strings.Contains(err.Error(), emailConstraint) {
+			return models.ErrEmailExists
+		}
+		return models.ErrUserExists
+	}
+	if err != nil {
+		return fmt.Errorf("create user: %w", err)
+	}
+	return nil
+}
+
+// BulkCreate inserts the users in one transaction. Conflicting rows are
+// skipped with ON CONFLICT DO NOTHING and then attributed to the username
+// or email constraint.
+func (r *postgresUserRepository) BulkCreate(ctx context.Context, users []models.User) (int, []error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, []error{fmt.Errorf("bulk create users: %w", err)}
+	}
+	defer tx.Rollback()
+
+	imported := 0
+	var skipped []error
+	for _, user := range users {
+		result, err := tx.ExecContext(ctx,
+			`INSERT INTO users (id, username, email, password_hash, role) VALUES ($1, $2, NULLIF($3, ''), $4, $5) ON CONFLICT DO NOTHING`,
+			user.ID, user.Username, user.Email, user.Password, user.Role,
+		)
+		if err != nil {
+			return 0, []error{fmt.Errorf("bulk create user %q: %w", user.Username, err)}
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, []error{fmt.Errorf("bulk create user %q: %w", user.Username, err)}
+		}
+		if rows == 1 {
+			imported++
+			continue
+		}
+
+		conflict := models.ErrEmailExists
+		var usernameTaken bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE username = $1)`, user.Username).Scan(&usernameTaken)
+		if err != nil {
+			return 0, []error{fmt.Errorf("bulk create user %q: %w", user.Username, err)}
+		}
+		if usernameTaken {
+			conflict = models.ErrUserExists
+		}
+		skipped = append(skipped, fmt.Errorf("user %q: %w", user.Username, conflict))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, []error{fmt.Errorf("bulk create users: %w", err)}
+	}
+	return imported, skipped
+}
+
+// UpdatePassword replaces the password hash of the user with the given ID.
+func (r *postgresUserRepository) UpdatePassword(ctx context.Context, id, hash string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2`, hash, id)
+	if err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+	if rows == 0 {
+		return models.ErrUserNotFound
+	}
+	return nil
+}
+
+// Delete removes the user with the given ID.
+func (r *postgresUserRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	if rows == 0 {
+		return models.ErrUserNotFound
+	}
+	return nil
+}
+
+// List returns a page of users ordered by username.
+func (r *postgresUserRepository) List(ctx context.Context, offset, limit int) ([]models.User, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+userColumns+` FROM users ORDER BY username LIMIT $1 OFFSET $2`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role); err != nil {
+			return nil, fmt.Errorf("list users: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	return users, nil
+}
+
+// Count returns the number of rows in the users table.
+func (r *postgresUserRepository) Count(ctx context.Context) (int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count users: %w", err)
+	}
+	return total, nil
+}
+
+// isUniqueViolation detects unique constraint errors from any driver that
+// exposes the SQLSTATE code (lib/pq, pgx).
+func isUniqueViolation(err error) bool {
+	var stateErr interface{ SQLState() string }
+	return errors.As(err, &stateErr) && stateErr.SQLState() == uniqueViolation
+}
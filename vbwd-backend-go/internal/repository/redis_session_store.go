@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// Redis keys of the session store. Each session is a JSON value under
+// redisSessionKeyPrefix and its ID. A set per user indexes the sessions of
+// the user, and a sorted set scored by last-seen time in microseconds
+// finds idle sessions.
+const (
+	redisSessionKeyPrefix      = "vbwd:session:"
+	redisUserSessionsKeyPrefix = "vbwd:user-sessions:"
+	redisSessionsLastSeenKey   = "vbwd:sessions-last-seen"
+)
+
+// redisSessionStore keeps sessions in Redis so that every instance of the
+// service sees the same sessions, and a revocation on one instance takes
+// effect on all of them. Writes update a session and its index entries in
+// one MULTI transaction, which Redis Cluster rejects for keys in different
+// slots, so the store needs a single Redis server.
+type redisSessionStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisSessionStore creates a SessionStore backed by client. A session
+// expires ttl after it was last seen; pass the refresh token lifetime, the
+// longest any token of the session can remain valid. A ttl of zero keeps
+// sessions until they are deleted. The caller owns the client, which must
+// connect to a single Redis server rather than a cluster.
+func NewRedisSessionStore(client *redis.Client, ttl time.Duration) SessionStore {
+	return &redisSessionStore{client: client, ttl: ttl}
+}
+
+// Create stores the session and indexes it.
+func (s *redisSessionStore) Create(ctx context.Context, session models.Session) error {
+	value, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("encode session: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, redisSessionKeyPrefix+session.ID, value, s.ttl)
+		pipe.SAdd(ctx, redisUserSessionsKeyPrefix+session.UserID, session.ID)
+		pipe.ZAdd(ctx, redisSessionsLastSeenKey, redis.Z{Score: lastSeenScore(session.LastSeen), Member: session.ID})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+	return nil
+}
+
+// Touch updates the last-seen time and restarts the expiry of the session.
+func (s *redisSessionStore) Touch(ctx context.Context, id string, at time.Time) (*models.Session, error) {
+	sessions, err := s.getMany(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	session := sessions[0]
+	if session == nil {
+		return nil, models.ErrSessionNotFound
+	}
+	if !at.After(session.LastSeen) {
+		return session, nil
+	}
+
+	session.LastSeen = at
+	value, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("encode session: %w", err)
+	}
+	// SET XX keeps a session deleted since it was read from coming back.
+	var updated *redis.BoolCmd
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		updated = pipe.SetXX(ctx, redisSessionKeyPrefix+id, value, s.ttl)
+		pipe.ZAddXX(ctx, redisSessionsLastSeenKey, redis.Z{Score: lastSeenScore(at), Member: id})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("touch session: %w", err)
+	}
+	if !updated.Val() {
+		return nil, models.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// ListByUser returns the user's sessions ordered by creation and drops
+// index entries of sessions that have expired.
+func (s *redisSessionStore) ListByUser(ctx context.Context, userID string) ([]models.Session, error) {
+	ids, err := s.client.SMembers(ctx, redisUserSessionsKeyPrefix+userID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	found, err := s.getMany(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []models.Session{}
+	var expired []string
+	for i, session := range found {
+		if session == nil {
+			expired = append(expired, ids[i])
+			continue
+		}
+		sessions = append(sessions, *session)
+	}
+	if len(expired) > 0 {
+		if _, err := s.remove(ctx, userID, expired...); err != nil {
+			return nil, err
+		}
+	}
+	sortSessions(sessions)
+	return sessions, nil
+}
+
+// Delete removes the session when it belongs to the user.
+func (s *redisSessionStore) Delete(ctx context.Context, userID, id string) error {
+	sessions, err := s.getMany(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sessions[0] == nil || sessions[0].UserID != userID {
+		return models.ErrSessionNotFound
+	}
+
+	removed, err := s.remove(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return models.ErrSessionNotFound
+	}
+	return nil
+}
+
+// DeleteByUser removes all sessions of the user.
+func (s *redisSessionStore) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	ids, err := s.client.SMembers(ctx, redisUserSessionsKeyPrefix+userID).Result()
+	if err != nil {
+		return 0, fmt.Errorf("list sessions: %w", err)
+	}
+	return s.remove(ctx, userID, ids...)
+}
+
+// DeleteIdleSince removes sessions last seen before cutoff. Sessions that
+// already expired are only dropped from the index and not counted.
+func (s *redisSessionStore) DeleteIdleSince(ctx context.Context, cutoff time.Time) (int, error) {
+	ids, err := s.client.ZRangeByScore(ctx, redisSessionsLastSeenKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatFloat(lastSeenScore(cutoff), 'f', -1, 64),
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("find idle sessions: %w", err)
+	}
+	found, err := s.getMany(ctx, ids...)
+	if err != nil {
+		return 0, err
+	}
+
+	idleByUser := make(map[string][]string)
+	var expired []any
+	for i, session := range found {
+		switch {
+		case session == nil:
+			expired = append(expired, ids[i])
+		case session.LastSeen.Before(cutoff):
+			idleByUser[session.UserID] = append(idleByUser[session.UserID], session.ID)
+		}
+	}
+	if len(expired) > 0 {
+		if err := s.client.ZRem(ctx, redisSessionsLastSeenKey, expired...).Err(); err != nil {
+			return 0, fmt.Errorf("delete idle sessions: %w", err)
+		}
+	}
+
+	removed := 0
+	for userID, idle := range idleByUser {
+		n, err := s.remove(ctx, userID, idle...)
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	return removed, nil
+}
+
+// getMany reads the sessions with the given IDs. Missing sessions are nil.
+func (s *redisSessionStore) getMany(ctx context.Context, ids ...string) ([]*models.Session, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = redisSessionKeyPrefix + id
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get sessions: %w", err)
+	}
+
+	sessions := make([]*models.Session, len(ids))
+	for i, value := range values {
+		encoded, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var session models.Session
+		if err := json.Unmarshal([]byte(encoded), &session); err != nil {
+			return nil, fmt.Errorf("decode session: %w", err)
+		}
+		sessions[i] = &session
+	}
+	return sessions, nil
+}
+
+// remove deletes sessions of the user with their index entries and
+// returns how many sessions existed.
+func (s *redisSessionStore) remove(ctx context.Context, userID string, ids ...string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	members := make([]any, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+
+	// One DEL per key to count which sessions existed.
+	deletes := make([]*redis.IntCmd, len(ids))
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			deletes[i] = pipe.Del(ctx, redisSessionKeyPrefix+id)
+		}
+		pipe.SRem(ctx, redisUserSessionsKeyPrefix+userID, members...)
+		pipe.ZRem(ctx, redisSessionsLastSeenKey, members...)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("delete sessions: %w", err)
+	}
+
+	removed := 0
+	for _, deleted := range deletes {
+		removed += int(deleted.Val())
+	}
+	return removed, nil
+}
+
+// lastSeenScore converts a last-seen time to its sorted set score.
+// Microseconds since the epoch fit a float64 exactly.
+func lastSeenScore(t time.Time) float64 {
+	return float64(t.UnixMicro())
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// ResetTokenStore keeps password reset tokens. Tokens are stored by their
+// hash so a leaked store cannot be used to reset passwords.
+type ResetTokenStore interface {
+	// Save stores a token hash for the user, replacing any earlier tokens
+	// of that user.
+	Save(ctx context.Context, tokenHash, userID string, expiresAt time.Time) error
+	// Consume removes the token and returns its user and expiry. It
+	// returns models.ErrInvalidResetToken when the token is unknown or was
+	// already used.
+	Consume(ctx context.Context, tokenHash string) (userID string, expiresAt time.Time, err error)
+}
+
+type resetToken struct {
+	userID    string
+	expiresAt time.Time
+}
+
+// inMemoryResetTokenStore keeps reset tokens in a map keyed by token hash.
+type inMemoryResetTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]resetToken
+}
+
+// NewInMemoryResetTokenStore creates an empty in-memory ResetTokenStore.
+func NewInMemoryResetTokenStore() ResetTokenStore {
+	return &inMemoryResetTokenStore{tokens: make(map[string]resetToken)}
+}
+
+// Save stores the token and drops earlier tokens of the same user.
+func (s *inMemoryResetTokenStore) Save(ctx context.Context, tokenHash, userID string, expiresAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, token := range s.tokens {
+		if token.userID == userID {
+			delete(s.tokens, hash)
+		}
+	}
+	s.tokens[tokenHash] = resetToken{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+// Consume removes and returns the token.
+func (s *inMemoryResetTokenStore) Consume(ctx context.Context, tokenHash string) (string, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return "", time.Time{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenHash]
+	if !ok {
+		return "", time.Time{}, models.ErrInvalidResetToken
+	}
+	delete(s.tokens, tokenHash)
+	return token.userID, token.expiresAt, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// SessionStore keeps the login sessions of users.
+type SessionStore interface {
+	// Create stores a new session.
+	Create(ctx context.Context, session models.Session) error
+	// Touch sets the last-seen time of the session and returns it. It
+	// returns models.ErrSessionNotFound when the session does not exist.
+	Touch(ctx context.Context, id string, at time.Time) (*models.Session, error)
+	// ListByUser returns the sessions of the user, oldest first.
+	ListByUser(ctx context.Context, userID string) ([]models.Session, error)
+	// Delete removes a session of the user and returns
+	// models.ErrSessionNotFound when the user has no session with that ID.
+	Delete(ctx context.Context, userID, id string) error
+	// DeleteByUser removes every session of the user and returns how many
+	// were removed.
+	DeleteByUser(ctx context.Context, userID string) (int, error)
+	// DeleteIdleSince removes sessions last seen before cutoff and returns
+	// how many were removed.
+	DeleteIdleSince(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// inMemorySessionStore keeps sessions in a map keyed by session ID.
+type inMemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]models.Session
+}
+
+// NewInMemorySessionStore creates an empty in-memory SessionStore.
+func NewInMemorySessionStore() SessionStore {
+	return &inMemorySessionStore{sessions: make(map[string]models.Session)}
+}
+
+// Create stores the session.
+func (s *inMemorySessionStore) Create(ctx context.Context, session models.Session) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[session.ID] = session
+	return nil
+}
+
+// Touch updates and returns a copy of the session.
+func (s *inMemorySessionStore) Touch(ctx context.Context, id string, at time.Time) (*models.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, models.ErrSessionNotFound
+	}
+	if at.After(session.LastSeen) {
+		session.LastSeen = at
+		s.sessions[id] = session
+	}
+	return &session, nil
+}
+
+// ListByUser returns copies of the user's sessions ordered by creation.
+func (s *inMemorySessionStore) ListByUser(ctx context.Context, userID string) ([]models.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := []models.Session{}
+	for _, session := range s.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	sortSessions(sessions)
+	return sessions, nil
+}
+
+// sortSessions orders sessions by creation time, then by ID.
+func sortSessions(sessions []models.Session) {
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].ID < sessions[j].ID
+		}
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+}
+
+// Delete removes the session when it belongs to the user.
+func (s *inMemorySessionStore) Delete(ctx context.Context, userID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.UserID != userID {
+		return models.ErrSessionNotFound
+	}
+	delete(s.sessions, id)
+	return nil
+}
+
+// DeleteByUser removes all sessions of the user.
+func (s *inMemorySessionStore) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// DeleteIdleSince removes sessions not seen since cutoff.
+func (s *inMemorySessionStore) DeleteIdleSince(ctx context.Context, cutoff time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for id, session := range s.sessions {
+		if session.LastSeen.Before(cutoff) {
+			delete(s.sessions, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// UserRepository abstracts user persistence from the service layer. Every
+// method honors cancellation and deadlines of the given context.
+type UserRepository interface {
+	// FindByUsername returns models.ErrUserNotFound when no user matches.
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	// FindByEmail looks up a user by normalized email and returns
+	// models.ErrUserNotFound when no user matches.
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// Create returns models.ErrUserExists when the username is taken and
+	// models.ErrEmailExists when the email is.
+	Create(ctx context.Context, user models.User) error
+	// UpdatePassword replaces the stored hash of the user with the given ID
+	// and returns models.ErrUserNotFound when no user matches.
+	UpdatePassword(ctx context.Context, id, hash string) error
+	// BulkCreate stores many users at once and returns how many were
+	// stored. Users whose username or email is taken, also by an earlier
+	// user of the batch, are skipped and reported in errs wrapping
+	// models.ErrUserExists or models.ErrEmailExists. Passwords must
+	// already be hashed; see services.ImportUsers. Any other failure
+	// stores no user and is returned as the only error.
+	BulkCreate(ctx context.Context, users []models.User) (imported int, errs []error)
+	// Delete removes the user with the given ID and returns
+	// models.ErrUserNotFound when no user matches.
+	Delete(ctx context.Context, id string) error
+	// List returns up to limit users ordered by username, starting at
+	// offset.
+	List(ctx context.Context, offset, limit int) ([]models.User, error)
+	// Count returns the total number of users.
+	Count(ctx context.Context) (int, error)
+}
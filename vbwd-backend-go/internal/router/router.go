@@ -0,0 +1,300 @@
+package router
+
+import (
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/openapi"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/i18n"
+)
+
+// Login rate limit per client IP.
+const (
+	loginRateLimitRPS   = 5
+	loginRateLimitBurst = 10
+)
+
+// Dependencies are the handlers and settings the router wires to routes.
+type Dependencies struct {
+	AuthHandler *handlers.AuthHandler
+	// AuthHandlerV2 serves POST /v2/login when set.
+	AuthHandlerV2  *handlers.AuthHandlerV2
+	HealthHandler  *handlers.HealthHandler
+	VersionHandler *handlers.VersionHandler
+	UserHandler    *handlers.UserHandler
+	// JWKSHandler serves GET /.well-known/jwks.json when set. The path is
+	// never prefixed.
+	JWKSHandler *handlers.JWKSHandler
+	// OAuthHandler serves GET /auth/{provider}/login,
+	// GET /auth/{provider}/callback and POST /auth/{provider}/link when set.
+	OAuthHandler *handlers.OAuthHandler
+	// KeyRotationHandler serves POST /admin/rotate-key when set.
+	KeyRotationHandler *handlers.KeyRotationHandler
+	// IntrospectionHandler serves POST /introspect when set.
+	IntrospectionHandler *handlers.IntrospectionHandler
+	// MFAHandler serves POST /mfa/enroll, POST /mfa/verify and
+	// POST /mfa/login when set.
+	MFAHandler *handlers.MFAHandler
+	// SessionHandler serves GET /sessions, DELETE /sessions/{id} and
+	// POST /logout-all when set.
+	SessionHandler *handlers.SessionHandler
+	// APIKeyHandler serves POST /apikeys and DELETE /apikeys/{id} when
+	// set. Pass a TokenService from services.NewAPIKeyTokenService so the
+	// keys are accepted.
+	APIKeyHandler *handlers.APIKeyHandler
+	// PasswordResetHandler serves the forgot-password flow.
+	PasswordResetHandler *handlers.PasswordResetHandler
+	// DisableRegistration leaves POST /register unregistered, so requests
+	// to it get 404.
+	DisableRegistration bool
+	// IdempotencyStore keeps replayable POST /register responses; nil
+	// selects an in-memory store.
+	IdempotencyStore middleware.IdempotencyStore
+	// IdempotencyTTL is how long responses are replayable; zero selects
+	// the default of 24h.
+	IdempotencyTTL time.Duration
+	TokenService   services.TokenService
+	// LoginIPBlocker blocks client IPs with many failed logins across
+	// usernames; nil selects one with the default policy.
+	LoginIPBlocker services.IPBlocker
+	// OpenAPI is served at GET /openapi.json when set, with its paths
+	// moved under RoutePrefix like the routes.
+	OpenAPI *openapi.Document
+
+	// RoutePrefix is prepended to every route, e.g. "/api/v1". GET /health
+	// and GET /readyz stay un-prefixed for infrastructure probes unless
+	// PrefixProbes is set.
+	RoutePrefix  string
+	PrefixProbes bool
+
+	CORSAllowedOrigins []string
+	// TrustedProxies are the proxies whose X-Forwarded-For identifies the
+	// client for rate limits, login blocking and audit records.
+	TrustedProxies []netip.Prefix
+	// RedirectHTTPS redirects plain HTTP requests, detected through
+	// X-Forwarded-Proto, to HTTPS. Health and readiness probes are exempt.
+	RedirectHTTPS bool
+	// HSTSMaxAge enables Strict-Transport-Security with this max-age when
+	// positive. Set it only when the service is reached over HTTPS.
+	HSTSMaxAge time.Duration
+	// MaxConcurrentRequests caps the requests served at once; zero disables
+	// the cap. Requests beyond it wait up to ConcurrencyWait for a slot and
+	// are then answered with 503. Health and readiness probes are exempt.
+	MaxConcurrentRequests int
+	ConcurrencyWait       time.Duration
+	// MaxBodyBytes limits request bodies; zero selects the default of 1MB.
+	MaxBodyBytes int64
+	// RequestTimeout is the deadline of each request; zero disables it.
+	RequestTimeout time.Duration
+	// Messages translates error messages for the Accept-Language of the
+	// request; nil selects i18n.DefaultCatalog().
+	Messages i18n.Catalog
+}
+
+// Route is a registered request pattern.
+type Route struct {
+	Method string
+	// Pattern is the path pattern including the route prefix, e.g.
+	// "/api/v1/users/{id}".
+	Pattern string
+}
+
+// Router is the handler built by NewRouter. It knows the routes it serves.
+type Router struct {
+	http.Handler
+	routes []Route
+}
+
+// Routes returns the registered routes in registration order.
+func (r *Router) Routes() []Route {
+	return append([]Route(nil), r.routes...)
+}
+
+// routeMux is a ServeMux that records the method patterns registered on it.
+type routeMux struct {
+	*http.ServeMux
+	routes []Route
+}
+
+// Handle registers handler for a "METHOD /path" pattern. Requests it
+// serves are recorded in the metrics under the path pattern.
+func (m *routeMux) Handle(pattern string, handler http.Handler) {
+	method, path, _ := strings.Cut(pattern, " ")
+	m.routes = append(m.routes, Route{Method: method, Pattern: path})
+	m.ServeMux.Handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.RecordRoute(r, path)
+		handler.ServeHTTP(w, r)
+	}))
+}
+
+// HandleFunc registers handler for a "METHOD /path" pattern.
+func (m *routeMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// NewRouter registers all routes with method patterns on a dedicated
+// ServeMux under the configured prefix. It wraps the mux with client IP
+// resolution for the trusted proxies, request IDs, access logging,
+// Prometheus metrics, panic recovery, an optional HTTPS redirect, an
+// optional cap on concurrent requests, security headers, CORS, a request
+// body size limit, gzip compression, localized error messages and a
+// request deadline. Requests with a wrong method are answered with 405 by
+// the mux. The registered routes are listed by Routes.
+func NewRouter(deps Dependencies) *Router {
+	mux := &routeMux{ServeMux: http.NewServeMux()}
+	prefix := normalizePrefix(deps.RoutePrefix)
+	route := func(method, path string) string {
+		return method + " " + prefix + path
+	}
+	probePrefix := ""
+	if deps.PrefixProbes {
+		probePrefix = prefix
+	}
+	probe := func(method, path string) string {
+		return method + " " + probePrefix + path
+	}
+
+	mux.HandleFunc(probe("GET", "/health"), deps.HealthHandler.Health)
+	mux.HandleFunc(probe("GET", "/readyz"), deps.HealthHandler.Ready)
+	mux.HandleFunc(route("GET", "/healthz/history"), deps.HealthHandler.History)
+	mux.HandleFunc(route("GET", "/version"), deps.VersionHandler.Version)
+	if deps.JWKSHandler != nil {
+		mux.HandleFunc("GET /.well-known/jwks.json", deps.JWKSHandler.JWKS)
+	}
+	mux.Handle(route("GET", "/metrics"), promhttp.Handler())
+	if deps.OpenAPI != nil {
+		// The document lists paths without the route prefix. Serve it with
+		// the paths registered here.
+		unprefixed := []string{"/.well-known/jwks.json"}
+		if !deps.PrefixProbes {
+			unprefixed = append(unprefixed, "/health", "/readyz")
+		}
+		mux.HandleFunc(route("GET", "/openapi.json"), openapi.Handler(deps.OpenAPI.WithPrefix(prefix, unprefixed...)))
+	}
+	// account guards routes that manage the caller's account. They need
+	// a bearer token; API keys are rejected.
+	account := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.RequireAuth(middleware.RejectAPIKeys(next), deps.TokenService)
+	}
+	// POST /login is the unversioned alias of POST /v1/login. All login
+	// versions share one rate limit budget per client.
+	// Failed logins from one IP are counted across all versions too.
+	rateLimit := middleware.NewRateLimiter(loginRateLimitRPS, loginRateLimitBurst)
+	loginIPBlocker := deps.LoginIPBlocker
+	if loginIPBlocker == nil {
+		loginIPBlocker = services.NewIPBlocker(0, 0, nil)
+	}
+	blockFailedLogins := middleware.BlockFailedLogins(loginIPBlocker)
+	loginLimit := func(next http.HandlerFunc) http.HandlerFunc {
+		return rateLimit(blockFailedLogins(next))
+	}
+	mux.HandleFunc(route("POST", "/login"), loginLimit(middleware.RequireJSON(deps.AuthHandler.Login)))
+	mux.HandleFunc(route("POST", "/v1/login"), loginLimit(middleware.RequireJSON(deps.AuthHandler.Login)))
+	if deps.AuthHandlerV2 != nil {
+		mux.HandleFunc(route("POST", "/v2/login"), loginLimit(middleware.RequireJSON(deps.AuthHandlerV2.Login)))
+	}
+	if deps.OAuthHandler != nil {
+		mux.HandleFunc(route("GET", "/auth/{provider}/login"), deps.OAuthHandler.Login)
+		mux.HandleFunc(route("GET", "/auth/{provider}/callback"), loginLimit(deps.OAuthHandler.Callback))
+		mux.HandleFunc(route("POST", "/auth/{provider}/link"), account(deps.OAuthHandler.Link))
+	}
+	if deps.MFAHandler != nil {
+		mux.HandleFunc(route("POST", "/mfa/enroll"), account(deps.MFAHandler.Enroll))
+		mux.HandleFunc(route("POST", "/mfa/verify"), account(middleware.RequireJSON(deps.MFAHandler.Verify)))
+		mux.HandleFunc(route("POST", "/mfa/login"), loginLimit(middleware.RequireJSON(deps.MFAHandler.Login)))
+	}
+	mux.HandleFunc(route("POST", "/refresh"), middleware.RequireJSON(deps.AuthHandler.Refresh))
+	if !deps.DisableRegistration {
+		idempotencyStore := deps.IdempotencyStore
+		if idempotencyStore == nil {
+			idempotencyStore = middleware.NewInMemoryIdempotencyStore(nil)
+		}
+		mux.HandleFunc(route("POST", "/register"), middleware.RequireJSON(middleware.Idempotency(deps.AuthHandler.Register, idempotencyStore, deps.IdempotencyTTL)))
+	}
+	mux.HandleFunc(route("POST", "/password"), account(middleware.RequireJSON(deps.AuthHandler.ChangePassword)))
+	mux.HandleFunc(route("GET", "/whoami"), middleware.RequireAuth(deps.AuthHandler.WhoAmI, deps.TokenService))
+	if deps.SessionHandler != nil {
+		mux.HandleFunc(route("GET", "/sessions"), account(deps.SessionHandler.List))
+		mux.HandleFunc(route("DELETE", "/sessions/{id}"), account(deps.SessionHandler.Revoke))
+		mux.HandleFunc(route("POST", "/logout-all"), account(deps.SessionHandler.LogoutAll))
+	}
+	if deps.APIKeyHandler != nil {
+		mux.HandleFunc(route("POST", "/apikeys"), account(deps.APIKeyHandler.Create))
+		mux.HandleFunc(route("DELETE", "/apikeys/{id}"), account(deps.APIKeyHandler.Revoke))
+	}
+	if deps.IntrospectionHandler != nil {
+		mux.HandleFunc(route("POST", "/introspect"), middleware.RequireAuth(middleware.RequireRole(models.RoleAdmin, middleware.RequireJSON(deps.IntrospectionHandler.Introspect)), deps.TokenService))
+	}
+	mux.HandleFunc(route("POST", "/password/forgot"), middleware.RateLimit(middleware.RequireJSON(deps.PasswordResetHandler.Forgot), loginRateLimitRPS, loginRateLimitBurst))
+	mux.HandleFunc(route("POST", "/password/reset"), middleware.RequireJSON(deps.PasswordResetHandler.Reset))
+	mux.HandleFunc(route("PUT", "/admin/maintenance"), middleware.RequireAuth(middleware.RequireRole(models.RoleAdmin, middleware.RequireJSON(deps.HealthHandler.SetMaintenance)), deps.TokenService))
+	if deps.KeyRotationHandler != nil {
+		mux.HandleFunc(route("POST", "/admin/rotate-key"), middleware.RequireAuth(middleware.RequireRole(models.RoleAdmin, deps.KeyRotationHandler.Rotate), deps.TokenService))
+	}
+	mux.HandleFunc(route("GET", "/users"), middleware.RequireAuth(middleware.RequireRole(models.RoleAdmin, deps.UserHandler.List), deps.TokenService))
+	mux.HandleFunc(route("DELETE", "/users/{id}"), middleware.RequireAuth(middleware.RequireRole(models.RoleAdmin, deps.UserHandler.Delete), deps.TokenService))
+
+	messages := deps.Messages
+	if messages == nil {
+		messages = i18n.DefaultCatalog()
+	}
+	stack := []middleware.Middleware{
+		middleware.TrustProxies(deps.TrustedProxies),
+		middleware.RequestID,
+		middleware.Logging,
+		middleware.Metrics,
+		middleware.Recover,
+	}
+	probePaths := []string{probePrefix + "/health", probePrefix + "/readyz"}
+	if deps.RedirectHTTPS {
+		stack = append(stack, middleware.RedirectHTTPSExcept(probePaths...))
+	}
+	if deps.MaxConcurrentRequests > 0 {
+		stack = append(stack, func(next http.Handler) http.Handler {
+			limited := middleware.LimitConcurrencyWait(deps.MaxConcurrentRequests, deps.ConcurrencyWait, next)
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if slices.Contains(probePaths, r.URL.Path) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				limited.ServeHTTP(w, r)
+			})
+		})
+	}
+	stack = append(stack, middleware.SecureHeaders)
+	if deps.HSTSMaxAge > 0 {
+		stack = append(stack, middleware.HSTS(deps.HSTSMaxAge))
+	}
+	stack = append(stack,
+		middleware.CORS(deps.CORSAllowedOrigins),
+		middleware.MaxBodySize(deps.MaxBodyBytes),
+		middleware.Gzip,
+		middleware.Localize(messages),
+		func(next http.Handler) http.Handler {
+			return middleware.Timeout(deps.RequestTimeout, next)
+		},
+	)
+	return &Router{
+		Handler: middleware.Chain(stack...).Then(mux),
+		routes:  mux.routes,
+	}
+}
+
+// normalizePrefix returns prefix with a leading slash and no trailing slash,
+// or "" when no prefix is configured.
+func normalizePrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return "/" + prefix
+}
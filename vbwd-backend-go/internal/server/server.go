@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Timeouts bound how long the server waits on clients. Zero fields fall
+// back to the values of DefaultTimeouts.
+type Timeouts struct {
+	ReadHeader time.Duration
+	Read       time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// DefaultTimeouts returns timeouts that protect against slow clients
+// (slowloris) without cutting off regular API requests.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		ReadHeader: 5 * time.Second,
+		Read:       15 * time.Second,
+		Write:      15 * time.Second,
+		Idle:       60 * time.Second,
+	}
+}
+
+func (t Timeouts) withDefaults() Timeouts {
+	defaults := DefaultTimeouts()
+	if t.ReadHeader == 0 {
+		t.ReadHeader = defaults.ReadHeader
+	}
+	if t.Read == 0 {
+		t.Read = defaults.Read
+	}
+	if t.Write == 0 {
+		t.Write = defaults.Write
+	}
+	if t.Idle == 0 {
+		t.Idle = defaults.Idle
+	}
+	return t
+}
+
+// Server wraps an *http.Server with context-driven graceful shutdown.
+// Requests are tracked while in flight so shutdown can wait for them.
+type Server struct {
+	httpServer      *http.Server
+	shutdownTimeout time.Duration
+
+	inFlight      sync.WaitGroup
+	inFlightCount atomic.Int64
+
+	certFile string
+	keyFile  string
+}
+
+// New creates a Server listening on addr that applies timeouts to client
+// connections and drains in-flight requests for up to shutdownTimeout when
+// stopped.
+func New(addr string, handler http.Handler, shutdownTimeout time.Duration, timeouts Timeouts) *Server {
+	timeouts = timeouts.withDefaults()
+	s := &Server{shutdownTimeout: shutdownTimeout}
+	s.httpServer = &http.Server{
+		Addr:              addr,
+		Handler:           s.track(handler),
+		ReadHeaderTimeout: timeouts.ReadHeader,
+		ReadTimeout:       timeouts.Read,
+		WriteTimeout:      timeouts.Write,
+		IdleTimeout:       timeouts.Idle,
+	}
+	return s
+}
+
+// track counts requests while next serves them.
+func (s *Server) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.inFlight.Add(1)
+		s.inFlightCount.Add(1)
+		defer func() {
+			s.inFlightCount.Add(-1)
+			s.inFlight.Done()
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InFlight returns the number of requests currently being served.
+func (s *Server) InFlight() int64 {
+	return s.inFlightCount.Load()
+}
+
+// EnableTLS makes the server terminate TLS with the given PEM certificate
+// and key files. HTTP/2 is negotiated via ALPN for TLS connections.
+func (s *Server) EnableTLS(certFile, keyFile string) {
+	s.certFile = certFile
+	s.keyFile = keyFile
+}
+
+// TLSEnabled reports whether the server serves HTTPS.
+func (s *Server) TLSEnabled() bool {
+	return s.certFile != ""
+}
+
+// Timeouts returns the timeouts configured on the underlying http.Server.
+func (s *Server) Timeouts() Timeouts {
+	return Timeouts{
+		ReadHeader: s.httpServer.ReadHeaderTimeout,
+		Read:       s.httpServer.ReadTimeout,
+		Write:      s.httpServer.WriteTimeout,
+		Idle:       s.httpServer.IdleTimeout,
+	}
+}
+
+// Run listens on the configured address and serves until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", s.httpServer.Addr, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve accepts connections on listener until ctx is cancelled, then shuts
+// the server down gracefully. It returns the first serve or shutdown error.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if s.TLSEnabled() {
+			log.Printf("Listening on %s (TLS)", listener.Addr())
+			serveErr <- s.httpServer.ServeTLS(listener, s.certFile, s.keyFile)
+			return
+		}
+		log.Printf("Listening on %s", listener.Addr())
+		serveErr <- s.httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutdown signal received, draining %d in-flight requests (timeout %s)", s.InFlight(), s.shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	err := s.httpServer.Shutdown(shutdownCtx)
+	if err == nil {
+		err = s.waitInFlight(shutdownCtx)
+	}
+	if err != nil {
+		// Requests still running past the timeout are cut off.
+		log.Printf("Forcing close with %d requests in flight", s.InFlight())
+		s.httpServer.Close()
+		return fmt.Errorf("shutdown: %w", err)
+	}
+
+	log.Printf("Server stopped")
+	return nil
+}
+
+// waitInFlight blocks until every tracked request has finished or ctx is
+// done. http.Server.Shutdown does not wait for hijacked connections, so the
+// tracker covers requests it would miss.
+func (s *Server) waitInFlight(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
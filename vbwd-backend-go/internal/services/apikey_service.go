@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+)
+
+// APIKeyPrefix starts every API key, which makes keys easy to recognize
+// in configuration files and secret scanners.
+const APIKeyPrefix = "vbwd_"
+
+// apiKeyBytes is the number of random bytes in an API key.
+const apiKeyBytes = 32
+
+// TokenTypeAPIKey is the token_type of the claims an API key
+// authenticates with.
+const TokenTypeAPIKey = "api_key"
+
+// APIKeyService creates, verifies and revokes API keys.
+type APIKeyService interface {
+	// Create issues a new key acting as user and returns it in plaintext.
+	Create(ctx context.Context, user models.User, name string) (*models.APIKeyResponse, error)
+	// Verify returns the claims of the user a key belongs to, with the
+	// user's current role. Unknown and revoked keys, and keys of deleted
+	// users, return models.ErrInvalidToken.
+	Verify(ctx context.Context, key string) (*Claims, error)
+	// Revoke deletes a key of the user. Requests with the key are rejected
+	// from then on. It returns models.ErrAPIKeyNotFound when the user has
+	// no key with that ID.
+	Revoke(ctx context.Context, userID, id string) error
+	// RevokeAll deletes every key of the user and returns how many were
+	// deleted.
+	RevokeAll(ctx context.Context, userID string) (int, error)
+}
+
+type apiKeyService struct {
+	store repository.APIKeyStore
+	users repository.UserRepository
+	clock Clock
+}
+
+// NewAPIKeyService creates an APIKeyService over store that looks the
+// owner of each key up in users. A nil clock uses the system clock.
+func NewAPIKeyService(store repository.APIKeyStore, users repository.UserRepository, clock Clock) APIKeyService {
+	return &apiKeyService{store: store, users: users, clock: clockOrDefault(clock)}
+}
+
+// Create generates a random key and stores its hash.
+func (s *apiKeyService) Create(ctx context.Context, user models.User, name string) (*models.APIKeyResponse, error) {
+	raw := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	apiKey := models.APIKey{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		Name:      name,
+		Hash:      hashAPIKey(key),
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.store.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+	return &models.APIKeyResponse{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Key:       key,
+		CreatedAt: apiKey.CreatedAt.UTC(),
+	}, nil
+}
+
+// Verify looks the key up by its hash and then its owner, so that a key
+// acts with the role the user has now and stops working once the user is
+// deleted. The owner must still have the ID the key was created for; a
+// new account reusing the username does not inherit the key. The
+// returned claims carry the key ID in their jti and have no expiry, as
+// they are rebuilt on every request.
+func (s *apiKeyService) Verify(ctx context.Context, key string) (*Claims, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, models.ErrInvalidToken
+	}
+	apiKey, err := s.store.FindByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, models.ErrAPIKeyNotFound) {
+		return nil, models.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.FindByUsername(ctx, apiKey.Username)
+	if errors.Is(err, models.ErrUserNotFound) {
+		return nil, models.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.ID != apiKey.UserID {
+		return nil, models.ErrInvalidToken
+	}
+
+	return &Claims{
+		Username:  user.Username,
+		Role:      user.Role,
+		TokenType: TokenTypeAPIKey,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  apiKey.UserID,
+			ID:       apiKey.ID,
+			IssuedAt: jwt.NewNumericDate(apiKey.CreatedAt),
+		},
+	}, nil
+}
+
+// Revoke deletes the key.
+func (s *apiKeyService) Revoke(ctx context.Context, userID, id string) error {
+	return s.store.Delete(ctx, userID, id)
+}
+
+// RevokeAll deletes the keys of the user.
+func (s *apiKeyService) RevokeAll(ctx context.Context, userID string) (int, error) {
+	return s.store.DeleteByUser(ctx, userID)
+}
+
+// hashAPIKey returns the hex SHA-256 digest under which a key is stored.
+// Keys are random, so a fast unsalted hash is enough.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyVerifier is implemented by TokenServices that also accept API
+// keys. middleware.RequireAuth checks the X-API-Key header with it.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*Claims, error)
+}
+
+// apiKeyTokenService adds API key verification to a TokenService.
+type apiKeyTokenService struct {
+	TokenService
+	keys APIKeyService
+}
+
+// NewAPIKeyTokenService wraps tokenService so that it also implements
+// APIKeyVerifier with keys.
+func NewAPIKeyTokenService(tokenService TokenService, keys APIKeyService) TokenService {
+	return &apiKeyTokenService{TokenService: tokenService, keys: keys}
+}
+
+// VerifyAPIKey verifies the key with the APIKeyService.
+func (s *apiKeyTokenService) VerifyAPIKey(ctx context.Context, key string) (*Claims, error) {
+	return s.keys.Verify(ctx, key)
+}
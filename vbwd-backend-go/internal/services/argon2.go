@@ -0,0 +1,135 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2idPrefix starts every encoded Argon2id hash.
+const argon2idPrefix = "$argon2id$"
+
+// ErrInvalidArgon2Params is returned for unusable Argon2id parameters.
+var ErrInvalidArgon2Params = errors.New("argon2id parameters must be positive")
+
+// Argon2Params are the Argon2id cost parameters. They are encoded into every
+// hash so changing them does not invalidate existing hashes.
+type Argon2Params struct {
+	// Memory is the memory cost in KiB.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params returns the OWASP recommended minimum: 19 MiB of
+// memory, two iterations and one lane.
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{
+		Memory:      19 * 1024,
+		Iterations:  2,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// Argon2idHasher hashes passwords with Argon2id and encodes them in the
+// PHC string format: $argon2id$v=19$m=<memory>,t=<iterations>,p=<lanes>$<salt>$<key>.
+type Argon2idHasher struct {
+	params Argon2Params
+}
+
+// NewArgon2idHasher creates an Argon2idHasher with the given parameters.
+func NewArgon2idHasher(params Argon2Params) (*Argon2idHasher, error) {
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 || params.SaltLength == 0 || params.KeyLength == 0 {
+		return nil, ErrInvalidArgon2Params
+	}
+	return &Argon2idHasher{params: params}, nil
+}
+
+// Params returns the parameters used for new hashes.
+func (h *Argon2idHasher) Params() Argon2Params {
+	return h.params
+}
+
+// Hash returns the encoded Argon2id hash of a plaintext password with a
+// random salt.
+func (h *Argon2idHasher) Hash(plain string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(plain), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Compare reports whether plain matches hash. Legacy bcrypt hashes are
+// verified too.
+func (h *Argon2idHasher) Compare(hash, plain string) (bool, error) {
+	return comparePassword(hash, plain)
+}
+
+// NeedsRehash reports whether hash is not an Argon2id hash with the
+// configured parameters.
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	params, _, _, err := decodeArgon2id(hash)
+	return err != nil || params != h.params
+}
+
+// compareArgon2id recomputes the key with the parameters and salt encoded
+// in hash and compares it in constant time.
+func compareArgon2id(hash, plain string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(plain), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// decodeArgon2id parses an encoded Argon2id hash.
+func decodeArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2Params{}, nil, nil, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, fmt.Errorf("%w: argon2 version %q", ErrUnsupportedHash, parts[2])
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("%w: argon2 parameters %q", ErrUnsupportedHash, parts[3])
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("%w: argon2 salt", ErrUnsupportedHash)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("%w: argon2 key", ErrUnsupportedHash)
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 || len(salt) == 0 || len(key) == 0 {
+		return Argon2Params{}, nil, nil, fmt.Errorf("%w: argon2 parameters %q", ErrUnsupportedHash, parts[3])
+	}
+	return params, salt, key, nil
+}
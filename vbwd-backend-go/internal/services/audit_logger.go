@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+)
+
+// AuditLogger records security-relevant events for later review. Entries
+// must never contain passwords or tokens.
+type AuditLogger interface {
+	// RecordLogin records a login attempt for username from ip and whether
+	// it succeeded.
+	RecordLogin(ctx context.Context, username string, success bool, ip string)
+}
+
+// nopAuditLogger drops every entry.
+type nopAuditLogger struct{}
+
+// NopAuditLogger returns an AuditLogger that discards entries.
+func NopAuditLogger() AuditLogger {
+	return nopAuditLogger{}
+}
+
+func (nopAuditLogger) RecordLogin(ctx context.Context, username string, success bool, ip string) {}
+
+// logAuditLogger writes entries to a logger.
+type logAuditLogger struct {
+	logger *slog.Logger
+}
+
+// NewLogAuditLogger returns an AuditLogger that writes each entry as a
+// structured "audit" record to logger. A nil logger uses slog.Default().
+func NewLogAuditLogger(logger *slog.Logger) AuditLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &logAuditLogger{logger: logger}
+}
+
+func (a *logAuditLogger) RecordLogin(ctx context.Context, username string, success bool, ip string) {
+	a.logger.InfoContext(ctx, "audit",
+		slog.String("event", "login"),
+		slog.String("username", username),
+		slog.Bool("success", success),
+		slog.String("ip", ip),
+	)
+}
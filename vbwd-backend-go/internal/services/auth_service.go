@@ -0,0 +1,610 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+)
+
+// AuthService defines the authentication use cases. The context is passed
+// through to the repository so request cancellation and deadlines apply.
+type AuthService interface {
+	Authenticate(ctx context.Context, username, password string) (*models.LoginResponse, error)
+	// AuthenticateExternal logs in the local user an external identity
+	// is linked to. Users with MFA enabled get a challenge, as with a
+	// password login.
+	AuthenticateExternal(ctx context.Context, identity models.ExternalIdentity) (*models.LoginResponse, error)
+	// LinkToken returns a short-lived token with which the user, given by
+	// ID and username, can link an external identity through LinkExternal.
+	LinkToken(ctx context.Context, userID, username string) (string, error)
+	// LinkExternal links the identity to the user of the link token.
+	LinkExternal(ctx context.Context, linkToken string, identity models.ExternalIdentity) error
+	// AuthenticateMFA completes a login that returned an MFA challenge
+	// token with a code of the user's authenticator.
+	AuthenticateMFA(ctx context.Context, mfaToken, code string) (*models.LoginResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.LoginResponse, error)
+	Register(ctx context.Context, username, email, password string) (*models.User, error)
+	// ChangePassword replaces the password of the user after checking the
+	// old one. Sessions other than sessionID, the session of the caller,
+	// and all API keys of the user are revoked.
+	ChangePassword(ctx context.Context, username, sessionID, oldPassword, newPassword string) error
+}
+
+// DefaultLinkTokenTTL is how long a link token can be used to link an
+// external identity.
+const DefaultLinkTokenTTL = 10 * time.Minute
+
+// demoPasswordHash is the bcrypt hash of the demo user's password ("password").
+const demoPasswordHash = "$2a$10$IO7BKADLhh9w3lXPsbTi9.A.uES8PXa3GciXZIuj0H0kF.1mouZ.a"
+
+// DemoUser returns the built-in demo account (admin/password).
+func DemoUser() models.User {
+	return models.User{
+		ID:       "1",
+		Username: "admin",
+		Password: demoPasswordHash,
+		Role:     models.RoleAdmin,
+	}
+}
+
+// authService implements AuthService on top of a UserRepository.
+type authService struct {
+	users        repository.UserRepository
+	tokenService TokenService
+	throttler    LoginThrottler
+	policy       PasswordPolicy
+	hasher       Hasher
+	logger       *slog.Logger
+	sessions     SessionService
+	keys         APIKeyService
+	mfa          MFAService
+	links        repository.IdentityLinkStore
+	// accessOnly skips refresh tokens for logins that choose no grant.
+	accessOnly bool
+	// onLoginSuccess runs after the tokens of a login are issued.
+	onLoginSuccess LoginHook
+
+	// dummyHash is compared against when a username does not exist so
+	// that unknown users cost the same hashing work as wrong passwords.
+	// Without it the response time would reveal which usernames are
+	// registered. It is produced lazily by the configured hasher so its
+	// cost matches real hashes.
+	dummyHash func() string
+}
+
+// AuthOption configures an AuthService created by NewAuthService.
+type AuthOption func(*authService)
+
+// WithRepository stores users in repo.
+func WithRepository(repo repository.UserRepository) AuthOption {
+	return func(s *authService) { s.users = repo }
+}
+
+// WithTokenService issues tokens through tokenService.
+func WithTokenService(tokenService TokenService) AuthOption {
+	return func(s *authService) { s.tokenService = tokenService }
+}
+
+// WithThrottler locks out usernames via throttler.
+func WithThrottler(throttler LoginThrottler) AuthOption {
+	return func(s *authService) { s.throttler = throttler }
+}
+
+// WithPasswordPolicy enforces policy on new passwords.
+func WithPasswordPolicy(policy PasswordPolicy) AuthOption {
+	return func(s *authService) { s.policy = policy }
+}
+
+// WithHasher hashes new passwords with hasher.
+func WithHasher(hasher Hasher) AuthOption {
+	return func(s *authService) { s.hasher = hasher }
+}
+
+// WithLogger logs failures to logger.
+func WithLogger(logger *slog.Logger) AuthOption {
+	return func(s *authService) { s.logger = logger }
+}
+
+// WithSessions starts a login session for every successful login and binds
+// the issued tokens to it.
+func WithSessions(sessions SessionService) AuthOption {
+	return func(s *authService) { s.sessions = sessions }
+}
+
+// WithAPIKeys revokes the API keys of users who change their password
+// through keys.
+func WithAPIKeys(keys APIKeyService) AuthOption {
+	return func(s *authService) { s.keys = keys }
+}
+
+// WithMFA asks users who enabled MFA in mfa for a code after their
+// password: Authenticate then returns a challenge token instead of tokens.
+func WithMFA(mfa MFAService) AuthOption {
+	return func(s *authService) { s.mfa = mfa }
+}
+
+// WithIdentityLinks keeps the links of external identities to users in
+// store.
+func WithIdentityLinks(store repository.IdentityLinkStore) AuthOption {
+	return func(s *authService) { s.links = store }
+}
+
+// WithRefreshTokens sets whether logins that choose no grant with
+// WithGrant get a refresh token. They do unless issue is false.
+func WithRefreshTokens(issue bool) AuthOption {
+	return func(s *authService) { s.accessOnly = !issue }
+}
+
+// LoginHook runs custom logic after a successful login, such as recording
+// the last login or emitting an event. The user carries no password hash.
+type LoginHook func(ctx context.Context, user models.User) error
+
+// WithOnLoginSuccess calls hook after the tokens of every successful
+// login, including MFA and external logins, are issued. The login succeeds
+// regardless of the hook: errors it returns and panics are logged.
+func WithOnLoginSuccess(hook LoginHook) AuthOption {
+	return func(s *authService) { s.onLoginSuccess = hook }
+}
+
+type grantContextKey struct{}
+
+// WithGrant returns a context selecting the tokens a login issues:
+// models.GrantAccessOnly suppresses the refresh token and
+// models.GrantAccessRefresh issues both. An empty grant selects the
+// default set with WithRefreshTokens.
+func WithGrant(ctx context.Context, grant string) context.Context {
+	return context.WithValue(ctx, grantContextKey{}, grant)
+}
+
+// GrantFromContext returns the grant stored by WithGrant, or "".
+func GrantFromContext(ctx context.Context) string {
+	grant, _ := ctx.Value(grantContextKey{}).(string)
+	return grant
+}
+
+// NewAuthService creates an AuthService configured by opts. Omitted or nil
+// dependencies get defaults: an empty in-memory repository, an HMAC token
+// service with a random per-process secret, a LoginThrottler with the
+// default lockout policy, DefaultPasswordPolicy(), DefaultBcryptHasher(),
+// an in-memory IdentityLinkStore and slog.Default(). Sessions are only
+// tracked with WithSessions, API keys are only revoked on password changes
+// with WithAPIKeys and second factors are only checked with WithMFA.
+// Logins issue refresh tokens unless disabled with WithRefreshTokens or
+// WithGrant.
+func NewAuthService(opts ...AuthOption) AuthService {
+	s := newAuthService(opts)
+	hasher := s.hasher
+	s.dummyHash = sync.OnceValue(func() string {
+		hash, _ := hasher.Hash("vbwd-dummy-password-for-timing")
+		return hash
+	})
+	return s
+}
+
+// newAuthService applies opts and fills in defaults.
+func newAuthService(opts []AuthOption) *authService {
+	s := &authService{policy: DefaultPasswordPolicy()}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.users == nil {
+		s.users = repository.NewInMemoryUserRepository()
+	}
+	if s.tokenService == nil {
+		s.tokenService = NewTokenService(randomSecret(), TokenOptions{})
+	}
+	if s.throttler == nil {
+		s.throttler = NewLoginThrottler(DefaultMaxFailedAttempts, DefaultLockoutWindow, nil)
+	}
+	if s.hasher == nil {
+		s.hasher = DefaultBcryptHasher()
+	}
+	if s.links == nil {
+		s.links = repository.NewInMemoryIdentityLinkStore()
+	}
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+	return s
+}
+
+// randomSecret returns a secret for the default token service. Tokens
+// signed with it do not survive a restart.
+func randomSecret() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("read random secret: %v", err))
+	}
+	return hex.EncodeToString(secret)
+}
+
+// Authenticate validates the credentials and returns a login response.
+// The username may also be the account's email address.
+// Unknown usernames and wrong passwords are indistinguishable: both return
+// models.ErrInvalidCredentials after a hash comparison. After a successful
+// check a hash with outdated settings is upgraded to the current ones.
+// Users with MFA enabled get a challenge token for AuthenticateMFA instead
+// of tokens.
+func (s *authService) Authenticate(ctx context.Context, username, password string) (*models.LoginResponse, error) {
+	if s.throttler.IsLocked(username) {
+		s.logger.WarnContext(ctx, "login rejected", slog.String("username", username), slog.String("reason", "account locked"))
+		return nil, models.ErrAccountLocked
+	}
+
+	user, err := s.findByLogin(ctx, username)
+	if errors.Is(err, models.ErrUserNotFound) {
+		// Spend the same time as a real comparison to avoid user enumeration.
+		_, _ = s.hasher.Compare(s.dummyHash(), password)
+		s.throttler.RecordFailure(username)
+		s.logger.WarnContext(ctx, "login failed", slog.String("username", username), slog.String("reason", "unknown user"))
+		return nil, models.ErrInvalidCredentials
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "login failed", slog.String("username", username), slog.Any("error", err))
+		return nil, err
+	}
+
+	ok, err := s.hasher.Compare(user.Password, password)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "login failed", slog.String("username", username), slog.Any("error", err))
+		return nil, err
+	}
+	if !ok {
+		s.throttler.RecordFailure(username)
+		s.logger.WarnContext(ctx, "login failed", slog.String("username", username), slog.String("reason", "wrong password"))
+		return nil, models.ErrInvalidCredentials
+	}
+	s.throttler.Reset(username)
+	s.rehashIfNeeded(ctx, user, password)
+
+	return s.completeFirstFactor(ctx, user)
+}
+
+// completeFirstFactor finishes a login whose first factor succeeded. Users
+// with MFA enabled get a challenge for AuthenticateMFA instead of tokens.
+func (s *authService) completeFirstFactor(ctx context.Context, user *models.User) (*models.LoginResponse, error) {
+	if s.mfa != nil {
+		enabled, err := s.mfa.Enabled(ctx, user.ID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "login failed", slog.String("username", user.Username), slog.Any("error", err))
+			return nil, err
+		}
+		if enabled {
+			return s.issueMFAChallenge(ctx, user)
+		}
+	}
+	return s.issueTokens(ctx, user)
+}
+
+// issueMFAChallenge returns a login response carrying only a challenge
+// token that AuthenticateMFA exchanges for tokens.
+func (s *authService) issueMFAChallenge(ctx context.Context, user *models.User) (*models.LoginResponse, error) {
+	token, err := s.tokenService.Generate(*user, asMFAChallenge(DefaultMFAChallengeTTL))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "issue mfa challenge", slog.String("username", user.Username), slog.Any("error", err))
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		Success:     true,
+		Message:     "MFA code required",
+		MFARequired: true,
+		MFAToken:    token,
+	}, nil
+}
+
+// AuthenticateMFA checks the challenge token and the code. Wrong codes
+// count as failed logins of the username, so guessing codes locks the
+// account like guessing passwords. Invalid or expired challenge tokens
+// return models.ErrInvalidToken and wrong codes models.ErrInvalidMFACode.
+func (s *authService) AuthenticateMFA(ctx context.Context, mfaToken, code string) (*models.LoginResponse, error) {
+	claims, err := s.tokenService.Parse(mfaToken)
+	if err != nil || claims.TokenType != TokenTypeMFA || s.mfa == nil {
+		return nil, models.ErrInvalidToken
+	}
+	if s.throttler.IsLocked(claims.Username) {
+		s.logger.WarnContext(ctx, "mfa login rejected", slog.String("username", claims.Username), slog.String("reason", "account locked"))
+		return nil, models.ErrAccountLocked
+	}
+
+	user, err := s.users.FindByUsername(ctx, claims.Username)
+	if errors.Is(err, models.ErrUserNotFound) {
+		return nil, models.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.ID != claims.Subject {
+		return nil, models.ErrInvalidToken
+	}
+
+	err = s.mfa.Validate(ctx, user.ID, code)
+	if errors.Is(err, models.ErrInvalidMFACode) {
+		s.throttler.RecordFailure(user.Username)
+		s.logger.WarnContext(ctx, "mfa login failed", slog.String("username", user.Username), slog.String("reason", "wrong code"))
+		return nil, err
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "mfa login failed", slog.String("username", user.Username), slog.Any("error", err))
+		return nil, err
+	}
+	s.throttler.Reset(user.Username)
+
+	return s.issueTokens(ctx, user)
+}
+
+// AuthenticateExternal logs in the user the identity was linked to with
+// LinkExternal. The identity's email is not used: a local account with the
+// same email may have been registered by someone else, since emails are
+// not verified. Unlinked identities get models.ErrIdentityNotLinked, and
+// accounts are never created from external identities. The provider only
+// stands in for the password, so MFA still applies.
+func (s *authService) AuthenticateExternal(ctx context.Context, identity models.ExternalIdentity) (*models.LoginResponse, error) {
+	logAttrs := []any{slog.String("provider", identity.Provider), slog.String("subject", identity.Subject)}
+	link, err := s.links.Find(ctx, identity.Provider, identity.Subject)
+	if errors.Is(err, models.ErrIdentityNotLinked) {
+		s.logger.WarnContext(ctx, "external login failed", append(logAttrs, slog.String("reason", "not linked"))...)
+		return nil, err
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "external login failed", append(logAttrs, slog.Any("error", err))...)
+		return nil, err
+	}
+
+	user, err := s.users.FindByUsername(ctx, link.Username)
+	if errors.Is(err, models.ErrUserNotFound) || (err == nil && user.ID != link.UserID) {
+		s.logger.WarnContext(ctx, "external login failed", append(logAttrs, slog.String("reason", "linked user deleted"))...)
+		return nil, models.ErrIdentityNotLinked
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "external login failed", append(logAttrs, slog.Any("error", err))...)
+		return nil, err
+	}
+
+	return s.completeFirstFactor(ctx, user)
+}
+
+// LinkToken issues a link token for the user, who must still exist.
+func (s *authService) LinkToken(ctx context.Context, userID, username string) (string, error) {
+	user, err := s.users.FindByUsername(ctx, username)
+	if errors.Is(err, models.ErrUserNotFound) || (err == nil && user.ID != userID) {
+		return "", models.ErrInvalidToken
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.tokenService.Generate(*user, asLinkIntent(DefaultLinkTokenTTL))
+}
+
+// LinkExternal checks the link token and stores the link. Invalid or
+// expired link tokens return models.ErrInvalidToken, and identities
+// already linked to another user models.ErrIdentityLinked.
+func (s *authService) LinkExternal(ctx context.Context, linkToken string, identity models.ExternalIdentity) error {
+	claims, err := s.tokenService.Parse(linkToken)
+	if err != nil || claims.TokenType != TokenTypeLink {
+		return models.ErrInvalidToken
+	}
+
+	user, err := s.users.FindByUsername(ctx, claims.Username)
+	if errors.Is(err, models.ErrUserNotFound) || (err == nil && user.ID != claims.Subject) {
+		return models.ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.links.Link(ctx, models.IdentityLink{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		UserID:   user.ID,
+		Username: user.Username,
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "link external identity failed", slog.String("username", user.Username), slog.String("provider", identity.Provider), slog.Any("error", err))
+		return err
+	}
+	s.logger.InfoContext(ctx, "external identity linked", slog.String("username", user.Username), slog.String("provider", identity.Provider))
+	return nil
+}
+
+// issueTokens starts a session for the authenticated user, when sessions
+// are tracked, and returns a login response with an access and, unless
+// the grant of ctx leaves it out, a refresh token bound to it.
+func (s *authService) issueTokens(ctx context.Context, user *models.User) (*models.LoginResponse, error) {
+	var claimOpts []ClaimOption
+	if s.sessions != nil {
+		session, err := s.sessions.Start(ctx, user.ID, DeviceFromContext(ctx))
+		if err != nil {
+			s.logger.ErrorContext(ctx, "start session", slog.String("username", user.Username), slog.Any("error", err))
+			return nil, err
+		}
+		claimOpts = append(claimOpts, WithSessionID(session.ID))
+	}
+
+	token, err := s.tokenService.Generate(*user, claimOpts...)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "issue access token", slog.String("username", user.Username), slog.Any("error", err))
+		return nil, err
+	}
+
+	resp := &models.LoginResponse{
+		Success: true,
+		Message: "Login successful",
+		Token:   token,
+	}
+	if s.issuesRefresh(ctx) {
+		if resp.RefreshToken, err = s.tokenService.GenerateRefresh(*user, claimOpts...); err != nil {
+			s.logger.ErrorContext(ctx, "issue refresh token", slog.String("username", user.Username), slog.Any("error", err))
+			return nil, err
+		}
+	}
+
+	s.runLoginHook(ctx, *user)
+	return resp, nil
+}
+
+// runLoginHook calls the OnLoginSuccess hook, if any. The login has
+// succeeded by then, so a failing or panicking hook is only logged.
+func (s *authService) runLoginHook(ctx context.Context, user models.User) {
+	if s.onLoginSuccess == nil {
+		return
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			s.logger.ErrorContext(ctx, "login hook panicked", slog.String("username", user.Username), slog.Any("panic", p))
+		}
+	}()
+
+	user.Password = ""
+	if err := s.onLoginSuccess(ctx, user); err != nil {
+		s.logger.WarnContext(ctx, "login hook failed", slog.String("username", user.Username), slog.Any("error", err))
+	}
+}
+
+// issuesRefresh reports whether the login of ctx gets a refresh token.
+func (s *authService) issuesRefresh(ctx context.Context) bool {
+	switch GrantFromContext(ctx) {
+	case models.GrantAccessOnly:
+		return false
+	case models.GrantAccessRefresh:
+		return true
+	}
+	return !s.accessOnly
+}
+
+// rehashIfNeeded replaces a stored hash produced with outdated settings by
+// a hash with the current ones. The login succeeds even when the upgrade
+// fails; it is retried on the next login.
+func (s *authService) rehashIfNeeded(ctx context.Context, user *models.User, password string) {
+	rehasher, ok := s.hasher.(Rehasher)
+	if !ok || !rehasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err == nil {
+		err = s.users.UpdatePassword(ctx, user.ID, hash)
+	}
+	if err != nil {
+		s.logger.WarnContext(ctx, "password rehash failed", slog.String("username", user.Username), slog.Any("error", err))
+		return
+	}
+	s.logger.InfoContext(ctx, "password rehashed", slog.String("username", user.Username))
+}
+
+// Refresh exchanges a valid refresh token for a new access token bound to
+// the same session.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*models.LoginResponse, error) {
+	claims, err := s.tokenService.Parse(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, models.ErrInvalidToken
+	}
+
+	user, err := s.users.FindByUsername(ctx, claims.Username)
+	if errors.Is(err, models.ErrUserNotFound) {
+		return nil, models.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.ID != claims.Subject {
+		return nil, models.ErrInvalidToken
+	}
+
+	token, err := s.tokenService.Generate(*user, WithSessionID(claims.SessionID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		Success: true,
+		Message: "Token refreshed",
+		Token:   token,
+	}, nil
+}
+
+// findByLogin looks a user up by username and, when none matches and the
+// login looks like an email address, by email.
+func (s *authService) findByLogin(ctx context.Context, login string) (*models.User, error) {
+	user, err := s.users.FindByUsername(ctx, login)
+	if errors.Is(err, models.ErrUserNotFound) && strings.Contains(login, "@") {
+		return s.users.FindByEmail(ctx, models.NormalizeEmail(login))
+	}
+	return user, err
+}
+
+// Register creates a new user with a hashed password after checking it
+// against the password policy. The optional email is stored normalized.
+func (s *authService) Register(ctx context.Context, username, email, password string) (*models.User, error) {
+	if err := s.policy.Validate(password); err != nil {
+		return nil, err
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		ID:       uuid.NewString(),
+		Username: username,
+		Email:    models.NormalizeEmail(email),
+		Password: hash,
+		Role:     models.RoleUser,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ChangePassword verifies the current password and replaces it with a new
+// one that satisfies the password policy, then revokes the credentials an
+// attacker who knew the old password may have obtained.
+func (s *authService) ChangePassword(ctx context.Context, username, sessionID, oldPassword, newPassword string) error {
+	user, err := s.users.FindByUsername(ctx, username)
+	if errors.Is(err, models.ErrUserNotFound) {
+		return models.ErrInvalidCredentials
+	}
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.hasher.Compare(user.Password, oldPassword)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return models.ErrInvalidCredentials
+	}
+
+	if err := s.policy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	hash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	return revokeCredentials(ctx, s.sessions, s.keys, user.ID, sessionID)
+}
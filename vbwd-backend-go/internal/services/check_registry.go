@@ -0,0 +1,98 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// CheckRegistry holds the named readiness checks of a HealthService.
+// Subsystems may add and remove checks at any time, also after startup;
+// the next readiness run uses the checks registered at its start. It is
+// safe for concurrent use.
+type CheckRegistry struct {
+	mu     sync.RWMutex
+	checks []namedCheck
+	// generation counts changes so cached readiness results of an older
+	// set of checks are not reused.
+	generation uint64
+}
+
+// NewCheckRegistry creates an empty CheckRegistry.
+func NewCheckRegistry() *CheckRegistry {
+	return &CheckRegistry{}
+}
+
+// Register adds a check limited to DefaultCheckTimeout.
+func (r *CheckRegistry) Register(name string, check Checker) {
+	r.RegisterWithTimeout(name, check, DefaultCheckTimeout)
+}
+
+// RegisterWithTimeout adds a check that fails with reason "timeout" when it
+// runs longer than timeout. A non-positive timeout selects
+// DefaultCheckTimeout. A check registered under a name already in use
+// replaces the earlier one and keeps its position.
+func (r *CheckRegistry) RegisterWithTimeout(name string, check Checker, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	entry := namedCheck{name: name, check: check, timeout: timeout}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	for i := range r.checks {
+		if r.checks[i].name == name {
+			r.checks[i] = entry
+			return
+		}
+	}
+	r.checks = append(r.checks, entry)
+}
+
+// Deregister removes the check with the given name and reports whether it
+// was registered.
+func (r *CheckRegistry) Deregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.checks {
+		if r.checks[i].name == name {
+			r.checks = append(r.checks[:i:i], r.checks[i+1:]...)
+			r.generation++
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the names of the registered checks in registration order.
+func (r *CheckRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.checks))
+	for i, c := range r.checks {
+		names[i] = c.name
+	}
+	return names
+}
+
+// snapshot returns a copy of the registered checks and the generation they
+// belong to.
+func (r *CheckRegistry) snapshot() ([]namedCheck, uint64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	checks := make([]namedCheck, len(r.checks))
+	copy(checks, r.checks)
+	return checks, r.generation
+}
+
+// currentGeneration returns the number of changes made so far.
+func (r *CheckRegistry) currentGeneration() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.generation
+}
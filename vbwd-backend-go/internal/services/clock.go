@@ -0,0 +1,29 @@
+package services
+
+import "time"
+
+// Clock provides the current time so time-dependent behavior can be
+// controlled in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock reads the system clock.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// RealClock returns a Clock backed by time.Now.
+func RealClock() Clock {
+	return realClock{}
+}
+
+// clockOrDefault returns clock, or the real clock when clock is nil.
+func clockOrDefault(clock Clock) Clock {
+	if clock == nil {
+		return realClock{}
+	}
+	return clock
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultDBCheckTimeout bounds how long a database ping may take.
+const DefaultDBCheckTimeout = 2 * time.Second
+
+// Pinger is satisfied by *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// DBChecker returns a readiness Checker that pings the database with the
+// default timeout.
+func DBChecker(db Pinger) Checker {
+	return NewDBChecker(db, DefaultDBCheckTimeout)
+}
+
+// NewDBChecker returns a readiness Checker that pings the database, failing
+// once timeout elapses or the caller's deadline is reached, whichever is
+// first. Errors include the observed latency.
+func NewDBChecker(db Pinger, timeout time.Duration) Checker {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		start := time.Now()
+		err := db.PingContext(ctx)
+		if err != nil {
+			return fmt.Errorf("database ping failed after %s: %w", time.Since(start).Round(time.Millisecond), err)
+		}
+		return nil
+	}
+}
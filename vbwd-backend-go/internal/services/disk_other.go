@@ -0,0 +1,10 @@
+//go:build !unix
+
+package services
+
+import "errors"
+
+// diskFree is not implemented on this platform.
+func diskFree(path string) (uint64, error) {
+	return 0, errors.New("free disk space is not supported on this platform")
+}
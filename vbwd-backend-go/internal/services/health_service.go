@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// Readiness check time limits.
+const (
+	// DefaultCheckTimeout bounds a single check registered with
+	// RegisterCheck.
+	DefaultCheckTimeout = 2 * time.Second
+	// DefaultReadinessTimeout bounds a whole readiness run.
+	DefaultReadinessTimeout = 5 * time.Second
+)
+
+// Checker probes a dependency and returns an error when it is unavailable.
+type Checker func(ctx context.Context) error
+
+// HealthService defines the health check use cases.
+type HealthService interface {
+	GetHealthStatus(ctx context.Context) *models.HealthResponse
+	GetReadiness(ctx context.Context) *models.ReadinessResponse
+	RegisterCheck(name string, check Checker)
+	RegisterCheckWithTimeout(name string, check Checker, timeout time.Duration)
+	// Checks returns the registry the readiness checks are read from, to
+	// be shared with subsystems that add or remove checks at runtime.
+	Checks() *CheckRegistry
+	SetReadinessTimeout(timeout time.Duration)
+	// SetReadinessCacheTTL makes GetReadiness reuse its last result for
+	// ttl instead of re-running the checks. Zero disables the cache.
+	SetReadinessCacheTTL(ttl time.Duration)
+	// SetHistorySize sets how many readiness evaluations History keeps and
+	// clears the history.
+	SetHistorySize(size int)
+	// History returns the latest readiness evaluations, oldest first.
+	// Results served from the readiness cache are not evaluations.
+	History() *models.ReadinessHistoryResponse
+	// SetMaintenance switches maintenance mode, in which /health reports
+	// status "maintenance" and the service is not ready. It is safe for
+	// concurrent use.
+	SetMaintenance(enabled bool)
+	Maintenance() bool
+}
+
+type namedCheck struct {
+	name    string
+	check   Checker
+	timeout time.Duration
+}
+
+type healthService struct {
+	serviceName string
+	version     string
+	startTime   time.Time
+	clock       Clock
+
+	checks *CheckRegistry
+
+	mu               sync.RWMutex
+	readinessTimeout time.Duration
+
+	maintenance atomic.Bool
+
+	history atomic.Pointer[readinessHistory]
+
+	// cacheMu guards the readiness cache and serializes refreshes, so
+	// concurrent probes of an expired cache run the checks only once.
+	cacheMu         sync.Mutex
+	cacheTTL        time.Duration
+	cached          *models.ReadinessResponse
+	cacheExpiry     time.Time
+	cacheGeneration uint64
+}
+
+// NewHealthService creates a HealthService reporting under the given name
+// and build version. Uptime is measured from startTime; a nil clock uses
+// the system clock.
+func NewHealthService(serviceName, version string, startTime time.Time, clock Clock) HealthService {
+	s := &healthService{
+		serviceName: serviceName,
+		version:     version,
+		startTime:   startTime,
+		clock:       clockOrDefault(clock),
+		checks:      NewCheckRegistry(),
+
+		readinessTimeout: DefaultReadinessTimeout,
+	}
+	s.history.Store(newReadinessHistory(DefaultReadinessHistorySize))
+	return s
+}
+
+// GetHealthStatus returns the current health status of the service.
+func (s *healthService) GetHealthStatus(ctx context.Context) *models.HealthResponse {
+	now := s.clock.Now()
+	status := models.StatusHealthy
+	if s.maintenance.Load() {
+		status = models.StatusMaintenance
+	}
+	return &models.HealthResponse{
+		Status:        status,
+		Timestamp:     now.UTC(),
+		Service:       s.serviceName,
+		Version:       s.version,
+		UptimeSeconds: int64(now.Sub(s.startTime) / time.Second),
+	}
+}
+
+// SetMaintenance switches maintenance mode on or off. The readiness cache
+// is cleared so leaving maintenance re-runs the checks.
+func (s *healthService) SetMaintenance(enabled bool) {
+	s.maintenance.Store(enabled)
+	s.invalidateReadiness()
+}
+
+// Maintenance reports whether maintenance mode is on.
+func (s *healthService) Maintenance() bool {
+	return s.maintenance.Load()
+}
+
+// RegisterCheck adds a readiness check limited to DefaultCheckTimeout. All
+// registered checks must pass for the service to report ready.
+func (s *healthService) RegisterCheck(name string, check Checker) {
+	s.checks.Register(name, check)
+}
+
+// RegisterCheckWithTimeout adds a readiness check that fails with reason
+// "timeout" when it runs longer than timeout. A non-positive timeout
+// selects DefaultCheckTimeout.
+func (s *healthService) RegisterCheckWithTimeout(name string, check Checker, timeout time.Duration) {
+	s.checks.RegisterWithTimeout(name, check, timeout)
+}
+
+// Checks returns the registry of readiness checks. Changes to it take
+// effect on the next readiness run, bypassing a cached result.
+func (s *healthService) Checks() *CheckRegistry {
+	return s.checks
+}
+
+// SetReadinessTimeout sets the deadline for a whole readiness run. Checks
+// still running when it passes are reported as timed out. A non-positive
+// timeout selects DefaultReadinessTimeout.
+func (s *healthService) SetReadinessTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultReadinessTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readinessTimeout = timeout
+}
+
+// SetReadinessCacheTTL sets how long a readiness result is reused. A
+// non-positive ttl disables the cache.
+func (s *healthService) SetReadinessCacheTTL(ttl time.Duration) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	s.cacheTTL = max(ttl, 0)
+	s.cached = nil
+}
+
+// SetHistorySize replaces the history with an empty one of size entries.
+// A non-positive size selects DefaultReadinessHistorySize.
+func (s *healthService) SetHistorySize(size int) {
+	if size <= 0 {
+		size = DefaultReadinessHistorySize
+	}
+	s.history.Store(newReadinessHistory(size))
+}
+
+// History returns the recorded readiness evaluations.
+func (s *healthService) History() *models.ReadinessHistoryResponse {
+	events, capacity := s.history.Load().list()
+	return &models.ReadinessHistoryResponse{Capacity: capacity, Events: events}
+}
+
+// invalidateReadiness drops the cached readiness result.
+func (s *healthService) invalidateReadiness() {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	s.cached = nil
+}
+
+// GetReadiness runs every registered check concurrently, each with its own
+// timeout and all within the readiness deadline, and aggregates the results
+// in registration order. With a cache TTL the result is reused until it
+// expires. In maintenance mode it reports not ready without running the
+// checks or consulting the cache. Every evaluation, but no cached result,
+// is recorded in the history.
+func (s *healthService) GetReadiness(ctx context.Context) *models.ReadinessResponse {
+	if s.maintenance.Load() {
+		resp := &models.ReadinessResponse{
+			Ready:       false,
+			Maintenance: true,
+			Timestamp:   s.clock.Now().UTC(),
+			Checks:      []models.CheckResult{},
+		}
+		s.history.Load().record(readinessEvent(resp))
+		return resp
+	}
+
+	s.cacheMu.Lock()
+	if s.cacheTTL == 0 {
+		s.cacheMu.Unlock()
+		resp, _ := s.runChecks(ctx)
+		return resp
+	}
+	defer s.cacheMu.Unlock()
+
+	now := s.clock.Now()
+	if s.cached == nil || !now.Before(s.cacheExpiry) || s.cacheGeneration != s.checks.currentGeneration() {
+		s.cached, s.cacheGeneration = s.runChecks(ctx)
+		s.cacheExpiry = now.Add(s.cacheTTL)
+	}
+	resp := *s.cached
+	return &resp
+}
+
+// runChecks runs the registered checks and aggregates their results. It
+// also returns the registry generation the checks were taken from.
+func (s *healthService) runChecks(ctx context.Context) (*models.ReadinessResponse, uint64) {
+	checks, generation := s.checks.snapshot()
+	s.mu.RLock()
+	timeout := s.readinessTimeout
+	s.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]models.CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			results[i] = s.runCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	resp := &models.ReadinessResponse{
+		Ready:     true,
+		Timestamp: s.clock.Now().UTC(),
+		Checks:    results,
+	}
+	for _, result := range results {
+		if !result.Healthy {
+			resp.Ready = false
+		}
+	}
+	s.history.Load().record(readinessEvent(resp))
+	return resp, generation
+}
+
+// runCheck runs c with its timeout and times it with the service clock. A
+// check that ignores its context is abandoned once the timeout passes so it
+// cannot stall the readiness run.
+func (s *healthService) runCheck(ctx context.Context, c namedCheck) models.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := s.clock.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := models.CheckResult{
+		Name:       c.name,
+		Healthy:    err == nil,
+		DurationMS: max(float64(s.clock.Now().Sub(start))/float64(time.Millisecond), 0),
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Reason = models.CheckReasonTimeout
+		result.Error = "check timed out"
+	default:
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// IdentityProvider is an external login provider using the OAuth2
+// authorization code flow.
+type IdentityProvider interface {
+	// Name identifies the provider in URLs, e.g. "google".
+	Name() string
+	// AuthCodeURL returns the provider URL the user is sent to, carrying
+	// state to be checked on the callback.
+	AuthCodeURL(state string) string
+	// Exchange redeems the authorization code from the callback and
+	// returns the identity of the user who logged in.
+	Exchange(ctx context.Context, code string) (*models.ExternalIdentity, error)
+}
+
+// ErrInvalidDiscovery is returned when an OpenID Connect discovery document
+// lacks a required endpoint.
+var ErrInvalidDiscovery = errors.New("discovery document lacks authorization, token or userinfo endpoint")
+
+// defaultOIDCScopes are requested when OIDCConfig.Scopes is empty.
+var defaultOIDCScopes = []string{"openid", "email", "profile"}
+
+// OIDCConfig configures NewOIDCProvider.
+type OIDCConfig struct {
+	// Name identifies the provider in URLs.
+	Name string
+	// DiscoveryURL is the provider's OpenID Connect discovery document,
+	// usually the issuer followed by /.well-known/openid-configuration.
+	DiscoveryURL string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the public URL of GET /auth/{provider}/callback.
+	RedirectURL string
+	// Scopes defaults to openid, email and profile.
+	Scopes []string
+	// HTTPClient makes the requests to the provider; nil uses
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// oidcDiscovery holds the endpoints read from a discovery document.
+type oidcDiscovery struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// oidcUserInfo is the part of a UserInfo response that identifies the
+// user. Some providers send email_verified as a string.
+type oidcUserInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// oidcProvider is an IdentityProvider for any OpenID Connect provider.
+type oidcProvider struct {
+	name        string
+	oauth       oauth2.Config
+	userInfoURL string
+	client      *http.Client
+}
+
+// NewOIDCProvider creates an IdentityProvider for an OpenID Connect
+// provider, reading its endpoints from the discovery document. The user is
+// identified through the UserInfo endpoint with the issued access token.
+func NewOIDCProvider(ctx context.Context, cfg OIDCConfig) (IdentityProvider, error) {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultOIDCScopes
+	}
+
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, client, cfg.DiscoveryURL, "", &discovery); err != nil {
+		return nil, fmt.Errorf("fetch discovery document: %w", err)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserInfoEndpoint == "" {
+		return nil, ErrInvalidDiscovery
+	}
+
+	return &oidcProvider{
+		name: cfg.Name,
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  discovery.AuthorizationEndpoint,
+				TokenURL: discovery.TokenEndpoint,
+			},
+		},
+		userInfoURL: discovery.UserInfoEndpoint,
+		client:      client,
+	}, nil
+}
+
+// Name returns the configured provider name.
+func (p *oidcProvider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL returns the authorization endpoint URL for state.
+func (p *oidcProvider) AuthCodeURL(state string) string {
+	return p.oauth.AuthCodeURL(state)
+}
+
+// Exchange redeems code at the token endpoint and reads the user from the
+// UserInfo endpoint.
+func (p *oidcProvider) Exchange(ctx context.Context, code string) (*models.ExternalIdentity, error) {
+	token, err := p.oauth.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code)
+	if err != nil {
+		return nil, fmt.Errorf("exchange authorization code: %w", err)
+	}
+
+	var info oidcUserInfo
+	if err := getJSON(ctx, p.client, p.userInfoURL, token.AccessToken, &info); err != nil {
+		return nil, fmt.Errorf("fetch userinfo: %w", err)
+	}
+	if info.Subject == "" {
+		return nil, errors.New("userinfo response has no subject")
+	}
+
+	return &models.ExternalIdentity{
+		Provider:      p.name,
+		Subject:       info.Subject,
+		Email:         info.Email,
+		EmailVerified: isTrue(info.EmailVerified),
+		Username:      info.PreferredUsername,
+	}, nil
+}
+
+// getJSON decodes the JSON response of a GET request to url into v,
+// authenticating with bearerToken when it is set.
+func getJSON(ctx context.Context, client *http.Client, url, bearerToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// isTrue reports whether a JSON boolean claim, possibly sent as a string,
+// is true.
+func isTrue(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		return strings.EqualFold(b, "true")
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// Default policy for blocking client IPs with many failed logins.
+const (
+	DefaultMaxFailuresPerIP = 20
+	DefaultIPBlockWindow    = 15 * time.Minute
+)
+
+// IPBlocker tracks failed logins per client IP across all usernames, so
+// credential stuffing spread over many accounts is caught where the
+// per-username LoginThrottler is not.
+type IPBlocker interface {
+	// RetryAfter returns how long ip remains blocked, or zero when it is
+	// not blocked.
+	RetryAfter(ip string) time.Duration
+	// RecordFailure counts a failed login from ip.
+	RecordFailure(ip string)
+}
+
+type ipFailures struct {
+	count       int
+	windowEnd   time.Time
+	lockedUntil time.Time
+}
+
+// ipBlocker keeps failure counters in memory.
+type ipBlocker struct {
+	mu          sync.Mutex
+	maxFailures int
+	window      time.Duration
+	clock       Clock
+	ips         map[string]*ipFailures
+	lastSweep   time.Time
+}
+
+// NewIPBlocker creates an in-memory IPBlocker that blocks an IP for window
+// once it has maxFailures failed logins within window. Zero values select
+// the defaults and a nil clock uses the system clock.
+func NewIPBlocker(maxFailures int, window time.Duration, clock Clock) IPBlocker {
+	if maxFailures <= 0 {
+		maxFailures = DefaultMaxFailuresPerIP
+	}
+	if window <= 0 {
+		window = DefaultIPBlockWindow
+	}
+	clock = clockOrDefault(clock)
+	return &ipBlocker{
+		maxFailures: maxFailures,
+		window:      window,
+		clock:       clock,
+		ips:         make(map[string]*ipFailures),
+		lastSweep:   clock.Now(),
+	}
+}
+
+// RetryAfter returns the remaining block time of ip.
+func (b *ipBlocker) RetryAfter(ip string) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.ips[ip]
+	if !ok {
+		return 0
+	}
+	if remaining := state.lockedUntil.Sub(b.clock.Now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// RecordFailure counts the failure in the current window of ip and blocks
+// the IP when the threshold is reached.
+func (b *ipBlocker) RecordFailure(ip string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	if now.Sub(b.lastSweep) >= b.window {
+		b.evictExpired(now)
+	}
+
+	state, ok := b.ips[ip]
+	if !ok || (!now.Before(state.windowEnd) && !now.Before(state.lockedUntil)) {
+		state = &ipFailures{windowEnd: now.Add(b.window)}
+		b.ips[ip] = state
+	}
+
+	state.count++
+	if state.count >= b.maxFailures && !now.Before(state.lockedUntil) {
+		state.lockedUntil = now.Add(b.window)
+	}
+}
+
+// evictExpired drops IPs whose window and block have both ended.
+func (b *ipBlocker) evictExpired(now time.Time) {
+	for ip, state := range b.ips {
+		if !now.Before(state.windowEnd) && !now.Before(state.lockedUntil) {
+			delete(b.ips, ip)
+		}
+	}
+	b.lastSweep = now
+}
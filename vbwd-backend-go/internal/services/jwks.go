@@ -0,0 +1,45 @@
+package services
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// KeySetProvider publishes the public keys that verify issued tokens.
+type KeySetProvider interface {
+	JWKS() models.JWKS
+}
+
+// RSAKeyID returns the RFC 7638 thumbprint of the public key, which is
+// stable across restarts and identical for every holder of the key.
+func RSAKeyID(key *rsa.PublicKey) string {
+	n, e := rsaComponents(key)
+	// Members in lexicographic order without whitespace, as RFC 7638
+	// requires. n and e are base64url and need no escaping.
+	canonical := `{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// rsaJWK describes an RS256 verification key as a JSON Web Key.
+func rsaJWK(key *rsa.PublicKey) models.JWK {
+	n, e := rsaComponents(key)
+	return models.JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: SigningRS256,
+		Kid: RSAKeyID(key),
+		N:   n,
+		E:   e,
+	}
+}
+
+// rsaComponents returns the base64url encoded modulus and exponent.
+func rsaComponents(key *rsa.PublicKey) (n, e string) {
+	return base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// Sizes of the keys generated by Rotate.
+const (
+	rotatedRSAKeyBits  = 2048
+	rotatedSecretBytes = 32
+	rotatedKeyIDBytes  = 12
+)
+
+// KeyRotator replaces the signing key of a TokenService at runtime.
+type KeyRotator interface {
+	// Rotate signs new tokens with a freshly generated key under a new
+	// key ID. Tokens signed with earlier keys keep verifying for grace
+	// and are rejected afterwards.
+	Rotate(grace time.Duration) (*models.KeyRotationResponse, error)
+}
+
+// Rotate generates a new secret for HS256 or a new RSA key for RS256. The
+// new key only lives in memory: after a restart the configured key signs
+// again and tokens signed with the generated key no longer verify. Keys
+// passed as TokenOptions.VerificationKeys never retire.
+func (s *jwtTokenService) Rotate(grace time.Duration) (*models.KeyRotationResponse, error) {
+	var signKey, verifyKey interface{}
+	var publicKey *rsa.PublicKey
+	var kid string
+	switch s.method {
+	case jwt.SigningMethodRS256:
+		privateKey, err := rsa.GenerateKey(rand.Reader, rotatedRSAKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("generate RSA key: %w", err)
+		}
+		publicKey = &privateKey.PublicKey
+		signKey, verifyKey, kid = privateKey, publicKey, RSAKeyID(publicKey)
+	default:
+		secret := make([]byte, rotatedSecretBytes+rotatedKeyIDBytes)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("generate secret: %w", err)
+		}
+		// HMAC key IDs are random so they reveal nothing about the secret.
+		id := secret[rotatedSecretBytes:]
+		secret = secret[:rotatedSecretBytes]
+		signKey, verifyKey, kid = secret, secret, base64.RawURLEncoding.EncodeToString(id)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.signKey == nil {
+		return nil, ErrSigningKeyUnavailable
+	}
+	now := s.clock.Now()
+	s.dropRetired(now)
+
+	retireAt := now.Add(max(grace, 0))
+	if s.retireAt == nil {
+		s.retireAt = make(map[string]time.Time)
+	}
+	if s.keysByKID == nil {
+		s.keysByKID = make(map[string]interface{})
+	}
+	// Tokens without a kid predate the first rotation.
+	if _, ok := s.retireAt[""]; !ok {
+		s.retireAt[""] = retireAt
+	}
+	if s.kid != "" {
+		s.retireAt[s.kid] = retireAt
+	}
+
+	s.signKey, s.kid = signKey, kid
+	s.keysByKID[kid] = verifyKey
+	if publicKey != nil {
+		s.publicKeys = append([]*rsa.PublicKey{publicKey}, s.publicKeys...)
+	}
+	return &models.KeyRotationResponse{
+		KeyID:                  kid,
+		PreviousKeysValidUntil: retireAt.UTC(),
+	}, nil
+}
+
+// retired reports whether the key with the given ID was rotated out and
+// its grace window has passed. The caller holds mu.
+func (s *jwtTokenService) retired(kid string, now time.Time) bool {
+	until, ok := s.retireAt[kid]
+	return ok && !now.Before(until)
+}
+
+// dropRetired forgets keys whose grace window has passed. The marker for
+// tokens without a kid is kept so they stay rejected. The caller holds mu.
+func (s *jwtTokenService) dropRetired(now time.Time) {
+	for kid := range s.retireAt {
+		if kid == "" || !s.retired(kid, now) {
+			continue
+		}
+		delete(s.keysByKID, kid)
+		delete(s.retireAt, kid)
+	}
+	live := s.publicKeys[:0]
+	for _, key := range s.publicKeys {
+		if _, ok := s.keysByKID[RSAKeyID(key)]; ok {
+			live = append(live, key)
+		}
+	}
+	s.publicKeys = live
+}
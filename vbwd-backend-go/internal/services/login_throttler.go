@@ -0,0 +1,95 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// Default lockout policy for repeated failed logins.
+const (
+	DefaultMaxFailedAttempts = 5
+	DefaultLockoutWindow     = 15 * time.Minute
+)
+
+// LoginThrottler tracks failed login attempts and locks out usernames that
+// exceed the allowed number of failures.
+type LoginThrottler interface {
+	IsLocked(username string) bool
+	RecordFailure(username string)
+	Reset(username string)
+}
+
+type attemptState struct {
+	failures    int
+	lockedUntil time.Time
+}
+
+// loginThrottler keeps attempt counters in memory.
+type loginThrottler struct {
+	mu          sync.Mutex
+	maxAttempts int
+	window      time.Duration
+	clock       Clock
+	attempts    map[string]*attemptState
+}
+
+// NewLoginThrottler creates an in-memory LoginThrottler. Zero values select
+// the defaults and a nil clock uses the system clock.
+func NewLoginThrottler(maxAttempts int, window time.Duration, clock Clock) LoginThrottler {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxFailedAttempts
+	}
+	if window <= 0 {
+		window = DefaultLockoutWindow
+	}
+	return &loginThrottler{
+		maxAttempts: maxAttempts,
+		window:      window,
+		clock:       clockOrDefault(clock),
+		attempts:    make(map[string]*attemptState),
+	}
+}
+
+// IsLocked reports whether the username is currently locked out. An expired
+// lockout clears the failure counter.
+func (t *loginThrottler) IsLocked(username string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.attempts[username]
+	if !ok || state.lockedUntil.IsZero() {
+		return false
+	}
+	if t.clock.Now().Before(state.lockedUntil) {
+		return true
+	}
+
+	delete(t.attempts, username)
+	return false
+}
+
+// RecordFailure counts a failed attempt and starts a lockout once the
+// threshold is reached.
+func (t *loginThrottler) RecordFailure(username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.attempts[username]
+	if !ok {
+		state = &attemptState{}
+		t.attempts[username] = state
+	}
+
+	state.failures++
+	if state.failures >= t.maxAttempts {
+		state.lockedUntil = t.clock.Now().Add(t.window)
+	}
+}
+
+// Reset clears the failure counter after a successful login.
+func (t *loginThrottler) Reset(username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.attempts, username)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+)
+
+// DefaultMFAChallengeTTL is how long the challenge token returned by a
+// password login can be exchanged for tokens at POST /mfa/login.
+const DefaultMFAChallengeTTL = 5 * time.Minute
+
+// totpOptions are the code settings of Google Authenticator and most other
+// apps: six digits every 30 seconds, accepting the codes of the adjacent
+// periods to tolerate clock drift. Each code is only accepted once.
+var totpOptions = totp.ValidateOpts{
+	Period:    30,
+	Skew:      1,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// MFAService manages TOTP second factors.
+type MFAService interface {
+	// Enroll generates a new secret for the user, labelled with
+	// accountName in authenticator apps. The secret stays pending until
+	// it is confirmed with Confirm. It returns models.ErrMFAAlreadyEnabled
+	// when the user already has MFA enabled.
+	Enroll(ctx context.Context, userID, accountName string) (*models.MFAEnrollResponse, error)
+	// Confirm enables MFA for the user when code matches the pending
+	// secret. It returns models.ErrMFANotEnrolled without a pending
+	// secret and models.ErrInvalidMFACode for a wrong code.
+	Confirm(ctx context.Context, userID, code string) error
+	// Enabled reports whether the user has confirmed MFA.
+	Enabled(ctx context.Context, userID string) (bool, error)
+	// Validate checks code against the enabled secret of the user and
+	// returns models.ErrInvalidMFACode when it does not match or was
+	// already accepted.
+	Validate(ctx context.Context, userID, code string) error
+}
+
+// mfaService implements MFAService on top of an MFAStore.
+type mfaService struct {
+	store  repository.MFAStore
+	issuer string
+	clock  Clock
+}
+
+// NewMFAService creates an MFAService keeping secrets in store. Secrets
+// are issued by issuer, the name authenticator apps show for the account.
+// A nil clock uses the system clock.
+func NewMFAService(store repository.MFAStore, issuer string, clock Clock) MFAService {
+	return &mfaService{store: store, issuer: issuer, clock: clockOrDefault(clock)}
+}
+
+// Enroll replaces any pending secret of the user with a new one.
+func (s *mfaService) Enroll(ctx context.Context, userID, accountName string) (*models.MFAEnrollResponse, error) {
+	enrollment, err := s.store.Get(ctx, userID)
+	if err != nil && !errors.Is(err, models.ErrMFANotEnrolled) {
+		return nil, err
+	}
+	if enrollment.Enabled {
+		return nil, models.ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.issuer,
+		AccountName: accountName,
+		Period:      totpOptions.Period,
+		Digits:      totpOptions.Digits,
+		Algorithm:   totpOptions.Algorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(ctx, userID, models.MFAEnrollment{Secret: key.Secret()}); err != nil {
+		return nil, err
+	}
+	return &models.MFAEnrollResponse{Secret: key.Secret(), URL: key.URL()}, nil
+}
+
+// Confirm checks the code against the pending secret and enables it.
+func (s *mfaService) Confirm(ctx context.Context, userID, code string) error {
+	enrollment, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if enrollment.Enabled {
+		return models.ErrMFAAlreadyEnabled
+	}
+	step, ok := s.match(code, enrollment.Secret)
+	if !ok {
+		return models.ErrInvalidMFACode
+	}
+
+	enrollment.Enabled = true
+	enrollment.LastStep = step
+	return s.store.Save(ctx, userID, enrollment)
+}
+
+// Enabled reports whether the user has an enabled secret.
+func (s *mfaService) Enabled(ctx context.Context, userID string) (bool, error) {
+	enrollment, err := s.store.Get(ctx, userID)
+	if errors.Is(err, models.ErrMFANotEnrolled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return enrollment.Enabled, nil
+}
+
+// Validate checks the code against the enabled secret and marks its time
+// step as used, so a replayed code is rejected. Pending secrets do not
+// count.
+func (s *mfaService) Validate(ctx context.Context, userID, code string) error {
+	enrollment, err := s.store.Get(ctx, userID)
+	if errors.Is(err, models.ErrMFANotEnrolled) {
+		return models.ErrInvalidMFACode
+	}
+	if err != nil {
+		return err
+	}
+	if !enrollment.Enabled {
+		return models.ErrInvalidMFACode
+	}
+	step, ok := s.match(code, enrollment.Secret)
+	if !ok || step <= enrollment.LastStep {
+		return models.ErrInvalidMFACode
+	}
+	fresh, err := s.store.UseStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return models.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// match returns the time step whose code of secret is code, trying the
+// current step and its neighbours within totpOptions.Skew. Malformed codes
+// are simply invalid.
+func (s *mfaService) match(code, secret string) (int64, bool) {
+	period := int64(totpOptions.Period)
+	current := s.clock.Now().Unix() / period
+	for offset := -int64(totpOptions.Skew); offset <= int64(totpOptions.Skew); offset++ {
+		step := current + offset
+		want, err := totp.GenerateCodeCustom(secret, time.Unix(step*period, 0), totpOptions)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(want)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Notifier delivers account notifications to users.
+type Notifier interface {
+	// SendPasswordReset delivers a password reset token to email.
+	SendPasswordReset(ctx context.Context, email, token string) error
+}
+
+// nopNotifier drops every notification.
+type nopNotifier struct{}
+
+// NopNotifier returns a Notifier that discards notifications.
+func NopNotifier() Notifier {
+	return nopNotifier{}
+}
+
+func (nopNotifier) SendPasswordReset(ctx context.Context, email, token string) error {
+	return nil
+}
+
+// logNotifier writes notifications to a logger.
+type logNotifier struct {
+	logger *slog.Logger
+}
+
+// NewLogNotifier returns a Notifier that logs notifications, including the
+// reset token, to logger. It is meant for local development only. A nil
+// logger uses slog.Default().
+func NewLogNotifier(logger *slog.Logger) Notifier {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &logNotifier{logger: logger}
+}
+
+func (n *logNotifier) SendPasswordReset(ctx context.Context, email, token string) error {
+	n.logger.InfoContext(ctx, "password reset requested", slog.String("email", email), slog.String("token", token))
+	return nil
+}
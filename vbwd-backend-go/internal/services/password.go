@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported password hashing algorithms.
+const (
+	HasherBcrypt   = "bcrypt"
+	HasherArgon2id = "argon2id"
+)
+
+// Password hashing errors.
+var (
+	// ErrInvalidBcryptCost is returned for a cost outside the range bcrypt
+	// supports.
+	ErrInvalidBcryptCost = fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	// ErrUnknownHasher is returned for an unsupported algorithm name.
+	ErrUnknownHasher = fmt.Errorf("password hasher must be %q or %q", HasherBcrypt, HasherArgon2id)
+	// ErrUnsupportedHash is returned when a stored hash was produced by an
+	// unknown algorithm or is malformed.
+	ErrUnsupportedHash = errors.New("unsupported password hash")
+)
+
+// Hasher hashes passwords and verifies them against stored hashes.
+type Hasher interface {
+	// Hash returns an encoded hash of password that carries its algorithm
+	// and parameters.
+	Hash(password string) (string, error)
+	// Compare reports whether password matches hash. A mismatch returns
+	// false and no error; an error means the hash could not be checked.
+	Compare(hash, password string) (bool, error)
+}
+
+// Rehasher is implemented by hashers that can tell whether a stored hash
+// was produced with outdated settings and should be replaced.
+type Rehasher interface {
+	// NeedsRehash reports whether hash uses a different algorithm or
+	// weaker parameters than new hashes.
+	NeedsRehash(hash string) bool
+}
+
+// NewHasher returns the Hasher for algorithm ("bcrypt" or "argon2id").
+// bcryptCost applies to bcrypt; Argon2id uses DefaultArgon2Params. Both
+// verify hashes of either algorithm, so switching keeps existing passwords
+// working.
+func NewHasher(algorithm string, bcryptCost int) (Hasher, error) {
+	switch algorithm {
+	case HasherBcrypt:
+		return NewBcryptHasher(bcryptCost)
+	case HasherArgon2id:
+		return NewArgon2idHasher(DefaultArgon2Params())
+	default:
+		return nil, fmt.Errorf("%w: got %q", ErrUnknownHasher, algorithm)
+	}
+}
+
+// BcryptHasher hashes and verifies passwords with bcrypt at a fixed cost.
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher creates a BcryptHasher with the given cost, which must be
+// between bcrypt.MinCost and bcrypt.MaxCost. Lower costs hash faster and
+// suit tests; production should keep at least bcrypt.DefaultCost.
+func NewBcryptHasher(cost int) (*BcryptHasher, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("%w: got %d", ErrInvalidBcryptCost, cost)
+	}
+	return &BcryptHasher{cost: cost}, nil
+}
+
+// defaultHasher is shared by services created without a hasher.
+var defaultHasher = sync.OnceValue(func() *BcryptHasher {
+	hasher, err := NewBcryptHasher(bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hasher
+})
+
+// DefaultBcryptHasher returns a hasher using bcrypt.DefaultCost.
+func DefaultBcryptHasher() *BcryptHasher {
+	return defaultHasher()
+}
+
+// Cost returns the bcrypt cost used for new hashes.
+func (h *BcryptHasher) Cost() int {
+	return h.cost
+}
+
+// Hash returns the bcrypt hash of a plaintext password.
+func (h *BcryptHasher) Hash(plain string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Compare reports whether plain matches hash. Argon2id hashes are verified
+// too.
+func (h *BcryptHasher) Compare(hash, plain string) (bool, error) {
+	return comparePassword(hash, plain)
+}
+
+// NeedsRehash reports whether hash is not a bcrypt hash at the configured
+// cost.
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
+
+// comparePassword verifies plain against a bcrypt or Argon2id hash,
+// detecting the algorithm from the hash prefix.
+func comparePassword(hash, plain string) (bool, error) {
+	switch {
+	case isBcryptHash(hash):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrUnsupportedHash, err)
+		}
+		return true, nil
+	case strings.HasPrefix(hash, argon2idPrefix):
+		return compareArgon2id(hash, plain)
+	default:
+		return false, ErrUnsupportedHash
+	}
+}
+
+func isBcryptHash(value string) bool {
+	_, err := bcrypt.Cost([]byte(value))
+	return err == nil
+}
+
+// isPasswordHash reports whether value is already a supported password
+// hash rather than a plaintext password.
+func isPasswordHash(value string) bool {
+	if isBcryptHash(value) {
+		return true
+	}
+	_, _, _, err := decodeArgon2id(value)
+	return err == nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// PasswordPolicy describes the minimum strength required for new passwords.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy requires at least 8 characters mixing upper case,
+// lower case and digits.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:    models.MinPasswordLength,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+	}
+}
+
+// Validate returns a *models.WeakPasswordError listing every rule the
+// password fails, or nil when it complies.
+func (p PasswordPolicy) Validate(password string) error {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	var violations []string
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, "an upper-case letter")
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, "a lower-case letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, "a symbol")
+	}
+
+	if len(violations) > 0 {
+		return &models.WeakPasswordError{Violations: violations}
+	}
+	return nil
+}
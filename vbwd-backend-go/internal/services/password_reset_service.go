@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+)
+
+// DefaultResetTokenTTL is the lifetime of password reset tokens.
+const DefaultResetTokenTTL = 15 * time.Minute
+
+// resetTokenBytes is the amount of randomness in a reset token.
+const resetTokenBytes = 32
+
+// resetNotifyTimeout bounds the delivery of one reset notification.
+const resetNotifyTimeout = 30 * time.Second
+
+// PasswordResetService implements the forgot-password flow.
+type PasswordResetService interface {
+	// RequestReset issues a reset token for the account with the given
+	// email and delivers it through the Notifier in the background. It
+	// returns an empty token and no error when no account matches, and
+	// storage and delivery failures for a known account are only logged,
+	// so neither the result nor the response time reveals which emails
+	// are registered.
+	RequestReset(ctx context.Context, email string) (string, error)
+	// ResetPassword consumes the token, sets the new password and revokes
+	// all sessions and API keys of the user. Unknown, used and expired
+	// tokens return models.ErrInvalidResetToken.
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+// passwordResetService implements PasswordResetService.
+type passwordResetService struct {
+	users    repository.UserRepository
+	tokens   repository.ResetTokenStore
+	hasher   Hasher
+	policy   PasswordPolicy
+	ttl      time.Duration
+	notifier Notifier
+	clock    Clock
+	logger   *slog.Logger
+	sessions SessionService
+	keys     APIKeyService
+}
+
+// NewPasswordResetService creates a PasswordResetService that keeps tokens
+// in store for ttl, sends them through notifier and hashes new passwords
+// with hasher after checking them against policy. A non-positive ttl uses
+// DefaultResetTokenTTL, a nil hasher uses DefaultBcryptHasher(), a nil
+// notifier uses NopNotifier(), a nil clock uses the system clock and a nil
+// logger, which records delivery failures, uses slog.Default(). The
+// sessions and API keys of users who reset their password are revoked
+// through sessions and keys; a nil one skips that revocation.
+func NewPasswordResetService(repo repository.UserRepository, store repository.ResetTokenStore, hasher Hasher, policy PasswordPolicy, ttl time.Duration, notifier Notifier, clock Clock, logger *slog.Logger, sessions SessionService, keys APIKeyService) PasswordResetService {
+	if hasher == nil {
+		hasher = DefaultBcryptHasher()
+	}
+	if ttl <= 0 {
+		ttl = DefaultResetTokenTTL
+	}
+	if notifier == nil {
+		notifier = NopNotifier()
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &passwordResetService{
+		users:    repo,
+		tokens:   store,
+		hasher:   hasher,
+		policy:   policy,
+		ttl:      ttl,
+		notifier: notifier,
+		clock:    clockOrDefault(clock),
+		logger:   logger,
+		sessions: sessions,
+		keys:     keys,
+	}
+}
+
+// RequestReset generates a random token and stores its hash.
+func (s *passwordResetService) RequestReset(ctx context.Context, email string) (string, error) {
+	user, err := s.users.FindByEmail(ctx, models.NormalizeEmail(email))
+	if errors.Is(err, models.ErrUserNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	raw := make([]byte, resetTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := s.tokens.Save(ctx, hashResetToken(token), user.ID, s.clock.Now().Add(s.ttl)); err != nil {
+		s.logger.ErrorContext(ctx, "save password reset token", slog.Any("error", err))
+		return "", nil
+	}
+	go s.notify(context.WithoutCancel(ctx), user.Email, token)
+	return token, nil
+}
+
+// notify delivers a reset token outside the request, so a slow or failing
+// mail relay neither delays nor fails the response.
+func (s *passwordResetService) notify(ctx context.Context, email, token string) {
+	ctx, cancel := context.WithTimeout(ctx, resetNotifyTimeout)
+	defer cancel()
+
+	if err := s.notifier.SendPasswordReset(ctx, email, token); err != nil {
+		s.logger.ErrorContext(ctx, "notify password reset", slog.String("email", email), slog.Any("error", err))
+	}
+}
+
+// ResetPassword validates the new password before consuming the token so a
+// policy violation does not burn it. Whoever held the account before the
+// reset loses its sessions and keys.
+func (s *passwordResetService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := s.policy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	userID, expiresAt, err := s.tokens.Consume(ctx, hashResetToken(token))
+	if err != nil {
+		return err
+	}
+	if !s.clock.Now().Before(expiresAt) {
+		return models.ErrInvalidResetToken
+	}
+
+	hash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	err = s.users.UpdatePassword(ctx, userID, hash)
+	if errors.Is(err, models.ErrUserNotFound) {
+		return models.ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	return revokeCredentials(ctx, s.sessions, s.keys, userID, "")
+}
+
+// hashResetToken returns the hex SHA-256 digest under which a token is
+// stored.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
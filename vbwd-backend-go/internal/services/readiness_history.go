@@ -0,0 +1,66 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// DefaultReadinessHistorySize is how many readiness evaluations a
+// HealthService remembers.
+const DefaultReadinessHistorySize = 50
+
+// readinessHistory is a fixed-size ring buffer of the latest readiness
+// evaluations. It is safe for concurrent use.
+type readinessHistory struct {
+	mu      sync.Mutex
+	entries []models.ReadinessEvent
+	// next is the slot the next evaluation is written to; once the buffer
+	// is full it holds the oldest evaluation.
+	next int
+	full bool
+}
+
+func newReadinessHistory(size int) *readinessHistory {
+	return &readinessHistory{entries: make([]models.ReadinessEvent, size)}
+}
+
+// record stores an evaluation, overwriting the oldest one when full.
+func (h *readinessHistory) record(event models.ReadinessEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries[h.next] = event
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns the stored evaluations, oldest first, and the capacity.
+func (h *readinessHistory) list() ([]models.ReadinessEvent, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.full {
+		return append([]models.ReadinessEvent(nil), h.entries[:h.next]...), len(h.entries)
+	}
+	events := make([]models.ReadinessEvent, 0, len(h.entries))
+	events = append(events, h.entries[h.next:]...)
+	return append(events, h.entries[:h.next]...), len(h.entries)
+}
+
+// readinessEvent summarizes a readiness result for the history.
+func readinessEvent(resp *models.ReadinessResponse) models.ReadinessEvent {
+	event := models.ReadinessEvent{
+		Ready:       resp.Ready,
+		Maintenance: resp.Maintenance,
+		Timestamp:   resp.Timestamp,
+	}
+	for _, check := range resp.Checks {
+		if !check.Healthy {
+			event.FailedChecks = append(event.FailedChecks, check.Name)
+		}
+	}
+	return event
+}
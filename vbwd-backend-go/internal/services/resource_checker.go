@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+)
+
+// ResourceThresholds configure NewResourceChecker. A zero minimum disables
+// that part of the check.
+type ResourceThresholds struct {
+	// DiskPath is the filesystem whose free space is checked.
+	DiskPath string
+	// MinFreeDiskBytes is the least free space DiskPath may have.
+	MinFreeDiskBytes uint64
+	// MinFreeMemoryBytes is the least headroom between the memory obtained
+	// from the OS and the runtime memory limit (GOMEMLIMIT). Without a
+	// memory limit there is no headroom to measure and memory passes.
+	MinFreeMemoryBytes uint64
+}
+
+// ResourceStats supplies the measurements of a resource check. Nil fields
+// read the running process and system.
+type ResourceStats struct {
+	ReadMemStats func(*runtime.MemStats)
+	// MemoryLimit returns the runtime memory limit; math.MaxInt64 means
+	// none is set.
+	MemoryLimit func() int64
+	// DiskFree returns the bytes available to unprivileged users on the
+	// filesystem holding path.
+	DiskFree func(path string) (uint64, error)
+}
+
+// NewResourceChecker returns a readiness Checker that fails when free disk
+// space on thresholds.DiskPath or free memory under the runtime memory
+// limit drops below the thresholds. Memory is measured with
+// runtime.MemStats.
+func NewResourceChecker(thresholds ResourceThresholds, stats ResourceStats) Checker {
+	if stats.ReadMemStats == nil {
+		stats.ReadMemStats = runtime.ReadMemStats
+	}
+	if stats.MemoryLimit == nil {
+		stats.MemoryLimit = func() int64 { return debug.SetMemoryLimit(-1) }
+	}
+	if stats.DiskFree == nil {
+		stats.DiskFree = diskFree
+	}
+
+	return func(ctx context.Context) error {
+		if thresholds.MinFreeMemoryBytes > 0 {
+			if free, ok := freeMemory(stats); ok && free < thresholds.MinFreeMemoryBytes {
+				return fmt.Errorf("free memory %s below %s", formatBytes(free), formatBytes(thresholds.MinFreeMemoryBytes))
+			}
+		}
+
+		if thresholds.MinFreeDiskBytes > 0 {
+			free, err := stats.DiskFree(thresholds.DiskPath)
+			if err != nil {
+				return fmt.Errorf("read free disk space of %s: %w", thresholds.DiskPath, err)
+			}
+			if free < thresholds.MinFreeDiskBytes {
+				return fmt.Errorf("free disk space on %s %s below %s", thresholds.DiskPath, formatBytes(free), formatBytes(thresholds.MinFreeDiskBytes))
+			}
+		}
+		return nil
+	}
+}
+
+// freeMemory returns the memory limit minus the memory obtained from the
+// OS. It reports false when no limit is set.
+func freeMemory(stats ResourceStats) (uint64, bool) {
+	limit := stats.MemoryLimit()
+	if limit <= 0 || limit == math.MaxInt64 {
+		return 0, false
+	}
+
+	var mem runtime.MemStats
+	stats.ReadMemStats(&mem)
+	if mem.Sys >= uint64(limit) {
+		return 0, true
+	}
+	return uint64(limit) - mem.Sys, true
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5GiB".
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+)
+
+// seedUser is the JSON form of a user in a seed file.
+type seedUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// LoadSeedFile reads users from a JSON file holding an array of
+// {"id","username","email","password","role"} objects. Passwords may be plaintext
+// or bcrypt hashes; NewAuthServiceFromSeed hashes plaintext ones.
+func LoadSeedFile(path string) ([]models.User, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read seed file: %w", err)
+	}
+
+	var entries []seedUser
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse seed file %s: %w", path, err)
+	}
+
+	users := make([]models.User, 0, len(entries))
+	for _, entry := range entries {
+		users = append(users, models.User(entry))
+	}
+	return users, nil
+}
+
+// NewSeededUserRepository creates an in-memory repository holding the seed
+// users. Usernames and emails must be unique and passwords non-empty;
+// plaintext passwords are hashed with hasher (DefaultBcryptHasher() when
+// nil), missing IDs are generated and missing roles default to
+// models.RoleUser.
+// An empty seed yields an empty repository.
+func NewSeededUserRepository(seed []models.User, hasher Hasher) (repository.UserRepository, error) {
+	if hasher == nil {
+		hasher = DefaultBcryptHasher()
+	}
+
+	users, err := prepareSeed(seed, hasher)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewInMemoryUserRepository(users...), nil
+}
+
+// NewAuthServiceFromSeed creates an AuthService configured by opts over a
+// repository built by NewSeededUserRepository with the configured hasher.
+// A WithRepository option is overridden.
+func NewAuthServiceFromSeed(seed []models.User, opts ...AuthOption) (AuthService, error) {
+	repo, err := NewSeededUserRepository(seed, newAuthService(opts).hasher)
+	if err != nil {
+		return nil, err
+	}
+	return NewAuthService(append(opts[:len(opts):len(opts)], WithRepository(repo))...), nil
+}
+
+func prepareSeed(seed []models.User, hasher Hasher) ([]models.User, error) {
+	users := make([]models.User, 0, len(seed))
+	seen := make(map[string]bool, len(seed))
+	seenEmails := make(map[string]bool, len(seed))
+
+	for i, user := range seed {
+		if user.Username == "" {
+			return nil, fmt.Errorf("seed user %d: %w", i, models.ErrUsernameRequired)
+		}
+		if seen[user.Username] {
+			return nil, fmt.Errorf("seed user %q: %w", user.Username, models.ErrUserExists)
+		}
+		seen[user.Username] = true
+
+		user, err := prepareUser(user, hasher)
+		if err != nil {
+			return nil, fmt.Errorf("seed user %q: %w", user.Username, err)
+		}
+		if user.Email != "" {
+			if seenEmails[user.Email] {
+				return nil, fmt.Errorf("seed user %q: %w", user.Username, models.ErrEmailExists)
+			}
+			seenEmails[user.Email] = true
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// prepareUser normalizes and checks the email, hashes a plaintext password
+// and fills in a missing ID and role.
+func prepareUser(user models.User, hasher Hasher) (models.User, error) {
+	if user.Email != "" {
+		user.Email = models.NormalizeEmail(user.Email)
+		if !models.ValidEmail(user.Email) {
+			return user, models.ErrInvalidEmail
+		}
+	}
+
+	if user.Password == "" {
+		return user, models.ErrPasswordRequired
+	}
+	if !isPasswordHash(user.Password) {
+		hash, err := hasher.Hash(user.Password)
+		if err != nil {
+			return user, err
+		}
+		user.Password = hash
+	}
+
+	if user.ID == "" {
+		user.ID = uuid.NewString()
+	}
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	return user, nil
+}
+
+// ImportUsers prepares users like a seed, hashing plaintext passwords with
+// hasher (DefaultBcryptHasher() when nil), and stores them with
+// repo.BulkCreate. Invalid users are skipped like duplicates; errs reports
+// both, each naming the user.
+func ImportUsers(ctx context.Context, repo repository.UserRepository, users []models.User, hasher Hasher) (imported int, errs []error) {
+	if hasher == nil {
+		hasher = DefaultBcryptHasher()
+	}
+
+	batch := make([]models.User, 0, len(users))
+	for i, user := range users {
+		if user.Username == "" {
+			errs = append(errs, fmt.Errorf("user %d: %w", i, models.ErrUsernameRequired))
+			continue
+		}
+		prepared, err := prepareUser(user, hasher)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %q: %w", user.Username, err))
+			continue
+		}
+		batch = append(batch, prepared)
+	}
+
+	imported, skipped := repo.BulkCreate(ctx, batch)
+	return imported, append(errs, skipped...)
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+)
+
+// maxDeviceLength caps the stored device description.
+const maxDeviceLength = 256
+
+// SessionService manages the login sessions of users.
+type SessionService interface {
+	// Start records a new session of the user on device.
+	Start(ctx context.Context, userID, device string) (*models.Session, error)
+	// Validate marks the session as seen and returns
+	// models.ErrSessionNotFound when it was revoked or has been pruned.
+	Validate(ctx context.Context, sessionID string) error
+	// List returns the sessions of the user, oldest first.
+	List(ctx context.Context, userID string) ([]models.Session, error)
+	// Revoke ends a session of the user. Tokens of the session stop
+	// validating immediately.
+	Revoke(ctx context.Context, userID, sessionID string) error
+	// RevokeAll ends every session of the user and returns how many were
+	// ended.
+	RevokeAll(ctx context.Context, userID string) (int, error)
+	// RevokeOthers ends every session of the user except keepID and
+	// returns how many were ended.
+	RevokeOthers(ctx context.Context, userID, keepID string) (int, error)
+}
+
+type sessionService struct {
+	store       repository.SessionStore
+	idleTimeout time.Duration
+	clock       Clock
+}
+
+// NewSessionService creates a SessionService over store. Sessions unused
+// for idleTimeout are dropped; pass the refresh token lifetime, after which
+// no token of the session can be valid any more. A nil clock uses the
+// system clock.
+func NewSessionService(store repository.SessionStore, idleTimeout time.Duration, clock Clock) SessionService {
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultRefreshTokenTTL
+	}
+	return &sessionService{store: store, idleTimeout: idleTimeout, clock: clockOrDefault(clock)}
+}
+
+// Start records a new session and prunes idle ones.
+func (s *sessionService) Start(ctx context.Context, userID, device string) (*models.Session, error) {
+	now := s.clock.Now()
+	if _, err := s.store.DeleteIdleSince(ctx, now.Add(-s.idleTimeout)); err != nil {
+		return nil, fmt.Errorf("prune sessions: %w", err)
+	}
+
+	if len(device) > maxDeviceLength {
+		device = device[:maxDeviceLength]
+	}
+	session := models.Session{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Device:    device,
+		CreatedAt: now,
+		LastSeen:  now,
+	}
+	if err := s.store.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Validate touches the session. Idle sessions need no check here: they
+// outlive every token issued for them and are pruned by Start.
+func (s *sessionService) Validate(ctx context.Context, sessionID string) error {
+	_, err := s.store.Touch(ctx, sessionID, s.clock.Now())
+	return err
+}
+
+// List returns the sessions of the user.
+func (s *sessionService) List(ctx context.Context, userID string) ([]models.Session, error) {
+	return s.store.ListByUser(ctx, userID)
+}
+
+// Revoke deletes a session of the user.
+func (s *sessionService) Revoke(ctx context.Context, userID, sessionID string) error {
+	return s.store.Delete(ctx, userID, sessionID)
+}
+
+// RevokeAll deletes all sessions of the user.
+func (s *sessionService) RevokeAll(ctx context.Context, userID string) (int, error) {
+	return s.store.DeleteByUser(ctx, userID)
+}
+
+// RevokeOthers deletes the other sessions one by one. Sessions that
+// disappear meanwhile are skipped.
+func (s *sessionService) RevokeOthers(ctx context.Context, userID, keepID string) (int, error) {
+	sessions, err := s.store.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == keepID {
+			continue
+		}
+		err := s.store.Delete(ctx, userID, session.ID)
+		if errors.Is(err, models.ErrSessionNotFound) {
+			continue
+		}
+		if err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+type deviceContextKey struct{}
+
+// WithDevice stores a description of the client device, such as its
+// User-Agent, in ctx. Authenticate records it with the new session.
+func WithDevice(ctx context.Context, device string) context.Context {
+	return context.WithValue(ctx, deviceContextKey{}, device)
+}
+
+// DeviceFromContext returns the device stored by WithDevice, or "".
+func DeviceFromContext(ctx context.Context) string {
+	device, _ := ctx.Value(deviceContextKey{}).(string)
+	return device
+}
+
+// RevocationPolicy decides how tokens are treated when their session
+// cannot be checked because the session store fails.
+type RevocationPolicy string
+
+const (
+	// RevocationFailClosed rejects the token, favoring security.
+	RevocationFailClosed RevocationPolicy = "fail_closed"
+	// RevocationFailOpen accepts the otherwise valid token and logs a
+	// warning, favoring availability. A session revoked while the store
+	// is down keeps working until the store recovers.
+	RevocationFailOpen RevocationPolicy = "fail_open"
+)
+
+// DefaultSessionCheckTimeout bounds the session lookup made for each
+// token, so that a hanging store is treated as a failing one.
+const DefaultSessionCheckTimeout = 2 * time.Second
+
+// SessionTokenOptions configures NewSessionTokenService. Zero values
+// select RevocationFailClosed, DefaultSessionCheckTimeout and
+// slog.Default().
+type SessionTokenOptions struct {
+	Policy RevocationPolicy
+	// Timeout bounds each session lookup. When it expires the check has
+	// failed and Policy decides whether the token is accepted.
+	Timeout time.Duration
+	Logger  *slog.Logger
+}
+
+// sessionTokenService rejects tokens whose login session was revoked.
+type sessionTokenService struct {
+	TokenService
+	sessions SessionService
+	policy   RevocationPolicy
+	timeout  time.Duration
+	logger   *slog.Logger
+}
+
+// NewSessionTokenService wraps tokenService so that Parse also rejects
+// tokens bound to a revoked session and marks live sessions as seen.
+// Tokens without a session ID are validated by tokenService alone.
+func NewSessionTokenService(tokenService TokenService, sessions SessionService, opts SessionTokenOptions) TokenService {
+	if opts.Policy == "" {
+		opts.Policy = RevocationFailClosed
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultSessionCheckTimeout
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &sessionTokenService{
+		TokenService: tokenService,
+		sessions:     sessions,
+		policy:       opts.Policy,
+		timeout:      opts.Timeout,
+		logger:       opts.Logger,
+	}
+}
+
+// Parse verifies the token and its session.
+func (s *sessionTokenService) Parse(token string) (*Claims, error) {
+	claims, err := s.TokenService.Parse(token)
+	if err != nil || claims.SessionID == "" {
+		return claims, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	err = s.sessions.Validate(ctx, claims.SessionID)
+	if errors.Is(err, models.ErrSessionNotFound) {
+		return nil, fmt.Errorf("%w: session revoked", models.ErrInvalidToken)
+	}
+	if err != nil {
+		if s.policy == RevocationFailOpen {
+			s.logger.Warn("session check failed, accepting token",
+				"session_id", claims.SessionID, "policy", string(s.policy), "error", err)
+			return claims, nil
+		}
+		return nil, fmt.Errorf("validate session: %w", err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// SMTPConfig holds the settings of an SMTP relay.
+type SMTPConfig struct {
+	Host string
+	Port int
+	// Username and Password enable PLAIN authentication when Username is
+	// set.
+	Username string
+	Password string
+	// From is the sender address of every message.
+	From string
+}
+
+// smtpNotifier sends notifications as plain-text emails.
+type smtpNotifier struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPNotifier returns a Notifier that sends emails through the SMTP
+// relay described by cfg using net/smtp.
+func NewSMTPNotifier(cfg SMTPConfig) Notifier {
+	return &smtpNotifier{cfg: cfg}
+}
+
+// SendPasswordReset mails the reset token to email. net/smtp does not
+// accept a context, so ctx is only checked before connecting.
+func (n *smtpNotifier) SendPasswordReset(ctx context.Context, email, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	body := "A password reset was requested for your account.\r\n\r\n" +
+		"Use this token to choose a new password: " + token + "\r\n\r\n" +
+		"If you did not request a reset, you can ignore this email.\r\n"
+	msg := n.message(email, "Password reset", body)
+
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+	if err := smtp.SendMail(addr, auth, n.cfg.From, []string{email}, msg); err != nil {
+		return fmt.Errorf("send password reset email: %w", err)
+	}
+	return nil
+}
+
+// message builds an RFC 5322 message with the given recipient, subject and
+// body.
+func (n *smtpNotifier) message(to, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + n.cfg.From + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(body)
+	return []byte(b.String())
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// Default token lifetimes.
+const (
+	DefaultAccessTokenTTL  = time.Hour
+	DefaultRefreshTokenTTL = 24 * time.Hour
+)
+
+// Signing algorithms supported by the token service.
+const (
+	SigningHS256 = "HS256"
+	SigningRS256 = "RS256"
+)
+
+// ErrSigningKeyUnavailable is returned when a verify-only TokenService is
+// asked to issue a token.
+var ErrSigningKeyUnavailable = errors.New("token service has no signing key")
+
+// Token types carried in the token_type claim.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// TokenTypeMFA marks the challenge token of a login that still needs
+	// a second factor. It authorizes nothing but POST /mfa/login.
+	TokenTypeMFA = "mfa"
+	// TokenTypeLink marks a token that lets a user link an external
+	// identity at the end of GET /auth/{provider}/callback. It authorizes
+	// nothing else.
+	TokenTypeLink = "link"
+)
+
+// Claims are the JWT claims carried by issued tokens. The subject holds
+// the user ID; SessionID names the login session the token belongs to.
+type Claims struct {
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type"`
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ClaimOption adds optional claims to an issued token.
+type ClaimOption func(*Claims)
+
+// WithSessionID binds the token to a login session.
+func WithSessionID(id string) ClaimOption {
+	return func(c *Claims) { c.SessionID = id }
+}
+
+// asMFAChallenge turns an access token into an MFA challenge token that
+// expires after ttl.
+func asMFAChallenge(ttl time.Duration) ClaimOption {
+	return func(c *Claims) {
+		c.TokenType = TokenTypeMFA
+		c.ExpiresAt = jwt.NewNumericDate(c.IssuedAt.Add(ttl))
+	}
+}
+
+// asLinkIntent turns an access token into a link token that expires after
+// ttl.
+func asLinkIntent(ttl time.Duration) ClaimOption {
+	return func(c *Claims) {
+		c.TokenType = TokenTypeLink
+		c.ExpiresAt = jwt.NewNumericDate(c.IssuedAt.Add(ttl))
+	}
+}
+
+// TokenService issues and verifies signed tokens.
+type TokenService interface {
+	Generate(user models.User, opts ...ClaimOption) (string, error)
+	GenerateRefresh(user models.User, opts ...ClaimOption) (string, error)
+	Parse(token string) (*Claims, error)
+}
+
+// TokenOptions configures token lifetimes and validation tolerance. Zero
+// TTLs select the defaults; a zero Leeway disables clock skew tolerance.
+type TokenOptions struct {
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// Leeway is the clock skew tolerated when validating expiry and
+	// issued-at claims.
+	Leeway time.Duration
+	// Clock provides the issue and validation time; nil uses the system clock.
+	Clock Clock
+	// VerificationKeys are further RSA public keys accepted for RS256
+	// tokens, such as keys retired during a rotation. They are published
+	// in the JWKS but never used for signing.
+	VerificationKeys []*rsa.PublicKey
+}
+
+// jwtTokenService signs tokens with method. signKey is nil for services
+// that only verify tokens. RS256 tokens carry the key ID of the signing key
+// in their kid header and are verified with the public key of that ID;
+// tokens without a kid are verified with verifyKey. Rotate replaces the
+// signing key at runtime, so the key fields are guarded by mu.
+type jwtTokenService struct {
+	method     jwt.SigningMethod
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	leeway     time.Duration
+	clock      Clock
+
+	mu         sync.RWMutex
+	signKey    interface{}
+	kid        string
+	verifyKey  interface{}
+	publicKeys []*rsa.PublicKey
+	keysByKID  map[string]interface{}
+	// retireAt holds when keys replaced by Rotate stop verifying tokens,
+	// by key ID. The key for tokens without a kid is listed under "".
+	retireAt map[string]time.Time
+}
+
+// NewTokenService creates a TokenService signing with the given HMAC secret.
+func NewTokenService(secret string, opts TokenOptions) TokenService {
+	return newJWTTokenService(jwt.SigningMethodHS256, []byte(secret), []byte(secret), opts)
+}
+
+// NewRS256TokenService creates a TokenService signing with the RSA private
+// key and verifying with its public key or opts.VerificationKeys. Other
+// services verify the tokens with NewRS256Verifier and the public keys
+// alone, which the returned service also implements KeySetProvider for.
+func NewRS256TokenService(privateKey *rsa.PrivateKey, opts TokenOptions) TokenService {
+	s := newJWTTokenService(jwt.SigningMethodRS256, privateKey, &privateKey.PublicKey, opts)
+	s.setPublicKeys(append([]*rsa.PublicKey{&privateKey.PublicKey}, opts.VerificationKeys...))
+	return s
+}
+
+// NewRS256Verifier creates a TokenService that verifies RS256 tokens with
+// the RSA public key or opts.VerificationKeys. It cannot issue tokens:
+// Generate and GenerateRefresh return ErrSigningKeyUnavailable.
+func NewRS256Verifier(publicKey *rsa.PublicKey, opts TokenOptions) TokenService {
+	s := newJWTTokenService(jwt.SigningMethodRS256, nil, publicKey, opts)
+	s.setPublicKeys(append([]*rsa.PublicKey{publicKey}, opts.VerificationKeys...))
+	return s
+}
+
+func newJWTTokenService(method jwt.SigningMethod, signKey, verifyKey interface{}, opts TokenOptions) *jwtTokenService {
+	if opts.AccessTTL <= 0 {
+		opts.AccessTTL = DefaultAccessTokenTTL
+	}
+	if opts.RefreshTTL <= 0 {
+		opts.RefreshTTL = DefaultRefreshTokenTTL
+	}
+	return &jwtTokenService{
+		method:     method,
+		signKey:    signKey,
+		verifyKey:  verifyKey,
+		accessTTL:  opts.AccessTTL,
+		refreshTTL: opts.RefreshTTL,
+		leeway:     opts.Leeway,
+		clock:      clockOrDefault(opts.Clock),
+	}
+}
+
+// setPublicKeys indexes keys by key ID. The first key is the current one
+// and its ID is put into the kid header of issued tokens.
+func (s *jwtTokenService) setPublicKeys(keys []*rsa.PublicKey) {
+	s.kid = RSAKeyID(keys[0])
+	s.keysByKID = make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		kid := RSAKeyID(key)
+		if _, dup := s.keysByKID[kid]; dup {
+			continue
+		}
+		s.keysByKID[kid] = key
+		s.publicKeys = append(s.publicKeys, key)
+	}
+}
+
+// JWKS returns the public verification keys, current key first. Keys
+// retired after a rotation are left out. It is empty for HMAC signing.
+func (s *jwtTokenService) JWKS() models.JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+	keys := make([]models.JWK, 0, len(s.publicKeys))
+	for _, key := range s.publicKeys {
+		jwk := rsaJWK(key)
+		if s.retired(jwk.Kid, now) {
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+	return models.JWKS{Keys: keys}
+}
+
+// Generate issues a signed access token for the user.
+func (s *jwtTokenService) Generate(user models.User, opts ...ClaimOption) (string, error) {
+	return s.sign(user, TokenTypeAccess, s.accessTTL, opts)
+}
+
+// GenerateRefresh issues a signed, longer-lived refresh token for the user.
+func (s *jwtTokenService) GenerateRefresh(user models.User, opts ...ClaimOption) (string, error) {
+	return s.sign(user, TokenTypeRefresh, s.refreshTTL, opts)
+}
+
+func (s *jwtTokenService) sign(user models.User, tokenType string, ttl time.Duration, opts []ClaimOption) (string, error) {
+	s.mu.RLock()
+	signKey, kid := s.signKey, s.kid
+	s.mu.RUnlock()
+	if signKey == nil {
+		return "", ErrSigningKeyUnavailable
+	}
+
+	now := s.clock.Now()
+	claims := Claims{
+		Username:  user.Username,
+		Role:      user.Role,
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	for _, opt := range opts {
+		opt(&claims)
+	}
+
+	token := jwt.NewWithClaims(s.method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(signKey)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// Parse verifies the token signature and expiry and returns its claims.
+func (s *jwtTokenService) Parse(token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, s.keyFor,
+		jwt.WithValidMethods([]string{s.method.Alg()}),
+		jwt.WithLeeway(s.leeway),
+		jwt.WithTimeFunc(s.clock.Now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// keyFor selects the verification key named by the token's kid header.
+// Keys retired after a rotation no longer verify.
+func (s *jwtTokenService) keyFor(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.retired(kid, s.clock.Now()) {
+		return nil, fmt.Errorf("key id %q was retired", kid)
+	}
+	if kid == "" || s.keysByKID == nil {
+		return s.verifyKey, nil
+	}
+	key, ok := s.keysByKID[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+)
+
+// UserService defines the user administration use cases.
+type UserService interface {
+	List(ctx context.Context, page models.PageRequest) (*models.Page[models.User], error)
+	// Delete removes the user with the given ID on behalf of the user
+	// actorID and revokes the user's sessions and API keys, so tokens
+	// issued before stop working. Deleting oneself fails with
+	// models.ErrCannotDeleteSelf so an admin cannot lock themselves out.
+	Delete(ctx context.Context, actorID, id string) error
+}
+
+// userService implements UserService on top of a UserRepository.
+type userService struct {
+	users    repository.UserRepository
+	sessions SessionService
+	keys     APIKeyService
+}
+
+// NewUserService creates a UserService backed by the given repository
+// that revokes the sessions and API keys of deleted users. A nil sessions
+// or keys skips that revocation.
+func NewUserService(repo repository.UserRepository, sessions SessionService, keys APIKeyService) UserService {
+	return &userService{users: repo, sessions: sessions, keys: keys}
+}
+
+// List returns a page of users. The limit is clamped to
+// models.MaxPageLimit and defaults to models.DefaultPageLimit.
+func (s *userService) List(ctx context.Context, page models.PageRequest) (*models.Page[models.User], error) {
+	if page.Offset < 0 {
+		page.Offset = 0
+	}
+	if page.Limit <= 0 {
+		page.Limit = models.DefaultPageLimit
+	}
+	page.Limit = min(page.Limit, models.MaxPageLimit)
+
+	users, err := s.users.List(ctx, page.Offset, page.Limit)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.users.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Page[models.User]{
+		Items:  users,
+		Total:  total,
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}, nil
+}
+
+// Delete removes a user other than the actor, then the user's
+// credentials.
+func (s *userService) Delete(ctx context.Context, actorID, id string) error {
+	if id == actorID {
+		return models.ErrCannotDeleteSelf
+	}
+	if err := s.users.Delete(ctx, id); err != nil {
+		return err
+	}
+	return revokeCredentials(ctx, s.sessions, s.keys, id, "")
+}
+
+// revokeCredentials revokes the sessions of the user except keepSessionID,
+// when set, and all API keys of the user, so tokens and keys an attacker
+// may hold stop working. A nil sessions or keys is skipped.
+func revokeCredentials(ctx context.Context, sessions SessionService, keys APIKeyService, userID, keepSessionID string) error {
+	if sessions != nil {
+		var err error
+		if keepSessionID == "" {
+			_, err = sessions.RevokeAll(ctx, userID)
+		} else {
+			_, err = sessions.RevokeOthers(ctx, userID, keepSessionID)
+		}
+		if err != nil {
+			return fmt.Errorf("revoke sessions: %w", err)
+		}
+	}
+	if keys != nil {
+		if _, err := keys.RevokeAll(ctx, userID); err != nil {
+			return fmt.Errorf("revoke api keys: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+// Package client is a Go SDK for the vbwd backend HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+// Response types shared with the server.
+type (
+	LoginResponse     = models.LoginResponse
+	HealthResponse    = models.HealthResponse
+	ReadinessResponse = models.ReadinessResponse
+	CheckResult       = models.CheckResult
+)
+
+// Client calls the backend API at a base URL. Requests carry the access
+// token obtained by Login or set with SetToken.
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	autoRefresh bool
+	retryPolicy RetryPolicy
+	tokens      *tokenStore
+}
+
+// NewClient creates a Client for the API served at baseURL, for example
+// "http://localhost:8082".
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		httpClient:  http.DefaultClient,
+		retryPolicy: DefaultRetryPolicy(),
+		tokens:      &tokenStore{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	base := c.httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport := &authTransport{
+		base:   &retryTransport{base: base, policy: c.retryPolicy},
+		tokens: c.tokens,
+	}
+	if c.autoRefresh {
+		transport.refresh = func(ctx context.Context) error {
+			_, err := c.Refresh(ctx)
+			return err
+		}
+	}
+
+	// Copy the caller's client so its transport is left untouched.
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+	return c
+}
+
+// SetToken sets the access token sent as a bearer token with each request.
+func (c *Client) SetToken(token string) {
+	c.tokens.setAccess(token)
+}
+
+// SetRefreshToken sets the refresh token used by Refresh.
+func (c *Client) SetRefreshToken(token string) {
+	c.tokens.setRefresh(token)
+}
+
+// Token returns the current access token.
+func (c *Client) Token() string {
+	access, _ := c.tokens.get()
+	return access
+}
+
+// Login exchanges credentials for an access and a refresh token and stores
+// both for subsequent requests.
+func (c *Client) Login(ctx context.Context, username, password string) (*LoginResponse, error) {
+	var resp LoginResponse
+	req := models.LoginRequest{Username: username, Password: password}
+	if err := c.do(withoutAuth(ctx), http.MethodPost, "/login", req, &resp); err != nil {
+		return nil, err
+	}
+
+	c.tokens.setAccess(resp.Token)
+	c.tokens.setRefresh(resp.RefreshToken)
+	return &resp, nil
+}
+
+// Refresh exchanges the stored refresh token for a new access token and
+// stores it.
+func (c *Client) Refresh(ctx context.Context) (*LoginResponse, error) {
+	_, refreshToken := c.tokens.get()
+	if refreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+
+	var resp LoginResponse
+	req := models.RefreshRequest{RefreshToken: refreshToken}
+	if err := c.do(withoutAuth(ctx), http.MethodPost, "/refresh", req, &resp); err != nil {
+		return nil, err
+	}
+
+	c.tokens.setAccess(resp.Token)
+	return &resp, nil
+}
+
+// ChangePassword changes the password of the authenticated user.
+func (c *Client) ChangePassword(ctx context.Context, oldPassword, newPassword string) error {
+	req := models.ChangePasswordRequest{OldPassword: oldPassword, NewPassword: newPassword}
+	return c.do(ctx, http.MethodPost, "/password", req, nil)
+}
+
+// Health returns the service health status.
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	var resp HealthResponse
+	if err := c.do(ctx, http.MethodGet, "/health", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Ready returns the readiness of the service with the result of every
+// dependency check. When the service answers 503 the parsed response is
+// returned together with an error matching ErrNotReady that names the
+// failed checks.
+func (c *Client) Ready(ctx context.Context) (*ReadinessResponse, error) {
+	resp, err := c.send(ctx, http.MethodGet, "/readyz", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, readAPIError(resp)
+	}
+
+	var readiness ReadinessResponse
+	if err := json.NewDecoder(resp.Body).Decode(&readiness); err != nil {
+		return nil, timeoutError(http.MethodGet, "/readyz", fmt.Errorf("decode response: %w", err))
+	}
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return &readiness, notReadyError(readiness)
+	}
+	return &readiness, nil
+}
+
+// notReadyError wraps ErrNotReady with the reason readiness failed.
+func notReadyError(readiness ReadinessResponse) error {
+	if readiness.Maintenance {
+		return fmt.Errorf("%w: maintenance mode", ErrNotReady)
+	}
+
+	var failed []string
+	for _, check := range readiness.Checks {
+		if !check.Healthy {
+			failed = append(failed, check.Name)
+		}
+	}
+	if len(failed) == 0 {
+		return ErrNotReady
+	}
+	return fmt.Errorf("%w: failed checks: %s", ErrNotReady, strings.Join(failed, ", "))
+}
+
+// do sends a request with an optional JSON body and decodes a 2xx JSON
+// response into out. Non-2xx responses are returned as *APIError.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	resp, err := c.send(ctx, method, path, in)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return readAPIError(resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return timeoutError(method, path, fmt.Errorf("decode response: %w", err))
+	}
+	return nil
+}
+
+// send issues a request with an optional JSON body. The caller closes the
+// response body. The request is bound to ctx, so cancelling it aborts the
+// request, including any retry backoff or token refresh; a missed deadline
+// is returned as a *TimeoutError.
+func (c *Client) send(ctx context.Context, method, path string, in interface{}) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return nil, fmt.Errorf("encode request: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, timeoutError(method, path, err)
+	}
+	return resp, nil
+}
+
+// readAPIError converts a failed response into an *APIError.
+func readAPIError(resp *http.Response) error {
+	var errBody errorBody
+	// A body that is not JSON still yields an error for the status.
+	_ = json.NewDecoder(resp.Body).Decode(&errBody)
+	return newAPIError(resp.StatusCode, errBody)
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// ErrNoRefreshToken is returned by Refresh when no refresh token is stored.
+var ErrNoRefreshToken = errors.New("vbwd: no refresh token")
+
+// ErrNotReady is returned by Ready when the service reports it is not
+// ready to serve traffic.
+var ErrNotReady = errors.New("vbwd: service not ready")
+
+// TimeoutError is returned when a request does not complete before the
+// deadline of its context or the timeout of the HTTP client. It matches
+// context.DeadlineExceeded with errors.Is.
+type TimeoutError struct {
+	Method string
+	Path   string
+	Err    error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("vbwd: %s %s timed out: %v", e.Method, e.Path, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports a match for context.DeadlineExceeded even when the timeout
+// came from http.Client.Timeout rather than a context deadline.
+func (e *TimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+// Timeout reports true so a TimeoutError satisfies net.Error.
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// Temporary reports false; it is part of net.Error.
+func (e *TimeoutError) Temporary() bool {
+	return false
+}
+
+// timeoutError wraps err in a *TimeoutError when it reports a deadline or
+// timeout, and returns any other error unchanged.
+func timeoutError(method, path string, err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &TimeoutError{Method: method, Path: path, Err: err}
+	}
+	return err
+}
+
+// FieldError describes a rejected request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+// APIError is returned for non-2xx responses. Code is the error code sent
+// by the server, or the code conventionally used for StatusCode when the
+// body carries none.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Fields     []FieldError
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("vbwd: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// errorBody matches the error shapes written by the server: the error
+// envelope, validation errors and unsuccessful login responses.
+type errorBody struct {
+	Message string `json:"message"`
+	Error   *struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	} `json:"error"`
+	Errors []FieldError `json:"errors"`
+}
+
+func newAPIError(statusCode int, body errorBody) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Message:    body.Message,
+		Fields:     body.Errors,
+	}
+	if body.Error != nil {
+		apiErr.Code = body.Error.Code
+		apiErr.Message = body.Error.Message
+	}
+	if apiErr.Code == "" {
+		apiErr.Code = string(response.CodeForStatus(statusCode))
+	}
+	if apiErr.Message == "" && len(body.Errors) > 0 {
+		apiErr.Message = body.Errors[0].Message
+	}
+	return apiErr
+}
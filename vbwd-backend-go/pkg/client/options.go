@@ -0,0 +1,33 @@
+package client
+
+import "net/http"
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests. The default is
+// http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithRetryPolicy sets how requests answered with 429 are retried. The
+// default is DefaultRetryPolicy; a zero MaxRetries disables retrying.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithAutoRefresh makes the client refresh the access token with the stored
+// refresh token when a request is answered with 401, and retry the request
+// once.
+func WithAutoRefresh() Option {
+	return func(c *Client) {
+		c.autoRefresh = true
+	}
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests answered with 429 Too Many Requests
+// are retried. The delay honors the Retry-After header when present and
+// otherwise grows exponentially from BaseDelay with jitter.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt; zero
+	// disables retrying.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry; it doubles with
+	// every further retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A Retry-After longer than MaxDelay is not
+	// waited for and the 429 is returned instead.
+	MaxDelay time.Duration
+	// RetryNonIdempotent also retries methods such as POST. By default
+	// only safe and idempotent methods are retried.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 2,
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   5 * time.Second,
+	}
+}
+
+// retryTransport retries requests answered with 429 according to policy.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+
+	for attempt := 0; attempt < t.policy.MaxRetries; attempt++ {
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || !t.retryable(req) {
+			return resp, err
+		}
+
+		delay, ok := t.delay(attempt, resp.Header.Get("Retry-After"))
+		if !ok {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = t.base.RoundTrip(retry)
+	}
+	return resp, err
+}
+
+// retryable reports whether the method may be retried under the policy and
+// the request body can be replayed.
+func (t *retryTransport) retryable(req *http.Request) bool {
+	if !t.policy.RetryNonIdempotent && !idempotent(req.Method) {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// delay returns the wait before retry number attempt, and false when the
+// server asks for a longer wait than MaxDelay allows.
+func (t *retryTransport) delay(attempt int, retryAfter string) (time.Duration, bool) {
+	if wait, ok := parseRetryAfter(retryAfter); ok {
+		return wait, t.policy.MaxDelay <= 0 || wait <= t.policy.MaxDelay
+	}
+
+	backoff := t.policy.BaseDelay << attempt
+	if t.policy.MaxDelay > 0 && (backoff > t.policy.MaxDelay || backoff <= 0) {
+		backoff = t.policy.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0, true
+	}
+	// Equal jitter: wait between half and all of the backoff.
+	half := backoff / 2
+	return half + rand.N(backoff-half+1), true
+}
+
+// parseRetryAfter parses a Retry-After value given in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
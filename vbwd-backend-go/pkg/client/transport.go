@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// tokenStore holds the tokens shared by the Client and its transport.
+type tokenStore struct {
+	mu           sync.RWMutex
+	access       string
+	refreshToken string
+}
+
+func (s *tokenStore) get() (access, refresh string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.access, s.refreshToken
+}
+
+func (s *tokenStore) setAccess(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.access = token
+}
+
+func (s *tokenStore) setRefresh(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshToken = token
+}
+
+type skipAuthKey struct{}
+
+// withoutAuth marks requests that must not carry the bearer token or
+// trigger a refresh, such as login and refresh themselves.
+func withoutAuth(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipAuthKey{}, true)
+}
+
+// authTransport injects the bearer token into requests and, when refresh
+// is set, refreshes the token once after a 401 and retries the request.
+type authTransport struct {
+	base    http.RoundTripper
+	tokens  *tokenStore
+	refresh func(ctx context.Context) error
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if skip, _ := req.Context().Value(skipAuthKey{}).(bool); skip {
+		return t.base.RoundTrip(req)
+	}
+
+	resp, err := t.base.RoundTrip(t.authorize(req))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !t.canRetry(req) {
+		return resp, err
+	}
+
+	if err := t.refresh(req.Context()); err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			resp.Body.Close()
+			return nil, ctxErr
+		}
+		// Surface the original 401 to the caller.
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(t.authorize(retry))
+}
+
+// canRetry reports whether a refresh is configured, a refresh token is
+// stored and the request body can be replayed.
+func (t *authTransport) canRetry(req *http.Request) bool {
+	if t.refresh == nil {
+		return false
+	}
+	if _, refresh := t.tokens.get(); refresh == "" {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// authorize returns a copy of req carrying the current access token.
+func (t *authTransport) authorize(req *http.Request) *http.Request {
+	access, _ := t.tokens.get()
+	if access == "" {
+		return req
+	}
+
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+access)
+	return authorized
+}
@@ -0,0 +1,97 @@
+package i18n
+
+// DefaultCatalog returns German and French translations of the error codes
+// whose message does not depend on the request. Generic codes such as
+// BAD_REQUEST carry request-specific details and are left in English.
+func DefaultCatalog() Catalog {
+	return Catalog{
+		"INVALID_CREDENTIALS": {
+			"de": "Ungültige Anmeldedaten",
+			"fr": "Identifiants invalides",
+		},
+		"ACCOUNT_LOCKED": {
+			"de": "Konto vorübergehend gesperrt",
+			"fr": "Compte temporairement verrouillé",
+		},
+		"INVALID_TOKEN": {
+			"de": "Ungültiges Token",
+			"fr": "Jeton invalide",
+		},
+		"USER_NOT_FOUND": {
+			"de": "Benutzer nicht gefunden",
+			"fr": "Utilisateur introuvable",
+		},
+		"USER_EXISTS": {
+			"de": "Benutzer existiert bereits",
+			"fr": "L'utilisateur existe déjà",
+		},
+		"EMAIL_EXISTS": {
+			"de": "E-Mail-Adresse ist bereits registriert",
+			"fr": "L'adresse e-mail est déjà enregistrée",
+		},
+		"CANNOT_DELETE_SELF": {
+			"de": "Administratoren können ihr eigenes Konto nicht löschen",
+			"fr": "Les administrateurs ne peuvent pas supprimer leur propre compte",
+		},
+		"SESSION_NOT_FOUND": {
+			"de": "Sitzung nicht gefunden",
+			"fr": "Session introuvable",
+		},
+		"WEAK_PASSWORD": {
+			"de": "Das Passwort erfüllt die Sicherheitsrichtlinie nicht",
+			"fr": "Le mot de passe ne respecte pas la politique de sécurité",
+		},
+		"USERNAME_REQUIRED": {
+			"de": "Benutzername ist erforderlich",
+			"fr": "Le nom d'utilisateur est obligatoire",
+		},
+		"USERNAME_TOO_SHORT": {
+			"de": "Benutzername muss mindestens 3 Zeichen lang sein",
+			"fr": "Le nom d'utilisateur doit contenir au moins 3 caractères",
+		},
+		"USERNAME_LENGTH": {
+			"de": "Benutzername muss zwischen 3 und 32 Zeichen lang sein",
+			"fr": "Le nom d'utilisateur doit contenir entre 3 et 32 caractères",
+		},
+		"PASSWORD_REQUIRED": {
+			"de": "Passwort ist erforderlich",
+			"fr": "Le mot de passe est obligatoire",
+		},
+		"PASSWORD_TOO_SHORT": {
+			"de": "Passwort muss mindestens 8 Zeichen lang sein",
+			"fr": "Le mot de passe doit contenir au moins 8 caractères",
+		},
+		"INVALID_EMAIL": {
+			"de": "E-Mail-Adresse ist ungültig",
+			"fr": "L'adresse e-mail n'est pas valide",
+		},
+		"UNAUTHORIZED": {
+			"de": "Authentifizierung erforderlich",
+			"fr": "Authentification requise",
+		},
+		"FORBIDDEN": {
+			"de": "Unzureichende Berechtigungen",
+			"fr": "Permissions insuffisantes",
+		},
+		"TOO_MANY_REQUESTS": {
+			"de": "Zu viele Anfragen",
+			"fr": "Trop de requêtes",
+		},
+		"PAYLOAD_TOO_LARGE": {
+			"de": "Anfrage zu groß",
+			"fr": "Requête trop volumineuse",
+		},
+		"UNSUPPORTED_MEDIA_TYPE": {
+			"de": "Content-Type muss application/json sein",
+			"fr": "Le Content-Type doit être application/json",
+		},
+		"SERVICE_UNAVAILABLE": {
+			"de": "Dienst nicht verfügbar",
+			"fr": "Service indisponible",
+		},
+		"INTERNAL_ERROR": {
+			"de": "Interner Serverfehler",
+			"fr": "Erreur interne du serveur",
+		},
+	}
+}
@@ -0,0 +1,92 @@
+// Package i18n localizes API error messages. Messages are looked up by
+// their error code, so the English text written by handlers stays the
+// fallback for anything a catalog does not translate.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is used when a request names no supported locale. Its
+// messages are the ones written by the handlers.
+const DefaultLocale = "en"
+
+// Catalog maps an error code to its message per locale, e.g.
+// catalog["INVALID_CREDENTIALS"]["de"].
+type Catalog map[string]map[string]string
+
+// Translate returns the message for code in locale, or fallback when the
+// catalog has none.
+func (c Catalog) Translate(locale, code, fallback string) string {
+	if message, ok := c[code][locale]; ok {
+		return message
+	}
+	return fallback
+}
+
+// Locales returns the locales the catalog translates into, sorted, plus
+// DefaultLocale.
+func (c Catalog) Locales() []string {
+	seen := map[string]bool{DefaultLocale: true}
+	for _, messages := range c {
+		for locale := range messages {
+			seen[locale] = true
+		}
+	}
+
+	locales := make([]string, 0, len(seen))
+	for locale := range seen {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Negotiate picks the supported locale the Accept-Language header prefers.
+// Region subtags fall back to their language ("de-AT" matches "de"). It
+// returns DefaultLocale when nothing matches.
+func Negotiate(acceptLanguage string, supported []string) string {
+	best, bestQ := DefaultLocale, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, q := parseLanguageRange(part)
+		if tag == "" || q <= bestQ {
+			continue
+		}
+		if locale, ok := match(tag, supported); ok {
+			best, bestQ = locale, q
+		}
+	}
+	return best
+}
+
+// parseLanguageRange splits "de-AT;q=0.8" into its lower-cased tag and
+// quality. Malformed qualities count as 0.
+func parseLanguageRange(part string) (string, float64) {
+	tag, params, _ := strings.Cut(part, ";")
+	tag = strings.ToLower(strings.TrimSpace(tag))
+
+	q := 1.0
+	if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			parsed = 0
+		}
+		q = parsed
+	}
+	return tag, q
+}
+
+// match finds tag or its primary language among supported.
+func match(tag string, supported []string) (string, bool) {
+	language, _, _ := strings.Cut(tag, "-")
+	for _, candidate := range []string{tag, language} {
+		for _, locale := range supported {
+			if strings.ToLower(locale) == candidate {
+				return locale, true
+			}
+		}
+	}
+	return "", false
+}
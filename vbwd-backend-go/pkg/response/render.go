@@ -0,0 +1,169 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Media types Render can produce.
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeMsgpack = "application/x-msgpack"
+	ContentTypeText    = "text/plain"
+)
+
+// Renderer encodes response payloads in one media type.
+type Renderer interface {
+	// ContentType is the media type the renderer produces.
+	ContentType() string
+	// Encode writes data to w.
+	Encode(w io.Writer, data interface{}) error
+}
+
+// renderers are the formats Render negotiates, in order of preference when
+// the client rates several equally. JSON comes first so it stays the
+// default.
+var renderers = []Renderer{JSONRenderer{}, MsgpackRenderer{}, TextRenderer{}}
+
+// JSONRenderer renders payloads as JSON.
+type JSONRenderer struct{}
+
+// ContentType returns application/json.
+func (JSONRenderer) ContentType() string { return ContentTypeJSON }
+
+// Encode writes data as JSON.
+func (JSONRenderer) Encode(w io.Writer, data interface{}) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+// MsgpackRenderer renders payloads as MessagePack. Field names follow the
+// json struct tags so both formats carry the same keys.
+type MsgpackRenderer struct{}
+
+// ContentType returns application/x-msgpack.
+func (MsgpackRenderer) ContentType() string { return ContentTypeMsgpack }
+
+// Encode writes data as MessagePack.
+func (MsgpackRenderer) Encode(w io.Writer, data interface{}) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(data)
+}
+
+// TextRenderer renders payloads as plain text: strings and fmt.Stringer
+// values as they are, anything else in Go's %+v notation.
+type TextRenderer struct{}
+
+// ContentType returns text/plain.
+func (TextRenderer) ContentType() string { return ContentTypeText }
+
+// Encode writes data as text followed by a newline.
+func (TextRenderer) Encode(w io.Writer, data interface{}) error {
+	var err error
+	switch v := data.(type) {
+	case string:
+		_, err = fmt.Fprintln(w, v)
+	case fmt.Stringer:
+		_, err = fmt.Fprintln(w, v.String())
+	default:
+		_, err = fmt.Fprintf(w, "%+v\n", data)
+	}
+	return err
+}
+
+// Render writes data with the given status in the format the request's
+// Accept header prefers. Requests without Accept, or accepting none of the
+// supported formats, get JSON.
+func Render(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	renderer := Negotiate(r.Header.Get("Accept"))
+	contentType := renderer.ContentType()
+	if contentType == ContentTypeText {
+		contentType += "; charset=utf-8"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(statusCode)
+	renderer.Encode(w, data)
+}
+
+// Negotiate returns the renderer the Accept header rates highest, or the
+// JSON renderer when none is acceptable.
+func Negotiate(accept string) Renderer {
+	if strings.TrimSpace(accept) == "" {
+		return renderers[0]
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := renderers[0], 0.0
+	for _, renderer := range renderers {
+		if q := acceptQuality(ranges, renderer.ContentType()); q > bestQ {
+			best, bestQ = renderer, q
+		}
+	}
+	return best
+}
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept splits an Accept header into media ranges. Entries with an
+// invalid quality are treated as not acceptable.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(fields[0])), "/")
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.TrimSpace(name) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				parsed = 0
+			}
+			q = parsed
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality of contentType under the most specific
+// matching media range, or 0 when no range matches.
+func acceptQuality(ranges []mediaRange, contentType string) float64 {
+	typ, subtype, _ := strings.Cut(contentType, "/")
+	q, specificity := 0.0, -1
+	for _, mr := range ranges {
+		var s int
+		switch {
+		case mr.typ == typ && mr.subtype == subtype:
+			s = 2
+		case mr.typ == typ && mr.subtype == "*":
+			s = 1
+		case mr.typ == "*" && mr.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = mr.q, s
+		}
+	}
+	return q
+}
@@ -0,0 +1,142 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ErrorCode is a stable, machine-readable error identifier.
+type ErrorCode string
+
+// Error codes emitted in error envelopes.
+const (
+	CodeBadRequest         ErrorCode = "BAD_REQUEST"
+	CodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	CodeForbidden          ErrorCode = "FORBIDDEN"
+	CodeNotFound           ErrorCode = "NOT_FOUND"
+	CodeMethodNotAllowed   ErrorCode = "METHOD_NOT_ALLOWED"
+	CodeConflict           ErrorCode = "CONFLICT"
+	CodePayloadTooLarge    ErrorCode = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMedia   ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+	CodeTooManyRequests    ErrorCode = "TOO_MANY_REQUESTS"
+	CodeInternal           ErrorCode = "INTERNAL_ERROR"
+	CodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+)
+
+// SuccessEnvelope wraps successful payloads.
+type SuccessEnvelope struct {
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data"`
+}
+
+// ErrorEnvelope wraps error details.
+type ErrorEnvelope struct {
+	Success bool        `json:"success"`
+	Error   ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes an error for API clients.
+type ErrorDetail struct {
+	Message string    `json:"message"`
+	Code    ErrorCode `json:"code"`
+}
+
+// JSON writes data as a JSON response with the given status code.
+func JSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
+
+// Success writes data wrapped as {"success":true,"data":...}.
+func Success(w http.ResponseWriter, statusCode int, data interface{}) {
+	JSON(w, statusCode, SuccessEnvelope{Success: true, Data: data})
+}
+
+// Error writes an error envelope with the code derived from the status.
+func Error(w http.ResponseWriter, statusCode int, message string) {
+	ErrorWithCode(w, statusCode, CodeForStatus(statusCode), message)
+}
+
+// ErrorWithCode writes {"success":false,"error":{"message":...,"code":...}}.
+// The message is translated when w carries a Localizer.
+func ErrorWithCode(w http.ResponseWriter, statusCode int, code ErrorCode, message string) {
+	JSON(w, statusCode, ErrorEnvelope{
+		Success: false,
+		Error:   ErrorDetail{Message: Localize(w, string(code), message), Code: code},
+	})
+}
+
+// Localizer translates error messages by code into the language negotiated
+// for a response. Response writers implement it to localize the messages
+// written through them.
+type Localizer interface {
+	Localize(code, message string) string
+}
+
+// Localize translates message with the first Localizer found on w or the
+// writers it wraps, and returns message unchanged when there is none.
+func Localize(w http.ResponseWriter, code, message string) string {
+	for w != nil {
+		if localizer, ok := w.(Localizer); ok {
+			return localizer.Localize(code, message)
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = unwrapper.Unwrap()
+	}
+	return message
+}
+
+// codedError is implemented by domain errors that carry their own error
+// code and HTTP status.
+type codedError interface {
+	error
+	ErrorCode() string
+	HTTPStatus() int
+}
+
+// FromError writes an error envelope using the code and status carried by
+// err. Errors without a code are reported as a generic 500 so internal
+// details never reach the client.
+func FromError(w http.ResponseWriter, err error) {
+	var coded codedError
+	if errors.As(err, &coded) {
+		ErrorWithCode(w, coded.HTTPStatus(), ErrorCode(coded.ErrorCode()), coded.Error())
+		return
+	}
+	ErrorWithCode(w, http.StatusInternalServerError, CodeInternal, "Internal server error")
+}
+
+// CodeForStatus returns the generic error code for an HTTP status.
+func CodeForStatus(statusCode int) ErrorCode {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMedia
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	if statusCode >= 400 && statusCode < 500 {
+		return CodeBadRequest
+	}
+	return CodeInternal
+}
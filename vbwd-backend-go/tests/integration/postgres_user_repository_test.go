@@ -0,0 +1,241 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+)
+
+// openTestDB connects to TEST_DATABASE_URL and resets the users table,
+// skipping the test when no database is configured.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec(`DROP TABLE IF EXISTS users`); err != nil {
+		t.Fatalf("drop users table: %v", err)
+	}
+	if _, err := db.Exec(repository.UsersTableSchema); err != nil {
+		t.Fatalf("create users table: %v", err)
+	}
+	return db
+}
+
+func TestPostgresUserRepository_CreateAndFind(t *testing.T) {
+	repo := repository.NewPostgresUserRepository(openTestDB(t))
+	user := models.User{ID: "7", Username: "alice", Password: "hash", Role: models.RoleUser}
+
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	found, err := repo.FindByUsername(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("FindByUsername() unexpected error: %v", err)
+	}
+	if *found != user {
+		t.Errorf("FindByUsername() = %+v, want %+v", *found, user)
+	}
+}
+
+func TestPostgresUserRepository_CreateDuplicate(t *testing.T) {
+	repo := repository.NewPostgresUserRepository(openTestDB(t))
+	user := models.User{ID: "7", Username: "alice", Password: "hash", Role: models.RoleUser}
+
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	user.ID = "8"
+	if err := repo.Create(context.Background(), user); !errors.Is(err, models.ErrUserExists) {
+		t.Errorf("Create() duplicate error = %v, want %v", err, models.ErrUserExists)
+	}
+}
+
+func TestPostgresUserRepository_FindByUsername_NotFound(t *testing.T) {
+	repo := repository.NewPostgresUserRepository(openTestDB(t))
+
+	if _, err := repo.FindByUsername(context.Background(), "nobody"); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("FindByUsername() error = %v, want %v", err, models.ErrUserNotFound)
+	}
+}
+
+func TestPostgresUserRepository_FindByUsername_InjectionSafe(t *testing.T) {
+	repo := repository.NewPostgresUserRepository(openTestDB(t))
+	if err := repo.Create(context.Background(), models.User{ID: "7", Username: "alice", Password: "hash", Role: models.RoleUser}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	if _, err := repo.FindByUsername(context.Background(), "' OR '1'='1"); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("FindByUsername() error = %v, want %v", err, models.ErrUserNotFound)
+	}
+}
+
+func TestPostgresUserRepository_UpdatePassword(t *testing.T) {
+	repo := repository.NewPostgresUserRepository(openTestDB(t))
+	if err := repo.Create(context.Background(), models.User{ID: "7", Username: "alice", Password: "old-hash", Role: models.RoleUser}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	if err := repo.UpdatePassword(context.Background(), "7", "new-hash"); err != nil {
+		t.Fatalf("UpdatePassword() unexpected error: %v", err)
+	}
+
+	found, err := repo.FindByUsername(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("FindByUsername() unexpected error: %v", err)
+	}
+	if found.Password != "new-hash" {
+		t.Errorf("Password = %q, want %q", found.Password, "new-hash")
+	}
+
+	if err := repo.UpdatePassword(context.Background(), "missing", "hash"); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("UpdatePassword() missing user error = %v, want %v", err, models.ErrUserNotFound)
+	}
+}
+
+func TestPostgresUserRepository_List(t *testing.T) {
+	repo := repository.NewPostgresUserRepository(openTestDB(t))
+	for i, username := range []string{"carol", "alice", "bob"} {
+		user := models.User{ID: fmt.Sprint(i), Username: username, Password: "hash", Role: models.RoleUser}
+		if err := repo.Create(context.Background(), user); err != nil {
+			t.Fatalf("Create(%s) unexpected error: %v", username, err)
+		}
+	}
+
+	users, err := repo.List(context.Background(), 1, 5)
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(users) != 2 || users[0].Username != "bob" || users[1].Username != "carol" {
+		t.Errorf("List() = %+v, want bob and carol", users)
+	}
+}
+
+func TestPostgresUserRepository_Email(t *testing.T) {
+	repo := repository.NewPostgresUserRepository(openTestDB(t))
+	alice := models.User{ID: "7", Username: "alice", Email: "alice@example.com", Password: "hash", Role: models.RoleUser}
+	if err := repo.Create(context.Background(), alice); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	// Users without email must not collide on the unique constraint.
+	for i, username := range []string{"bob", "carol"} {
+		if err := repo.Create(context.Background(), models.User{ID: fmt.Sprint(10 + i), Username: username, Password: "hash", Role: models.RoleUser}); err != nil {
+			t.Fatalf("Create(%s) unexpected error: %v", username, err)
+		}
+	}
+
+	found, err := repo.FindByEmail(context.Background(), "alice@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail() unexpected error: %v", err)
+	}
+	if *found != alice {
+		t.Errorf("FindByEmail() = %+v, want %+v", *found, alice)
+	}
+
+	duplicate := models.User{ID: "8", Username: "dave", Email: "alice@example.com", Password: "hash", Role: models.RoleUser}
+	if err := repo.Create(context.Background(), duplicate); !errors.Is(err, models.ErrEmailExists) {
+		t.Errorf("Create() duplicate email error = %v, want %v", err, models.ErrEmailExists)
+	}
+	if _, err := repo.FindByEmail(context.Background(), "nobody@example.com"); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("FindByEmail() error = %v, want %v", err, models.ErrUserNotFound)
+	}
+}
+
+func TestPostgresUserRepository_BulkCreate(t *testing.T) {
+	repo := repository.NewPostgresUserRepository(openTestDB(t))
+	ctx := context.Background()
+	if err := repo.Create(ctx, models.User{ID: "1", Username: "admin", Password: "hash", Role: models.RoleAdmin}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	users := []models.User{
+		{ID: "2", Username: "alice", Email: "alice@example.com", Password: "hash", Role: models.RoleUser},
+		{ID: "3", Username: "admin", Password: "hash", Role: models.RoleUser},
+		{ID: "4", Username: "bob", Password: "hash", Role: models.RoleUser},
+		{ID: "5", Username: "carol", Email: "alice@example.com", Password: "hash", Role: models.RoleUser},
+	}
+	imported, errs := repo.BulkCreate(ctx, users)
+	if imported != 2 {
+		t.Errorf("imported = %d, want 2", imported)
+	}
+
+	wantErrs := []error{models.ErrUserExists, models.ErrEmailExists}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("errs = %v, want %d errors", errs, len(wantErrs))
+	}
+	for i, want := range wantErrs {
+		if !errors.Is(errs[i], want) {
+			t.Errorf("errs[%d] = %v, want %v", i, errs[i], want)
+		}
+	}
+
+	if total, _ := repo.Count(ctx); total != 3 {
+		t.Errorf("total users = %d, want 3", total)
+	}
+}
+
+func TestPostgresUserRepository_Count(t *testing.T) {
+	repo := repository.NewPostgresUserRepository(openTestDB(t))
+	ctx := context.Background()
+	assertCount := func(step string, want int) {
+		t.Helper()
+		total, err := repo.Count(ctx)
+		if err != nil {
+			t.Fatalf("%s: Count() unexpected error: %v", step, err)
+		}
+		if total != want {
+			t.Errorf("%s: Count() = %d, want %d", step, total, want)
+		}
+	}
+
+	assertCount("empty", 0)
+	for i, username := range []string{"alice", "bob"} {
+		if err := repo.Create(ctx, models.User{ID: fmt.Sprint(i), Username: username, Password: "hash", Role: models.RoleUser}); err != nil {
+			t.Fatalf("Create(%s) unexpected error: %v", username, err)
+		}
+	}
+	assertCount("after creations", 2)
+	if err := repo.Delete(ctx, "0"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	assertCount("after deletion", 1)
+}
+
+func TestPostgresUserRepository_Delete(t *testing.T) {
+	repo := repository.NewPostgresUserRepository(openTestDB(t))
+	ctx := context.Background()
+	if err := repo.Create(ctx, models.User{ID: "7", Username: "alice", Password: "hash", Role: models.RoleUser}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	if err := repo.Delete(ctx, "7"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := repo.FindByUsername(ctx, "alice"); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("FindByUsername() error = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if err := repo.Delete(ctx, "7"); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("Delete() missing error = %v, want %v", err, models.ErrUserNotFound)
+	}
+}
@@ -0,0 +1,171 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+)
+
+// openTestRedis connects to TEST_REDIS_ADDR and empties its database,
+// skipping the test when no Redis is configured. Point it at a disposable
+// instance.
+func openTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR not set")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+
+	if err := client.FlushDB(context.Background()).Err(); err != nil {
+		t.Fatalf("flush redis: %v", err)
+	}
+	return client
+}
+
+func newTestSession(id, userID string, at time.Time) models.Session {
+	return models.Session{ID: id, UserID: userID, Device: "curl/8", CreatedAt: at, LastSeen: at}
+}
+
+func TestRedisSessionStore_CreateTouchAndList(t *testing.T) {
+	store := repository.NewRedisSessionStore(openTestRedis(t), time.Hour)
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Second)
+
+	for _, session := range []models.Session{
+		newTestSession("b", "1", start.Add(time.Second)),
+		newTestSession("a", "1", start),
+		newTestSession("c", "2", start),
+	} {
+		if err := store.Create(ctx, session); err != nil {
+			t.Fatalf("Create(%s) unexpected error: %v", session.ID, err)
+		}
+	}
+
+	touched, err := store.Touch(ctx, "a", start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Touch() unexpected error: %v", err)
+	}
+	if !touched.LastSeen.Equal(start.Add(time.Minute)) {
+		t.Errorf("LastSeen = %v, want %v", touched.LastSeen, start.Add(time.Minute))
+	}
+	if _, err := store.Touch(ctx, "missing", start); !errors.Is(err, models.ErrSessionNotFound) {
+		t.Errorf("Touch() missing error = %v, want %v", err, models.ErrSessionNotFound)
+	}
+
+	sessions, err := store.ListByUser(ctx, "1")
+	if err != nil {
+		t.Fatalf("ListByUser() unexpected error: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "a" || sessions[1].ID != "b" {
+		t.Fatalf("ListByUser() = %+v, want a then b", sessions)
+	}
+	if sessions[0].Device != "curl/8" || !sessions[0].LastSeen.Equal(start.Add(time.Minute)) {
+		t.Errorf("session a = %+v, want device and touched last-seen time", sessions[0])
+	}
+}
+
+func TestRedisSessionStore_Delete(t *testing.T) {
+	store := repository.NewRedisSessionStore(openTestRedis(t), time.Hour)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for _, session := range []models.Session{
+		newTestSession("a", "1", now),
+		newTestSession("b", "1", now),
+		newTestSession("c", "2", now),
+	} {
+		if err := store.Create(ctx, session); err != nil {
+			t.Fatalf("Create(%s) unexpected error: %v", session.ID, err)
+		}
+	}
+
+	if err := store.Delete(ctx, "2", "a"); !errors.Is(err, models.ErrSessionNotFound) {
+		t.Errorf("Delete() of another user's session error = %v, want %v", err, models.ErrSessionNotFound)
+	}
+	if err := store.Delete(ctx, "1", "a"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := store.Touch(ctx, "a", now.Add(time.Second)); !errors.Is(err, models.ErrSessionNotFound) {
+		t.Errorf("Touch() after Delete() error = %v, want %v", err, models.ErrSessionNotFound)
+	}
+
+	removed, err := store.DeleteByUser(ctx, "1")
+	if err != nil {
+		t.Fatalf("DeleteByUser() unexpected error: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("DeleteByUser() = %d, want 1", removed)
+	}
+	if sessions, _ := store.ListByUser(ctx, "2"); len(sessions) != 1 {
+		t.Errorf("sessions of other user = %+v, want 1", sessions)
+	}
+}
+
+func TestRedisSessionStore_DeleteIdleSince(t *testing.T) {
+	store := repository.NewRedisSessionStore(openTestRedis(t), time.Hour)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for _, session := range []models.Session{
+		newTestSession("idle", "1", now.Add(-2*time.Hour)),
+		newTestSession("active", "1", now.Add(-2*time.Hour)),
+	} {
+		if err := store.Create(ctx, session); err != nil {
+			t.Fatalf("Create(%s) unexpected error: %v", session.ID, err)
+		}
+	}
+	if _, err := store.Touch(ctx, "active", now); err != nil {
+		t.Fatalf("Touch() unexpected error: %v", err)
+	}
+
+	removed, err := store.DeleteIdleSince(ctx, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteIdleSince() unexpected error: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("DeleteIdleSince() = %d, want 1", removed)
+	}
+	sessions, err := store.ListByUser(ctx, "1")
+	if err != nil {
+		t.Fatalf("ListByUser() unexpected error: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "active" {
+		t.Errorf("ListByUser() = %+v, want only the active session", sessions)
+	}
+}
+
+func TestRedisSessionStore_Expiry(t *testing.T) {
+	client := openTestRedis(t)
+	store := repository.NewRedisSessionStore(client, time.Second)
+	ctx := context.Background()
+	if err := store.Create(ctx, newTestSession("a", "1", time.Now())); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	ttl, err := client.TTL(ctx, "vbwd:session:a").Result()
+	if err != nil {
+		t.Fatalf("TTL() unexpected error: %v", err)
+	}
+	if ttl <= 0 || ttl > time.Second {
+		t.Errorf("TTL = %v, want up to 1s", ttl)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := store.Touch(ctx, "a", time.Now()); !errors.Is(err, models.ErrSessionNotFound) {
+		t.Errorf("Touch() after expiry error = %v, want %v", err, models.ErrSessionNotFound)
+	}
+	if sessions, _ := store.ListByUser(ctx, "1"); len(sessions) != 0 {
+		t.Errorf("ListByUser() after expiry = %+v, want none", sessions)
+	}
+}
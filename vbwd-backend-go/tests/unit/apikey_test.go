@@ -0,0 +1,260 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// newAPIKeyRouter returns the test router with API keys enabled.
+func newAPIKeyRouter() http.Handler {
+	keys := services.NewAPIKeyService(repository.NewInMemoryAPIKeyStore(), repository.NewInMemoryUserRepository(services.DemoUser()), nil)
+	deps := newTestDependencies()
+	deps.TokenService = services.NewAPIKeyTokenService(deps.TokenService, keys)
+	deps.APIKeyHandler = handlers.NewAPIKeyHandler(keys)
+	return router.NewRouter(deps)
+}
+
+// sendWithCredentials sends a request authenticated with header set to
+// value, such as a bearer token or an API key.
+func sendWithCredentials(handler http.Handler, method, path, header, value, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if value != "" {
+		req.Header.Set(header, value)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// createAPIKey mints an API key through the router with an access token.
+func createAPIKey(t *testing.T, handler http.Handler, token string) models.APIKeyResponse {
+	t.Helper()
+
+	rec := sendWithCredentials(handler, http.MethodPost, "/apikeys", "Authorization", "Bearer "+token, `{"name":"ci"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d (body: %s)", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	var resp models.APIKeyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return resp
+}
+
+func TestAPIKeys_Create(t *testing.T) {
+	handler := newAPIKeyRouter()
+	token := loginForToken(t, handler, "admin", "password")
+
+	key := createAPIKey(t, handler, token)
+	if key.ID == "" || key.Name != "ci" || key.CreatedAt.IsZero() {
+		t.Errorf("response = %+v, want id, name and creation time", key)
+	}
+	if !strings.HasPrefix(key.Key, services.APIKeyPrefix) {
+		t.Errorf("key = %q, want prefix %q", key.Key, services.APIKeyPrefix)
+	}
+	if other := createAPIKey(t, handler, token); other.Key == key.Key {
+		t.Error("two keys are identical")
+	}
+}
+
+func TestAPIKeys_CreateErrors(t *testing.T) {
+	handler := newAPIKeyRouter()
+	token := loginForToken(t, handler, "admin", "password")
+	key := createAPIKey(t, handler, token)
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		body       string
+		wantStatus int
+	}{
+		{"anonymous", "Authorization", "", `{"name":"ci"}`, http.StatusUnauthorized},
+		{"missing name", "Authorization", "Bearer " + token, `{}`, http.StatusUnprocessableEntity},
+		{"with an API key", "X-API-Key", key.Key, `{"name":"ci"}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := sendWithCredentials(handler, http.MethodPost, "/apikeys", tt.header, tt.value, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	handler := newAPIKeyRouter()
+	key := createAPIKey(t, handler, loginForToken(t, handler, "admin", "password"))
+
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+	}{
+		{"valid key", key.Key, http.StatusOK},
+		{"unknown key", services.APIKeyPrefix + "unknown", http.StatusUnauthorized},
+		{"jwt in key header", signTestToken(t, services.Claims{Username: "admin", Role: models.RoleAdmin, TokenType: services.TokenTypeAccess}), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := sendWithCredentials(handler, http.MethodGet, "/whoami", "X-API-Key", tt.key, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var user models.UserDTO
+			if err := json.Unmarshal(rec.Body.Bytes(), &user); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if user.ID != "1" || user.Username != "admin" || user.Role != models.RoleAdmin {
+				t.Errorf("whoami = %+v, want the admin user", user)
+			}
+		})
+	}
+}
+
+func TestAPIKeys_CannotManageAccount(t *testing.T) {
+	keys := services.NewAPIKeyService(repository.NewInMemoryAPIKeyStore(), repository.NewInMemoryUserRepository(services.DemoUser()), nil)
+	deps := newTestDependencies()
+	deps.TokenService = services.NewAPIKeyTokenService(deps.TokenService, keys)
+	deps.APIKeyHandler = handlers.NewAPIKeyHandler(keys)
+	deps.SessionHandler = handlers.NewSessionHandler(services.NewSessionService(repository.NewInMemorySessionStore(), 0, nil))
+	mfa := services.NewMFAService(repository.NewInMemoryMFAStore(), "test-service", nil)
+	deps.MFAHandler = handlers.NewMFAHandler(mfa, newTestAuthService(deps.TokenService), deps.TokenService, nil)
+	handler := router.NewRouter(deps)
+
+	token := loginForToken(t, handler, "admin", "password")
+	key := createAPIKey(t, handler, token)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/apikeys", `{"name":"ci"}`},
+		{http.MethodDelete, "/apikeys/" + key.ID, ""},
+		{http.MethodPost, "/password", `{"old_password":"password","new_password":"N3w-Passw0rd!x"}`},
+		{http.MethodPost, "/mfa/enroll", ""},
+		{http.MethodPost, "/mfa/verify", `{"code":"123456"}`},
+		{http.MethodGet, "/sessions", ""},
+		{http.MethodDelete, "/sessions/some-session", ""},
+		{http.MethodPost, "/logout-all", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := sendWithCredentials(handler, tt.method, tt.path, "X-API-Key", key.Key, tt.body)
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, http.StatusForbidden, rec.Body.String())
+			}
+		})
+	}
+	if rec := sendWithCredentials(handler, http.MethodGet, "/whoami", "X-API-Key", key.Key, ""); rec.Code != http.StatusOK {
+		t.Errorf("whoami status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestAPIKeys_Revoke(t *testing.T) {
+	handler := newAPIKeyRouter()
+	token := loginForToken(t, handler, "admin", "password")
+	key := createAPIKey(t, handler, token)
+	kept := createAPIKey(t, handler, token)
+	bearer := "Bearer " + token
+
+	if rec := sendWithCredentials(handler, http.MethodDelete, "/apikeys/"+key.ID, "Authorization", bearer, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke status = %d, want %d (body: %s)", rec.Code, http.StatusNoContent, rec.Body.String())
+	}
+	if rec := sendWithCredentials(handler, http.MethodGet, "/whoami", "X-API-Key", key.Key, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := sendWithCredentials(handler, http.MethodGet, "/whoami", "X-API-Key", kept.Key, ""); rec.Code != http.StatusOK {
+		t.Errorf("other key status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec := sendWithCredentials(handler, http.MethodDelete, "/apikeys/"+key.ID, "Authorization", bearer, "")
+	assertErrorCode(t, rec, http.StatusNotFound, models.ErrAPIKeyNotFound)
+
+	otherUser := signTestToken(t, services.Claims{
+		Username:         "alice",
+		Role:             models.RoleUser,
+		TokenType:        services.TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "2"},
+	})
+	rec = sendWithCredentials(handler, http.MethodDelete, "/apikeys/"+kept.ID, "Authorization", "Bearer "+otherUser, "")
+	assertErrorCode(t, rec, http.StatusNotFound, models.ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyService_VerifyFollowsOwner(t *testing.T) {
+	ctx := context.Background()
+	alice := models.User{ID: "u-1", Username: "alice", Role: models.RoleUser}
+
+	tests := []struct {
+		name     string
+		deleted  bool
+		replace  *models.User
+		wantRole string
+		wantErr  error
+	}{
+		{name: "unchanged", wantRole: models.RoleUser},
+		{name: "deleted", deleted: true, wantErr: models.ErrInvalidToken},
+		{name: "promoted", deleted: true, replace: &models.User{ID: "u-1", Username: "alice", Role: models.RoleAdmin}, wantRole: models.RoleAdmin},
+		{name: "username reused", deleted: true, replace: &models.User{ID: "u-2", Username: "alice", Role: models.RoleUser}, wantErr: models.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := repository.NewInMemoryUserRepository(alice)
+			keys := services.NewAPIKeyService(repository.NewInMemoryAPIKeyStore(), users, nil)
+			key, err := keys.Create(ctx, alice, "ci")
+			if err != nil {
+				t.Fatalf("Create() unexpected error: %v", err)
+			}
+
+			if tt.deleted {
+				if err := users.Delete(ctx, alice.ID); err != nil {
+					t.Fatalf("Delete() unexpected error: %v", err)
+				}
+			}
+			if tt.replace != nil {
+				if err := users.Create(ctx, *tt.replace); err != nil {
+					t.Fatalf("Create() unexpected error: %v", err)
+				}
+			}
+
+			claims, err := keys.Verify(ctx, key.Key)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() unexpected error: %v", err)
+			}
+			if claims.Role != tt.wantRole || claims.Subject != alice.ID {
+				t.Errorf("claims = role %q subject %q, want role %q subject %q", claims.Role, claims.Subject, tt.wantRole, alice.ID)
+			}
+		})
+	}
+}
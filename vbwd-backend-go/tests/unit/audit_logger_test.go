@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+type loginRecord struct {
+	username string
+	success  bool
+	ip       string
+}
+
+// fakeAuditLogger collects login records.
+type fakeAuditLogger struct {
+	mu     sync.Mutex
+	logins []loginRecord
+}
+
+func (f *fakeAuditLogger) RecordLogin(ctx context.Context, username string, success bool, ip string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.logins = append(f.logins, loginRecord{username: username, success: success, ip: ip})
+}
+
+func TestAuthHandler_Login_RecordsAudit(t *testing.T) {
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+
+	tests := []struct {
+		name  string
+		login func(audit services.AuditLogger) http.HandlerFunc
+	}{
+		{"v1", func(audit services.AuditLogger) http.HandlerFunc {
+			return handlers.NewAuthHandler(newTestAuthService(tokenService), audit).Login
+		}},
+		{"v2", func(audit services.AuditLogger) http.HandlerFunc {
+			return handlers.NewAuthHandlerV2(newTestAuthService(tokenService), tokenService, audit).Login
+		}},
+	}
+
+	attempts := []struct {
+		body        string
+		wantSuccess bool
+	}{
+		{`{"username":"admin","password":"password"}`, true},
+		{`{"username":"admin","password":"wrong"}`, false},
+		{`{"username":"ghost","password":"password"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &fakeAuditLogger{}
+			login := tt.login(audit)
+
+			for _, attempt := range attempts {
+				req := newJSONRequest("/login", attempt.body)
+				req.RemoteAddr = "203.0.113.7:4321"
+				login(httptest.NewRecorder(), req)
+			}
+
+			if len(audit.logins) != len(attempts) {
+				t.Fatalf("recorded %d logins, want %d", len(audit.logins), len(attempts))
+			}
+			for i, attempt := range attempts {
+				got := audit.logins[i]
+				if got.success != attempt.wantSuccess {
+					t.Errorf("login %d success = %t, want %t", i, got.success, attempt.wantSuccess)
+				}
+				if got.ip != "203.0.113.7" {
+					t.Errorf("login %d ip = %q, want %q", i, got.ip, "203.0.113.7")
+				}
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Login_InvalidBodyNotAudited(t *testing.T) {
+	audit := &fakeAuditLogger{}
+	handler := handlers.NewAuthHandler(newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{})), audit)
+
+	req := newJSONRequest("/login", `{"username":"admin"}`)
+	handler.Login(httptest.NewRecorder(), req)
+
+	if len(audit.logins) != 0 {
+		t.Errorf("recorded %d logins, want 0", len(audit.logins))
+	}
+}
+
+func TestLogAuditLogger_RecordLogin(t *testing.T) {
+	var buf bytes.Buffer
+	audit := services.NewLogAuditLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	handler := handlers.NewAuthHandler(newTestAuthService(tokenService), audit)
+	req := newJSONRequest("/login", `{"username":"admin","password":"wrong-secret"}`)
+	req.RemoteAddr = "198.51.100.2:4321"
+	handler.Login(httptest.NewRecorder(), req)
+
+	if strings.Contains(buf.String(), "wrong-secret") {
+		t.Fatalf("audit entry contains the password: %s", buf.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decode audit entry: %v", err)
+	}
+	want := map[string]any{"msg": "audit", "event": "login", "username": "admin", "success": false, "ip": "198.51.100.2"}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("entry[%q] = %v, want %v", key, entry[key], value)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+func newTestAuthHandler() *handlers.AuthHandler {
+	return handlers.NewAuthHandler(newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{})), nil)
+}
+
+func TestAuthHandler_Register(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"success", `{"username":"alice","password":"S3cret-pass"}`, http.StatusCreated},
+		{"duplicate username", `{"username":"admin","password":"S3cret-pass"}`, http.StatusConflict},
+		{"weak password", `{"username":"bob","password":"short"}`, http.StatusUnprocessableEntity},
+		{"password fails policy", `{"username":"bob","password":"alllowercase"}`, http.StatusUnprocessableEntity},
+		{"invalid body", `{`, http.StatusBadRequest},
+	}
+
+	handler := newTestAuthHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newJSONRequest("/register", tt.body)
+			rec := httptest.NewRecorder()
+
+			handler.Register(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Login_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []string
+	}{
+		{"both empty", `{}`, []string{"username", "password"}},
+		{"username only", `{"username":"admin"}`, []string{"password"}},
+		{"password only", `{"password":"password"}`, []string{"username"}},
+	}
+
+	handler := newTestAuthHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			handler.Login(rec, newJSONRequest("/login", tt.body))
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+			}
+
+			var body struct {
+				Errors []models.FieldError `json:"errors"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+
+			var fields []string
+			for _, fe := range body.Errors {
+				fields = append(fields, fe.Field)
+				if fe.Message == "" || fe.Code == "" {
+					t.Errorf("field error %+v missing message or code", fe)
+				}
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Login_DecodeErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantMessage string
+	}{
+		{"empty body", ``, "Request body is required"},
+		{"syntax error", `{"username": admin}`, "Malformed JSON at offset 14"},
+		{"truncated", `{"username":"admin"`, "Malformed JSON: unexpected end of body"},
+		{"wrong field type", `{"username":42,"password":"password"}`, `Field "username" expected type string`},
+		{"not an object", `["admin","password"]`, "Request body must be a JSON object"},
+	}
+
+	handler := newTestAuthHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.Login(rec, newJSONRequest("/login", tt.body))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			var body response.ErrorEnvelope
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if body.Error.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", body.Error.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		withToken  bool
+		wantStatus int
+	}{
+		{"missing token", `{"old_password":"password","new_password":"N3w-password"}`, false, http.StatusUnauthorized},
+		{"wrong old password", `{"old_password":"wrong","new_password":"N3w-password"}`, true, http.StatusUnauthorized},
+		{"weak new password", `{"old_password":"password","new_password":"weak"}`, true, http.StatusUnprocessableEntity},
+		{"missing fields", `{}`, true, http.StatusUnprocessableEntity},
+		{"success", `{"old_password":"password","new_password":"N3w-password"}`, true, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestRouter()
+
+			req := httptest.NewRequest(http.MethodPost, "/password", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.withToken {
+				req.Header.Set("Authorization", "Bearer "+loginForToken(t, handler, "admin", "password"))
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Login_UnknownUserMatchesWrongPassword(t *testing.T) {
+	handler := newTestAuthHandler()
+
+	login := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.Login(rec, newJSONRequest("/login", body))
+		return rec
+	}
+
+	unknown := login(`{"username":"nobody","password":"password"}`)
+	wrong := login(`{"username":"admin","password":"wrong"}`)
+
+	if unknown.Code != http.StatusUnauthorized || wrong.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d (unknown user), %d (wrong password), want %d for both", unknown.Code, wrong.Code, http.StatusUnauthorized)
+	}
+	if unknown.Body.String() != wrong.Body.String() {
+		t.Errorf("body = %q (unknown user), %q (wrong password), want identical", unknown.Body.String(), wrong.Body.String())
+	}
+}
+
+func TestAuthHandler_Register_Email(t *testing.T) {
+	handler := newTestAuthHandler()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"with email", `{"username":"alice","email":"alice@example.com","password":"S3cret-pass"}`, http.StatusCreated},
+		{"malformed email", `{"username":"bob","email":"bob-at-example","password":"S3cret-pass"}`, http.StatusUnprocessableEntity},
+		{"duplicate email", `{"username":"carol","email":"ALICE@example.com","password":"S3cret-pass"}`, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.Register(rec, newJSONRequest("/register", tt.body))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_WhoAmI(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      func(t *testing.T, handler http.Handler) string
+		wantStatus int
+		wantUser   models.UserDTO
+	}{
+		{
+			name:       "authenticated",
+			token:      func(t *testing.T, handler http.Handler) string { return loginForToken(t, handler, "admin", "password") },
+			wantStatus: http.StatusOK,
+			wantUser:   models.UserDTO{ID: "1", Username: "admin", Role: models.RoleAdmin},
+		},
+		{
+			name:       "missing token",
+			token:      func(t *testing.T, handler http.Handler) string { return "" },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid token",
+			token:      func(t *testing.T, handler http.Handler) string { return "not-a-jwt" },
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestRouter()
+
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if token := tt.token(t, handler); token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got models.UserDTO
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got != tt.wantUser {
+				t.Errorf("user = %+v, want %+v", got, tt.wantUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+func newTestAuthHandlerV2() *handlers.AuthHandlerV2 {
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	return handlers.NewAuthHandlerV2(newTestAuthService(tokenService), tokenService, nil)
+}
+
+// postLogin sends valid admin credentials to path through the test router
+// and decodes the body into a generic map.
+func postLogin(t *testing.T, path string) map[string]any {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"username":"admin","password":"password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST %s status = %d, want %d (body: %s)", path, rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return body
+}
+
+func TestLogin_V1Shape(t *testing.T) {
+	for _, path := range []string{"/login", "/v1/login"} {
+		t.Run(path, func(t *testing.T) {
+			body := postLogin(t, path)
+
+			if _, ok := body["token"].(string); !ok {
+				t.Errorf("token = %#v, want a string", body["token"])
+			}
+			if _, ok := body["refresh_token"].(string); !ok {
+				t.Errorf("refresh_token = %#v, want a string", body["refresh_token"])
+			}
+		})
+	}
+}
+
+func TestLogin_V2Shape(t *testing.T) {
+	body := postLogin(t, "/v2/login")
+
+	for _, field := range []string{"token", "refresh_token"} {
+		token, ok := body[field].(map[string]any)
+		if !ok {
+			t.Errorf("%s = %#v, want an object", field, body[field])
+			continue
+		}
+		if value, _ := token["value"].(string); value == "" {
+			t.Errorf("%s.value is empty", field)
+		}
+		if typ := token["type"]; typ != models.TokenTypeBearer {
+			t.Errorf("%s.type = %v, want %q", field, typ, models.TokenTypeBearer)
+		}
+		expiresAt, _ := token["expires_at"].(string)
+		if exp, err := time.Parse(time.RFC3339, expiresAt); err != nil || !exp.After(time.Now()) {
+			t.Errorf("%s.expires_at = %q, want a future RFC 3339 time", field, expiresAt)
+		}
+	}
+}
+
+func TestAuthHandlerV2_Login_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"wrong password", `{"username":"admin","password":"wrong"}`, http.StatusUnauthorized, "INVALID_CREDENTIALS"},
+		{"missing fields", `{}`, http.StatusUnprocessableEntity, ""},
+	}
+
+	handler := newTestAuthHandlerV2()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.Login(rec, newJSONRequest("/v2/login", tt.body))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantCode != "" && !strings.Contains(rec.Body.String(), tt.wantCode) {
+				t.Errorf("body = %s, want code %s", rec.Body.String(), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestLogin_Grant(t *testing.T) {
+	tests := []struct {
+		path        string
+		wantRefresh bool
+	}{
+		{"/login", true},
+		{"/login?grant=access_refresh", true},
+		{"/login?grant=access_only", false},
+		{"/v2/login", true},
+		{"/v2/login?grant=access_only", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			body := postLogin(t, tt.path)
+
+			if body["token"] == nil {
+				t.Error("token is missing")
+			}
+			if _, ok := body["refresh_token"]; ok != tt.wantRefresh {
+				t.Errorf("refresh_token present = %t, want %t (body: %v)", ok, tt.wantRefresh, body)
+			}
+		})
+	}
+}
+
+func TestLogin_InvalidGrant(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v2/login?grant=offline", strings.NewReader(`{"username":"admin","password":"password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d (body: %s)", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), models.ErrInvalidGrant.Code) {
+		t.Errorf("body = %s, want code %s", rec.Body.String(), models.ErrInvalidGrant.Code)
+	}
+}
@@ -0,0 +1,135 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+func TestRequireAuth(t *testing.T) {
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+
+	valid, err := tokenService.Generate(models.User{ID: "1", Username: "admin"})
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	refresh, err := tokenService.GenerateRefresh(models.User{ID: "1", Username: "admin"})
+	if err != nil {
+		t.Fatalf("GenerateRefresh() unexpected error: %v", err)
+	}
+
+	expired := signTestToken(t, services.Claims{
+		Username:  "admin",
+		TokenType: services.TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-2 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+		},
+	})
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantCalled bool
+	}{
+		{"missing header", "", http.StatusUnauthorized, false},
+		{"wrong scheme", "Basic " + valid, http.StatusUnauthorized, false},
+		{"malformed token", "Bearer not-a-jwt", http.StatusUnauthorized, false},
+		{"expired token", "Bearer " + expired, http.StatusUnauthorized, false},
+		{"refresh token", "Bearer " + refresh, http.StatusUnauthorized, false},
+		{"valid token", "Bearer " + valid, http.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			next := func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				claims, ok := middleware.ClaimsFromContext(r.Context())
+				if !ok {
+					t.Fatal("ClaimsFromContext() ok = false, want true")
+				}
+				if claims.Subject != "1" {
+					t.Errorf("Subject = %q, want %q", claims.Subject, "1")
+				}
+				w.WriteHeader(http.StatusOK)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			middleware.RequireAuth(next, tokenService)(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if called != tt.wantCalled {
+				t.Errorf("next called = %v, want %v", called, tt.wantCalled)
+			}
+		})
+	}
+}
+
+func TestClaimsFromContext_Missing(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, ok := middleware.ClaimsFromContext(req.Context()); ok {
+		t.Error("ClaimsFromContext() ok = true, want false")
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	adminOnly := middleware.RequireAuth(middleware.RequireRole(models.RoleAdmin, okHandler), tokenService)
+
+	tests := []struct {
+		name       string
+		role       string
+		wantStatus int
+	}{
+		{"admin token", models.RoleAdmin, http.StatusOK},
+		{"user token", models.RoleUser, http.StatusForbidden},
+		{"no role", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tokenService.Generate(models.User{ID: "1", Username: "someone", Role: tt.role})
+			if err != nil {
+				t.Fatalf("Generate() unexpected error: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+
+			adminOnly(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRequireRole_WithoutClaims(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	middleware.RequireRole(models.RoleAdmin, okHandler)(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
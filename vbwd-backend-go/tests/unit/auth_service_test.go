@@ -0,0 +1,746 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// newTestAuthService creates an AuthService over an in-memory repository
+// seeded with the demo user.
+func newTestAuthService(tokenService services.TokenService) services.AuthService {
+	return services.NewAuthService(
+		services.WithRepository(repository.NewInMemoryUserRepository(services.DemoUser())),
+		services.WithTokenService(tokenService),
+		services.WithLogger(discardLogger()),
+	)
+}
+
+// discardLogger returns a logger that drops every entry.
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// fakeUserRepository returns canned results so tests can drive the service
+// through specific repository responses.
+type fakeUserRepository struct {
+	user *models.User
+	err  error
+}
+
+func (f *fakeUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	return f.user, f.err
+}
+
+func (f *fakeUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return f.user, f.err
+}
+
+func (f *fakeUserRepository) Create(ctx context.Context, user models.User) error {
+	return f.err
+}
+
+func (f *fakeUserRepository) BulkCreate(ctx context.Context, users []models.User) (int, []error) {
+	if f.err != nil {
+		return 0, []error{f.err}
+	}
+	return len(users), nil
+}
+
+func (f *fakeUserRepository) UpdatePassword(ctx context.Context, id, hash string) error {
+	return f.err
+}
+
+func (f *fakeUserRepository) Delete(ctx context.Context, id string) error {
+	return f.err
+}
+
+func (f *fakeUserRepository) List(ctx context.Context, offset, limit int) ([]models.User, error) {
+	if f.user == nil {
+		return nil, f.err
+	}
+	return []models.User{*f.user}, f.err
+}
+
+func (f *fakeUserRepository) Count(ctx context.Context) (int, error) {
+	if f.user == nil {
+		return 0, f.err
+	}
+	return 1, f.err
+}
+
+func TestAuthService_Authenticate_Success(t *testing.T) {
+	service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+
+	resp, err := service.Authenticate(context.Background(), "admin", "password")
+	if err != nil {
+		t.Fatalf("Authenticate() unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Error("Success = false, want true")
+	}
+	if resp.Token == "" {
+		t.Error("Token is empty")
+	}
+}
+
+func TestAuthService_Authenticate_Grant(t *testing.T) {
+	tests := []struct {
+		name        string
+		issue       bool
+		grant       string
+		wantRefresh bool
+	}{
+		{"default", true, "", true},
+		{"access only", true, models.GrantAccessOnly, false},
+		{"refresh disabled", false, "", false},
+		{"refresh disabled, requested", false, models.GrantAccessRefresh, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := services.NewAuthService(
+				services.WithRepository(repository.NewInMemoryUserRepository(services.DemoUser())),
+				services.WithLogger(discardLogger()),
+				services.WithRefreshTokens(tt.issue),
+			)
+
+			resp, err := service.Authenticate(services.WithGrant(context.Background(), tt.grant), "admin", "password")
+			if err != nil {
+				t.Fatalf("Authenticate() unexpected error: %v", err)
+			}
+			if resp.Token == "" {
+				t.Error("Token is empty")
+			}
+			if got := resp.RefreshToken != ""; got != tt.wantRefresh {
+				t.Errorf("refresh token issued = %t, want %t", got, tt.wantRefresh)
+			}
+		})
+	}
+}
+
+func TestAuthService_Authenticate_IssuesParsableJWT(t *testing.T) {
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	service := newTestAuthService(tokenService)
+
+	resp, err := service.Authenticate(context.Background(), "admin", "password")
+	if err != nil {
+		t.Fatalf("Authenticate() unexpected error: %v", err)
+	}
+
+	claims, err := tokenService.Parse(resp.Token)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if claims.Subject != "1" {
+		t.Errorf("Subject = %q, want %q", claims.Subject, "1")
+	}
+	if claims.Username != "admin" {
+		t.Errorf("Username = %q, want %q", claims.Username, "admin")
+	}
+	if claims.Role != models.RoleAdmin {
+		t.Errorf("Role = %q, want %q", claims.Role, models.RoleAdmin)
+	}
+}
+
+func TestAuthService_Authenticate_InvalidCredentials(t *testing.T) {
+	service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"wrong password", "admin", "wrong"},
+		{"unknown user", "nobody", "password"},
+		{"unknown user with dummy hash password", "nobody", "vbwd-dummy-password-for-timing"},
+		{"empty password", "admin", ""},
+		{"stored hash as password", "admin", "$2a$10$IO7BKADLhh9w3lXPsbTi9.A.uES8PXa3GciXZIuj0H0kF.1mouZ.a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := service.Authenticate(context.Background(), tt.username, tt.password)
+			if !errors.Is(err, models.ErrInvalidCredentials) {
+				t.Errorf("Authenticate() error = %v, want %v", err, models.ErrInvalidCredentials)
+			}
+			if resp != nil {
+				t.Errorf("Authenticate() resp = %v, want nil", resp)
+			}
+		})
+	}
+}
+
+func TestAuthService_Refresh_Success(t *testing.T) {
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	service := newTestAuthService(tokenService)
+
+	login, err := service.Authenticate(context.Background(), "admin", "password")
+	if err != nil {
+		t.Fatalf("Authenticate() unexpected error: %v", err)
+	}
+	if login.RefreshToken == "" {
+		t.Fatal("RefreshToken is empty")
+	}
+
+	resp, err := service.Refresh(context.Background(), login.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+
+	claims, err := tokenService.Parse(resp.Token)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if claims.TokenType != services.TokenTypeAccess {
+		t.Errorf("TokenType = %q, want %q", claims.TokenType, services.TokenTypeAccess)
+	}
+	if claims.Subject != "1" {
+		t.Errorf("Subject = %q, want %q", claims.Subject, "1")
+	}
+}
+
+func TestAuthService_Refresh_RejectsAccessToken(t *testing.T) {
+	service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+
+	login, err := service.Authenticate(context.Background(), "admin", "password")
+	if err != nil {
+		t.Fatalf("Authenticate() unexpected error: %v", err)
+	}
+
+	if _, err := service.Refresh(context.Background(), login.Token); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("Refresh() error = %v, want %v", err, models.ErrInvalidToken)
+	}
+}
+
+func TestAuthService_Refresh_RejectsExpiredToken(t *testing.T) {
+	service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+
+	expired := signTestToken(t, services.Claims{
+		Username:  "admin",
+		TokenType: services.TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-48 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-24 * time.Hour)),
+		},
+	})
+
+	if _, err := service.Refresh(context.Background(), expired); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("Refresh() error = %v, want %v", err, models.ErrInvalidToken)
+	}
+}
+
+func TestAuthService_Register_Success(t *testing.T) {
+	service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+
+	user, err := service.Register(context.Background(), "alice", "", "S3cret-pass")
+	if err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if user.ID == "" {
+		t.Error("ID is empty")
+	}
+	if user.Password == "S3cret-pass" {
+		t.Error("Password stored in plaintext")
+	}
+	if user.Role != models.RoleUser {
+		t.Errorf("Role = %q, want %q", user.Role, models.RoleUser)
+	}
+
+	if _, err := service.Authenticate(context.Background(), "alice", "S3cret-pass"); err != nil {
+		t.Errorf("Authenticate() after Register unexpected error: %v", err)
+	}
+}
+
+func TestAuthService_Register_DuplicateUsername(t *testing.T) {
+	service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+
+	if _, err := service.Register(context.Background(), "admin", "", "An0ther-pass"); !errors.Is(err, models.ErrUserExists) {
+		t.Errorf("Register() error = %v, want %v", err, models.ErrUserExists)
+	}
+}
+
+func TestAuthService_Authenticate_RepositoryResponses(t *testing.T) {
+	demo := services.DemoUser()
+	errDatabase := errors.New("database unavailable")
+
+	tests := []struct {
+		name     string
+		repo     *fakeUserRepository
+		password string
+		wantErr  error
+	}{
+		{"user found, correct password", &fakeUserRepository{user: &demo}, "password", nil},
+		{"user found, wrong password", &fakeUserRepository{user: &demo}, "wrong", models.ErrInvalidCredentials},
+		{"user not found", &fakeUserRepository{err: models.ErrUserNotFound}, "password", models.ErrInvalidCredentials},
+		{"repository failure", &fakeUserRepository{err: errDatabase}, "password", errDatabase},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := services.NewAuthService(services.WithRepository(tt.repo), services.WithTokenService(services.NewTokenService(testJWTSecret, services.TokenOptions{})), services.WithLogger(discardLogger()))
+
+			_, err := service.Authenticate(context.Background(), demo.Username, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthService_Authenticate_LocksAccountAfterFailures(t *testing.T) {
+	clock := newFakeClock(time.Now())
+	service := services.NewAuthService(
+		services.WithRepository(repository.NewInMemoryUserRepository(services.DemoUser())),
+		services.WithTokenService(services.NewTokenService(testJWTSecret, services.TokenOptions{})),
+		services.WithThrottler(services.NewLoginThrottler(3, 15*time.Minute, clock)),
+		services.WithLogger(discardLogger()),
+	)
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.Authenticate(context.Background(), "admin", "wrong"); !errors.Is(err, models.ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: error = %v, want %v", i+1, err, models.ErrInvalidCredentials)
+		}
+	}
+
+	if _, err := service.Authenticate(context.Background(), "admin", "password"); !errors.Is(err, models.ErrAccountLocked) {
+		t.Fatalf("Authenticate() while locked error = %v, want %v", err, models.ErrAccountLocked)
+	}
+
+	clock.Advance(15 * time.Minute)
+
+	if _, err := service.Authenticate(context.Background(), "admin", "password"); err != nil {
+		t.Errorf("Authenticate() after lockout window unexpected error: %v", err)
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldPassword string
+		newPassword string
+		wantErr     error
+	}{
+		{"wrong old password", "wrong", "N3w-password", models.ErrInvalidCredentials},
+		{"weak new password", "password", "weak", models.ErrWeakPassword},
+		{"success", "password", "N3w-password", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+
+			err := service.ChangePassword(context.Background(), "admin", "", tt.oldPassword, tt.newPassword)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if _, err := service.Authenticate(context.Background(), "admin", tt.newPassword); err != nil {
+				t.Errorf("Authenticate() with new password unexpected error: %v", err)
+			}
+			if _, err := service.Authenticate(context.Background(), "admin", tt.oldPassword); !errors.Is(err, models.ErrInvalidCredentials) {
+				t.Errorf("Authenticate() with old password error = %v, want %v", err, models.ErrInvalidCredentials)
+			}
+		})
+	}
+}
+
+func TestAuthService_Authenticate_HonorsCancellation(t *testing.T) {
+	service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := service.Authenticate(ctx, "admin", "password"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Authenticate() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestAuthService_Authenticate_LogsFailures(t *testing.T) {
+	const secretPassword = "Sup3r-secret-value"
+
+	tests := []struct {
+		name      string
+		repo      *fakeUserRepository
+		username  string
+		wantLevel string
+	}{
+		{name: "wrong password", repo: &fakeUserRepository{user: &models.User{ID: "1", Username: "admin", Password: "$2a$10$IO7BKADLhh9w3lXPsbTi9.A.uES8PXa3GciXZIuj0H0kF.1mouZ.a"}}, username: "admin", wantLevel: "WARN"},
+		{name: "unknown user", repo: &fakeUserRepository{err: models.ErrUserNotFound}, username: "nobody", wantLevel: "WARN"},
+		{name: "repository failure", repo: &fakeUserRepository{err: errors.New("connection reset")}, username: "admin", wantLevel: "ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+			service := services.NewAuthService(services.WithRepository(tt.repo), services.WithTokenService(services.NewTokenService(testJWTSecret, services.TokenOptions{})), services.WithLogger(logger))
+
+			if _, err := service.Authenticate(context.Background(), tt.username, secretPassword); err == nil {
+				t.Fatal("Authenticate() error = nil, want error")
+			}
+
+			entry := decodeLogEntry(t, &buf)
+			if entry["level"] != tt.wantLevel {
+				t.Errorf("level = %v, want %s", entry["level"], tt.wantLevel)
+			}
+			if entry["username"] != tt.username {
+				t.Errorf("username = %v, want %q", entry["username"], tt.username)
+			}
+			if strings.Contains(buf.String(), secretPassword) {
+				t.Errorf("log output contains the password: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestAuthService_Register_Email(t *testing.T) {
+	service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+
+	user, err := service.Register(context.Background(), "alice", " Alice@Example.com ", "S3cret-pass")
+	if err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if user.Email != "alice@example.com" {
+		t.Errorf("Email = %q, want %q", user.Email, "alice@example.com")
+	}
+
+	if _, err := service.Register(context.Background(), "bob", "ALICE@example.com", "S3cret-pass"); !errors.Is(err, models.ErrEmailExists) {
+		t.Errorf("Register() duplicate email error = %v, want %v", err, models.ErrEmailExists)
+	}
+}
+
+func TestAuthService_Authenticate_ByEmail(t *testing.T) {
+	service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+	if _, err := service.Register(context.Background(), "alice", "alice@example.com", "S3cret-pass"); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	for _, login := range []string{"alice", "alice@example.com", "Alice@Example.com"} {
+		if _, err := service.Authenticate(context.Background(), login, "S3cret-pass"); err != nil {
+			t.Errorf("Authenticate(%q) unexpected error: %v", login, err)
+		}
+	}
+	if _, err := service.Authenticate(context.Background(), "alice@example.com", "wrong"); !errors.Is(err, models.ErrInvalidCredentials) {
+		t.Errorf("Authenticate() by email with wrong password error = %v, want %v", err, models.ErrInvalidCredentials)
+	}
+}
+
+func TestAuthService_Argon2id_AcceptsLegacyBcryptHash(t *testing.T) {
+	hasher, err := services.NewArgon2idHasher(testArgon2Params)
+	if err != nil {
+		t.Fatalf("NewArgon2idHasher() unexpected error: %v", err)
+	}
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	service := services.NewAuthService(services.WithRepository(repository.NewInMemoryUserRepository(services.DemoUser())), services.WithTokenService(tokenService), services.WithHasher(hasher), services.WithLogger(discardLogger()))
+	ctx := context.Background()
+
+	// The demo user's password is a bcrypt hash.
+	if _, err := service.Authenticate(ctx, "admin", "password"); err != nil {
+		t.Fatalf("Authenticate(bcrypt user) unexpected error: %v", err)
+	}
+
+	if _, err := service.Register(ctx, "alice", "", "S3cret-pass"); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if _, err := service.Authenticate(ctx, "alice", "S3cret-pass"); err != nil {
+		t.Errorf("Authenticate(argon2id user) unexpected error: %v", err)
+	}
+	if _, err := service.Authenticate(ctx, "alice", "wrong"); !errors.Is(err, models.ErrInvalidCredentials) {
+		t.Errorf("Authenticate(wrong password) error = %v, want %v", err, models.ErrInvalidCredentials)
+	}
+}
+
+// newRehashTestService returns an AuthService using hasher over a
+// repository holding alice, whose password "S3cret-pass" is hashed with
+// bcrypt at the minimum cost.
+func newRehashTestService(t *testing.T, hasher services.Hasher) (services.AuthService, repository.UserRepository) {
+	t.Helper()
+
+	oldHash, err := bcrypt.GenerateFromPassword([]byte("S3cret-pass"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() unexpected error: %v", err)
+	}
+	repo := repository.NewInMemoryUserRepository(models.User{ID: "42", Username: "alice", Password: string(oldHash), Role: models.RoleUser})
+	service := services.NewAuthService(services.WithRepository(repo), services.WithTokenService(services.NewTokenService(testJWTSecret, services.TokenOptions{})), services.WithHasher(hasher), services.WithLogger(discardLogger()))
+	return service, repo
+}
+
+func TestAuthService_Authenticate_RehashesOutdatedHash(t *testing.T) {
+	bcryptHasher, err := services.NewBcryptHasher(bcrypt.MinCost + 1)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() unexpected error: %v", err)
+	}
+	argonHasher, err := services.NewArgon2idHasher(testArgon2Params)
+	if err != nil {
+		t.Fatalf("NewArgon2idHasher() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		hasher interface {
+			services.Hasher
+			services.Rehasher
+		}
+	}{
+		{"higher bcrypt cost", bcryptHasher},
+		{"bcrypt to argon2id", argonHasher},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newRehashTestService(t, tt.hasher)
+			ctx := context.Background()
+
+			if _, err := service.Authenticate(ctx, "alice", "S3cret-pass"); err != nil {
+				t.Fatalf("Authenticate() unexpected error: %v", err)
+			}
+
+			user, err := repo.FindByUsername(ctx, "alice")
+			if err != nil {
+				t.Fatalf("FindByUsername() unexpected error: %v", err)
+			}
+			if tt.hasher.NeedsRehash(user.Password) {
+				t.Errorf("stored hash %q was not upgraded", user.Password)
+			}
+			assertCompare(t, tt.hasher, user.Password, "S3cret-pass", true)
+			if _, err := service.Authenticate(ctx, "alice", "S3cret-pass"); err != nil {
+				t.Errorf("Authenticate() after rehash unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_Authenticate_KeepsCurrentHash(t *testing.T) {
+	hasher, err := services.NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() unexpected error: %v", err)
+	}
+	service, repo := newRehashTestService(t, hasher)
+	ctx := context.Background()
+
+	before, _ := repo.FindByUsername(ctx, "alice")
+	if _, err := service.Authenticate(ctx, "alice", "S3cret-pass"); err != nil {
+		t.Fatalf("Authenticate() unexpected error: %v", err)
+	}
+	after, _ := repo.FindByUsername(ctx, "alice")
+
+	if after.Password != before.Password {
+		t.Error("hash changed although it already uses the current cost")
+	}
+}
+
+func TestNewAuthService_Defaults(t *testing.T) {
+	service := services.NewAuthService()
+	ctx := context.Background()
+
+	if _, err := service.Register(ctx, "alice", "", "weak"); !errors.Is(err, models.ErrWeakPassword) {
+		t.Errorf("Register(weak) error = %v, want %v (default policy)", err, models.ErrWeakPassword)
+	}
+	if _, err := service.Register(ctx, "alice", "", "S3cret-pass"); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	resp, err := service.Authenticate(ctx, "alice", "S3cret-pass")
+	if err != nil {
+		t.Fatalf("Authenticate() unexpected error: %v", err)
+	}
+	if resp.Token == "" || resp.RefreshToken == "" {
+		t.Error("Authenticate() issued no tokens with the default token service")
+	}
+	if _, err := service.Authenticate(ctx, "admin", "password"); !errors.Is(err, models.ErrInvalidCredentials) {
+		t.Errorf("Authenticate(admin) error = %v, want %v (default repository is empty)", err, models.ErrInvalidCredentials)
+	}
+
+	for i := 0; i < services.DefaultMaxFailedAttempts; i++ {
+		_, _ = service.Authenticate(ctx, "alice", "wrong")
+	}
+	if _, err := service.Authenticate(ctx, "alice", "S3cret-pass"); !errors.Is(err, models.ErrAccountLocked) {
+		t.Errorf("Authenticate() after failures error = %v, want %v (default throttler)", err, models.ErrAccountLocked)
+	}
+}
+
+func TestNewAuthService_Options(t *testing.T) {
+	argon2, err := services.NewArgon2idHasher(testArgon2Params)
+	if err != nil {
+		t.Fatalf("NewArgon2idHasher() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		opts       []services.AuthOption
+		password   string
+		wantPrefix string
+	}{
+		{"default hasher", nil, "S3cret-pass", "$2a$"},
+		{"nil hasher falls back to default", []services.AuthOption{services.WithHasher(nil)}, "S3cret-pass", "$2a$"},
+		{"argon2id hasher", []services.AuthOption{services.WithHasher(argon2)}, "S3cret-pass", "$argon2id$"},
+		{"relaxed policy", []services.AuthOption{services.WithPasswordPolicy(services.PasswordPolicy{MinLength: 4})}, "abcd", "$2a$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewInMemoryUserRepository()
+			service := services.NewAuthService(append(tt.opts, services.WithRepository(repo))...)
+
+			user, err := service.Register(context.Background(), "alice", "", tt.password)
+			if err != nil {
+				t.Fatalf("Register() unexpected error: %v", err)
+			}
+			stored, err := repo.FindByUsername(context.Background(), "alice")
+			if err != nil {
+				t.Fatalf("FindByUsername() unexpected error: %v", err)
+			}
+			if stored.ID != user.ID || !strings.HasPrefix(stored.Password, tt.wantPrefix) {
+				t.Errorf("stored user = %+v, want ID %q and hash prefix %q", stored, user.ID, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestNewAuthService_WithTokenServiceAndLogger(t *testing.T) {
+	var buf bytes.Buffer
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	service := services.NewAuthService(
+		services.WithRepository(repository.NewInMemoryUserRepository(services.DemoUser())),
+		services.WithTokenService(tokenService),
+		services.WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+	)
+
+	resp, err := service.Authenticate(context.Background(), "admin", "password")
+	if err != nil {
+		t.Fatalf("Authenticate() unexpected error: %v", err)
+	}
+	if _, err := tokenService.Parse(resp.Token); err != nil {
+		t.Errorf("token not issued by the configured service: %v", err)
+	}
+
+	_, _ = service.Authenticate(context.Background(), "admin", "wrong")
+	if !strings.Contains(buf.String(), "login failed") {
+		t.Errorf("configured logger got %q, want a login failed entry", buf.String())
+	}
+}
+
+func TestAuthService_OnLoginSuccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    func(ctx context.Context, user models.User) error
+		wantLog string
+	}{
+		{name: "succeeds", hook: func(context.Context, models.User) error { return nil }},
+		{name: "returns error", hook: func(context.Context, models.User) error { return errors.New("event bus down") }, wantLog: "login hook failed"},
+		{name: "panics", hook: func(context.Context, models.User) error { panic("nil map") }, wantLog: "login hook panicked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var got []models.User
+			service := services.NewAuthService(
+				services.WithRepository(repository.NewInMemoryUserRepository(services.DemoUser())),
+				services.WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+				services.WithOnLoginSuccess(func(ctx context.Context, user models.User) error {
+					got = append(got, user)
+					return tt.hook(ctx, user)
+				}),
+			)
+
+			resp, err := service.Authenticate(context.Background(), "admin", "password")
+			if err != nil {
+				t.Fatalf("Authenticate() unexpected error: %v", err)
+			}
+			if !resp.Success || resp.Token == "" {
+				t.Errorf("response = %+v, want a successful login with a token", resp)
+			}
+			if len(got) != 1 || got[0].ID != "1" || got[0].Username != "admin" || got[0].Role != models.RoleAdmin {
+				t.Fatalf("hook users = %+v, want the admin user once", got)
+			}
+			if got[0].Password != "" {
+				t.Error("hook user carries the password hash")
+			}
+			if tt.wantLog == "" {
+				if buf.Len() != 0 {
+					t.Errorf("log = %q, want nothing", buf.String())
+				}
+				return
+			}
+			if !strings.Contains(buf.String(), tt.wantLog) {
+				t.Errorf("log = %q, want %q", buf.String(), tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestAuthService_OnLoginSuccess_NotCalledOnFailure(t *testing.T) {
+	called := false
+	service := services.NewAuthService(
+		services.WithRepository(repository.NewInMemoryUserRepository(services.DemoUser())),
+		services.WithLogger(discardLogger()),
+		services.WithOnLoginSuccess(func(context.Context, models.User) error {
+			called = true
+			return nil
+		}),
+	)
+
+	if _, err := service.Authenticate(context.Background(), "admin", "wrong"); !errors.Is(err, models.ErrInvalidCredentials) {
+		t.Fatalf("Authenticate() error = %v, want %v", err, models.ErrInvalidCredentials)
+	}
+	if called {
+		t.Error("hook called for a failed login")
+	}
+}
+
+func TestAuthService_ChangePasswordRevokesOtherCredentials(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewInMemoryUserRepository(services.DemoUser())
+	sessions := services.NewSessionService(repository.NewInMemorySessionStore(), 0, nil)
+	keys := services.NewAPIKeyService(repository.NewInMemoryAPIKeyStore(), users, nil)
+	service := services.NewAuthService(
+		services.WithRepository(users),
+		services.WithSessions(sessions),
+		services.WithAPIKeys(keys),
+		services.WithLogger(discardLogger()),
+	)
+
+	current, err := sessions.Start(ctx, "1", "laptop")
+	if err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+	if _, err := sessions.Start(ctx, "1", "attacker"); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+	key, err := keys.Create(ctx, services.DemoUser(), "stolen")
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	if err := service.ChangePassword(ctx, "admin", current.ID, "password", "N3w-password"); err != nil {
+		t.Fatalf("ChangePassword() unexpected error: %v", err)
+	}
+
+	left, err := sessions.List(ctx, "1")
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(left) != 1 || left[0].ID != current.ID {
+		t.Errorf("sessions after change = %v, want only the current one", left)
+	}
+	if _, err := keys.Verify(ctx, key.Key); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("Verify() after change error = %v, want %v", err, models.ErrInvalidToken)
+	}
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+func TestMaxBodySize_OversizedLoginReturns413(t *testing.T) {
+	handler := middleware.MaxBodySize(64)(http.HandlerFunc(newTestAuthHandler().Login))
+
+	body := fmt.Sprintf(`{"username":"admin","password":%q}`, strings.Repeat("x", 128))
+	req := newJSONRequest("/login", body)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestMaxBodySize_WithinLimitPassesThrough(t *testing.T) {
+	handler := middleware.MaxBodySize(1024)(http.HandlerFunc(newTestAuthHandler().Login))
+
+	req := newJSONRequest("/login", `{"username":"admin","password":"password"}`)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRouter_AppliesBodyLimit(t *testing.T) {
+	handler := router.NewRouter(router.Dependencies{
+		AuthHandler:    newTestAuthHandler(),
+		HealthHandler:  handlers.NewHealthHandler(services.NewHealthService("test-service", "test", time.Now(), nil)),
+		VersionHandler: handlers.NewVersionHandler("1.2.3", "abc1234", "2026-01-18T12:00:00Z"),
+		TokenService:   services.NewTokenService(testJWTSecret, services.TokenOptions{}),
+		MaxBodyBytes:   32,
+	})
+
+	body := fmt.Sprintf(`{"username":%q,"password":"S3cret-pass"}`, strings.Repeat("a", 64))
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
@@ -0,0 +1,168 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+)
+
+// countingUserRepository counts the lookups that reach the wrapped
+// repository.
+type countingUserRepository struct {
+	repository.UserRepository
+	lookups atomic.Int32
+}
+
+func (r *countingUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.lookups.Add(1)
+	return r.UserRepository.FindByUsername(ctx, username)
+}
+
+func (r *countingUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.lookups.Add(1)
+	return r.UserRepository.FindByEmail(ctx, email)
+}
+
+// newCachingTestRepository returns a cache over alice and bob together with
+// the counting backing repository and the cache clock.
+func newCachingTestRepository(capacity int) (repository.UserRepository, *countingUserRepository, *fakeClock) {
+	backing := &countingUserRepository{UserRepository: repository.NewInMemoryUserRepository(
+		models.User{ID: "1", Username: "alice", Email: "alice@example.com", Password: "hash-1"},
+		models.User{ID: "2", Username: "bob", Email: "bob@example.com", Password: "hash-2"},
+	)}
+	clock := newFakeClock(time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC))
+	repo := repository.NewCachingUserRepository(backing, repository.CacheOptions{
+		Capacity: capacity,
+		TTL:      time.Minute,
+		Now:      clock.Now,
+	})
+	return repo, backing, clock
+}
+
+func TestCachingUserRepository_HitsAvoidBackingStore(t *testing.T) {
+	repo, backing, _ := newCachingTestRepository(10)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		user, err := repo.FindByUsername(ctx, "alice")
+		if err != nil {
+			t.Fatalf("FindByUsername() unexpected error: %v", err)
+		}
+		if user.ID != "1" {
+			t.Errorf("ID = %q, want %q", user.ID, "1")
+		}
+		user.Password = "mutated by caller"
+	}
+	if _, err := repo.FindByEmail(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("FindByEmail() unexpected error: %v", err)
+	}
+	if _, err := repo.FindByEmail(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("FindByEmail() unexpected error: %v", err)
+	}
+
+	if got := backing.lookups.Load(); got != 2 {
+		t.Errorf("backing lookups = %d, want 2", got)
+	}
+	user, _ := repo.FindByUsername(ctx, "alice")
+	if user.Password != "hash-1" {
+		t.Errorf("cached Password = %q, want %q", user.Password, "hash-1")
+	}
+}
+
+func TestCachingUserRepository_MissesAreNotCached(t *testing.T) {
+	repo, backing, _ := newCachingTestRepository(10)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.FindByUsername(ctx, "carol"); !errors.Is(err, models.ErrUserNotFound) {
+			t.Fatalf("FindByUsername() error = %v, want %v", err, models.ErrUserNotFound)
+		}
+	}
+	if got := backing.lookups.Load(); got != 2 {
+		t.Errorf("backing lookups = %d, want 2", got)
+	}
+}
+
+func TestCachingUserRepository_TTLExpiry(t *testing.T) {
+	repo, backing, clock := newCachingTestRepository(10)
+	ctx := context.Background()
+
+	repo.FindByUsername(ctx, "alice")
+	clock.Advance(59 * time.Second)
+	repo.FindByUsername(ctx, "alice")
+	if got := backing.lookups.Load(); got != 1 {
+		t.Fatalf("backing lookups before expiry = %d, want 1", got)
+	}
+
+	clock.Advance(time.Second)
+	repo.FindByUsername(ctx, "alice")
+	if got := backing.lookups.Load(); got != 2 {
+		t.Errorf("backing lookups after expiry = %d, want 2", got)
+	}
+}
+
+func TestCachingUserRepository_LRUEviction(t *testing.T) {
+	repo, backing, _ := newCachingTestRepository(1)
+	ctx := context.Background()
+
+	repo.FindByUsername(ctx, "alice")
+	repo.FindByUsername(ctx, "bob")
+	repo.FindByUsername(ctx, "bob")
+	if got := backing.lookups.Load(); got != 2 {
+		t.Fatalf("backing lookups = %d, want 2", got)
+	}
+
+	repo.FindByUsername(ctx, "alice")
+	if got := backing.lookups.Load(); got != 3 {
+		t.Errorf("backing lookups after eviction = %d, want 3", got)
+	}
+}
+
+func TestCachingUserRepository_MutationsInvalidate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("UpdatePassword", func(t *testing.T) {
+		repo, backing, _ := newCachingTestRepository(10)
+		repo.FindByUsername(ctx, "alice")
+		repo.FindByEmail(ctx, "alice@example.com")
+		repo.FindByUsername(ctx, "bob")
+
+		if err := repo.UpdatePassword(ctx, "1", "hash-new"); err != nil {
+			t.Fatalf("UpdatePassword() unexpected error: %v", err)
+		}
+
+		for _, find := range []func() (*models.User, error){
+			func() (*models.User, error) { return repo.FindByUsername(ctx, "alice") },
+			func() (*models.User, error) { return repo.FindByEmail(ctx, "alice@example.com") },
+		} {
+			user, err := find()
+			if err != nil {
+				t.Fatalf("find unexpected error: %v", err)
+			}
+			if user.Password != "hash-new" {
+				t.Errorf("Password = %q, want %q", user.Password, "hash-new")
+			}
+		}
+		repo.FindByUsername(ctx, "bob")
+		if got := backing.lookups.Load(); got != 5 {
+			t.Errorf("backing lookups = %d, want 5 (bob stays cached)", got)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo, _, _ := newCachingTestRepository(10)
+		repo.FindByUsername(ctx, "alice")
+
+		if err := repo.Delete(ctx, "1"); err != nil {
+			t.Fatalf("Delete() unexpected error: %v", err)
+		}
+		if _, err := repo.FindByUsername(ctx, "alice"); !errors.Is(err, models.ErrUserNotFound) {
+			t.Errorf("FindByUsername() error = %v, want %v", err, models.ErrUserNotFound)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+)
+
+// recordingMiddleware appends "<name> before" and "<name> after" to calls
+// around the wrapped handler.
+func recordingMiddleware(name string, calls *[]string) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls = append(*calls, name+" before")
+			next.ServeHTTP(w, r)
+			*calls = append(*calls, name+" after")
+		})
+	}
+}
+
+func TestChain_AppliesInOrder(t *testing.T) {
+	var calls []string
+	final := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+	})
+
+	handler := middleware.Chain(
+		recordingMiddleware("first", &calls),
+		recordingMiddleware("second", &calls),
+		recordingMiddleware("third", &calls),
+	).Then(final)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := []string{"first before", "second before", "third before", "handler", "third after", "second after", "first after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestChain_Nested(t *testing.T) {
+	var calls []string
+	final := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+	})
+
+	inner := middleware.Chain(recordingMiddleware("b", &calls), recordingMiddleware("c", &calls))
+	middleware.Chain(recordingMiddleware("a", &calls), inner).Then(final).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := []string{"a before", "b before", "c before", "handler", "c after", "b after", "a after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestChain_Empty(t *testing.T) {
+	rec := httptest.NewRecorder()
+	middleware.Chain().Then(http.HandlerFunc(okHandler)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,127 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// readyz calls the readiness handler and decodes its response.
+func readyz(t *testing.T, handler *handlers.HealthHandler) (int, models.ReadinessResponse) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var resp models.ReadinessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode readiness: %v", err)
+	}
+	return rec.Code, resp
+}
+
+// checkNames returns the names of the reported checks.
+func checkNames(resp models.ReadinessResponse) string {
+	names := make([]string, len(resp.Checks))
+	for i, check := range resp.Checks {
+		names[i] = check.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestCheckRegistry_RegisterAfterStartup(t *testing.T) {
+	service := services.NewHealthService("test-service", "test", time.Now(), nil)
+	service.RegisterCheck("database", func(ctx context.Context) error { return nil })
+	handler := handlers.NewHealthHandler(service)
+
+	if status, resp := readyz(t, handler); status != http.StatusOK || checkNames(resp) != "database" {
+		t.Fatalf("before registration: status %d, checks %q", status, checkNames(resp))
+	}
+
+	// A subsystem started later adds its own probe through the shared
+	// registry.
+	registry := service.Checks()
+	registry.Register("queue", func(ctx context.Context) error { return errors.New("broker unreachable") })
+
+	status, resp := readyz(t, handler)
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+	if got := checkNames(resp); got != "database,queue" {
+		t.Errorf("checks = %q, want %q", got, "database,queue")
+	}
+	if resp.Checks[1].Error != "broker unreachable" {
+		t.Errorf("queue Error = %q, want %q", resp.Checks[1].Error, "broker unreachable")
+	}
+
+	if !registry.Deregister("queue") {
+		t.Error("Deregister(queue) = false, want true")
+	}
+	if registry.Deregister("queue") {
+		t.Error("second Deregister(queue) = true, want false")
+	}
+	if status, resp := readyz(t, handler); status != http.StatusOK || checkNames(resp) != "database" {
+		t.Errorf("after deregistration: status %d, checks %q", status, checkNames(resp))
+	}
+}
+
+func TestCheckRegistry_ReplaceKeepsPosition(t *testing.T) {
+	registry := services.NewCheckRegistry()
+	registry.Register("database", func(ctx context.Context) error { return nil })
+	registry.Register("cache", func(ctx context.Context) error { return nil })
+	registry.Register("database", func(ctx context.Context) error { return errors.New("down") })
+
+	if got := strings.Join(registry.Names(), ","); got != "database,cache" {
+		t.Errorf("Names() = %q, want %q", got, "database,cache")
+	}
+}
+
+func TestCheckRegistry_ChangesBypassReadinessCache(t *testing.T) {
+	clock := newFakeClock(time.Now())
+	service := services.NewHealthService("test-service", "test", clock.Now(), clock)
+	service.SetReadinessCacheTTL(time.Minute)
+	service.RegisterCheck("database", func(ctx context.Context) error { return nil })
+
+	if resp := service.GetReadiness(context.Background()); !resp.Ready {
+		t.Fatal("Ready = false, want true")
+	}
+	service.Checks().Register("queue", func(ctx context.Context) error { return errors.New("down") })
+
+	if resp := service.GetReadiness(context.Background()); resp.Ready || len(resp.Checks) != 2 {
+		t.Errorf("readiness = %+v, want the new failing check despite the cache", resp)
+	}
+}
+
+func TestCheckRegistry_ConcurrentUse(t *testing.T) {
+	service := services.NewHealthService("test-service", "test", time.Now(), nil)
+	registry := service.Checks()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			registry.Register("flaky", func(ctx context.Context) error { return nil })
+			registry.Deregister("flaky")
+		}()
+		go func() {
+			defer wg.Done()
+			service.GetReadiness(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if names := registry.Names(); len(names) != 0 {
+		t.Errorf("Names() = %v, want none", names)
+	}
+}
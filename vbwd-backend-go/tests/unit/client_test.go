@@ -0,0 +1,432 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/client"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// newTestClient starts the real router behind an httptest.Server and
+// returns a client pointed at it.
+func newTestClient(t *testing.T, opts ...client.Option) *client.Client {
+	t.Helper()
+
+	server := httptest.NewServer(newTestRouter())
+	t.Cleanup(server.Close)
+
+	return client.NewClient(server.URL, append([]client.Option{client.WithHTTPClient(server.Client())}, opts...)...)
+}
+
+func TestClient_Login(t *testing.T) {
+	c := newTestClient(t)
+
+	resp, err := c.Login(context.Background(), "admin", "password")
+	if err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Error("Success = false, want true")
+	}
+	if resp.Token == "" || resp.RefreshToken == "" {
+		t.Errorf("Login() tokens = %q, %q, want both set", resp.Token, resp.RefreshToken)
+	}
+}
+
+func TestClient_Login_InvalidCredentials(t *testing.T) {
+	c := newTestClient(t)
+
+	_, err := c.Login(context.Background(), "admin", "wrong")
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Login() error = %v, want *client.APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, http.StatusUnauthorized)
+	}
+	if apiErr.Code != string(response.CodeUnauthorized) {
+		t.Errorf("Code = %q, want %q", apiErr.Code, response.CodeUnauthorized)
+	}
+	if apiErr.Message != "Invalid credentials" {
+		t.Errorf("Message = %q, want %q", apiErr.Message, "Invalid credentials")
+	}
+}
+
+func TestClient_Login_ValidationError(t *testing.T) {
+	c := newTestClient(t)
+
+	_, err := c.Login(context.Background(), "", "")
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Login() error = %v, want *client.APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, http.StatusUnprocessableEntity)
+	}
+	if len(apiErr.Fields) != 2 {
+		t.Errorf("len(Fields) = %d, want 2", len(apiErr.Fields))
+	}
+}
+
+func TestClient_Health(t *testing.T) {
+	c := newTestClient(t)
+
+	resp, err := c.Health(context.Background())
+	if err != nil {
+		t.Fatalf("Health() unexpected error: %v", err)
+	}
+	if resp.Status != "healthy" {
+		t.Errorf("Status = %q, want %q", resp.Status, "healthy")
+	}
+	if resp.Service != "test-service" {
+		t.Errorf("Service = %q, want %q", resp.Service, "test-service")
+	}
+}
+
+func TestClient_ErrorEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.Error(w, http.StatusServiceUnavailable, "Down for maintenance")
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := client.NewClient(server.URL).Health(context.Background())
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Health() error = %v, want *client.APIError", err)
+	}
+	want := client.APIError{
+		StatusCode: http.StatusServiceUnavailable,
+		Code:       string(response.CodeServiceUnavailable),
+		Message:    "Down for maintenance",
+	}
+	if apiErr.StatusCode != want.StatusCode || apiErr.Code != want.Code || apiErr.Message != want.Message {
+		t.Errorf("APIError = %+v, want %+v", *apiErr, want)
+	}
+}
+
+func TestClient_SetToken_SendsBearerToken(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	c := client.NewClient(server.URL)
+	c.SetToken("abc")
+
+	if err := c.ChangePassword(context.Background(), "old", "new"); err != nil {
+		t.Fatalf("ChangePassword() unexpected error: %v", err)
+	}
+	if got != "Bearer abc" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer abc")
+	}
+}
+
+func TestClient_AutoRefresh_RetriesAfter401(t *testing.T) {
+	c := newTestClient(t, client.WithAutoRefresh())
+
+	if _, err := c.Login(context.Background(), "admin", "password"); err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+	c.SetToken("stale-token")
+
+	if err := c.ChangePassword(context.Background(), "password", "N3w-password"); err != nil {
+		t.Fatalf("ChangePassword() unexpected error: %v", err)
+	}
+	if c.Token() == "stale-token" {
+		t.Error("Token() was not refreshed")
+	}
+}
+
+func TestClient_AutoRefresh_Persistent401(t *testing.T) {
+	var refreshes, attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/refresh":
+			refreshes++
+			response.JSON(w, http.StatusOK, map[string]any{"success": true, "token": "fresh-token"})
+		default:
+			attempts++
+			response.Error(w, http.StatusUnauthorized, "Invalid or expired token")
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c := client.NewClient(server.URL, client.WithAutoRefresh())
+	c.SetToken("stale-token")
+	c.SetRefreshToken("refresh-token")
+
+	err := c.ChangePassword(context.Background(), "password", "N3w-password")
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("ChangePassword() error = %v, want 401 *client.APIError", err)
+	}
+	if refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", refreshes)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestClient_WithoutAutoRefresh_Returns401(t *testing.T) {
+	c := newTestClient(t)
+
+	if _, err := c.Login(context.Background(), "admin", "password"); err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+	c.SetToken("stale-token")
+
+	err := c.ChangePassword(context.Background(), "password", "N3w-password")
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("ChangePassword() error = %v, want 401 *client.APIError", err)
+	}
+}
+
+// rateLimitedServer answers the first failures requests with 429 and the
+// given Retry-After header, then serves the real router.
+func rateLimitedServer(t *testing.T, failures int, retryAfter string) (*httptest.Server, *[]time.Time) {
+	t.Helper()
+
+	var attempts []time.Time
+	next := newTestRouter()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts = append(attempts, time.Now())
+		if len(attempts) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			response.Error(w, http.StatusTooManyRequests, "Too many requests")
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+func TestClient_Retry_BacksOffOn429(t *testing.T) {
+	server, attempts := rateLimitedServer(t, 2, "")
+	policy := client.RetryPolicy{MaxRetries: 3, BaseDelay: 20 * time.Millisecond, MaxDelay: time.Second}
+	c := client.NewClient(server.URL, client.WithRetryPolicy(policy))
+
+	if _, err := c.Health(context.Background()); err != nil {
+		t.Fatalf("Health() unexpected error: %v", err)
+	}
+	if len(*attempts) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(*attempts))
+	}
+	// Equal jitter waits at least half of the doubling backoff.
+	for i, min := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond} {
+		if gap := (*attempts)[i+1].Sub((*attempts)[i]); gap < min {
+			t.Errorf("delay before retry %d = %v, want >= %v", i+1, gap, min)
+		}
+	}
+}
+
+func TestClient_Retry_RespectsRetryAfter(t *testing.T) {
+	server, attempts := rateLimitedServer(t, 1, "1")
+	c := client.NewClient(server.URL, client.WithRetryPolicy(client.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second}))
+
+	if _, err := c.Health(context.Background()); err != nil {
+		t.Fatalf("Health() unexpected error: %v", err)
+	}
+	if len(*attempts) != 2 {
+		t.Fatalf("attempts = %d, want 2", len(*attempts))
+	}
+	if gap := (*attempts)[1].Sub((*attempts)[0]); gap < time.Second {
+		t.Errorf("delay = %v, want >= 1s", gap)
+	}
+}
+
+func TestClient_Retry_GivesUpAfterMaxRetries(t *testing.T) {
+	server, attempts := rateLimitedServer(t, 5, "")
+	c := client.NewClient(server.URL, client.WithRetryPolicy(client.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+
+	_, err := c.Health(context.Background())
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Health() error = %v, want 429 *client.APIError", err)
+	}
+	if len(*attempts) != 3 {
+		t.Errorf("attempts = %d, want 3", len(*attempts))
+	}
+}
+
+func TestClient_Retry_SkipsNonIdempotentByDefault(t *testing.T) {
+	server, attempts := rateLimitedServer(t, 1, "")
+	c := client.NewClient(server.URL, client.WithRetryPolicy(client.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+
+	if _, err := c.Login(context.Background(), "admin", "password"); err == nil {
+		t.Fatal("Login() error = nil, want 429")
+	}
+	if len(*attempts) != 1 {
+		t.Errorf("attempts = %d, want 1", len(*attempts))
+	}
+
+	c = client.NewClient(server.URL, client.WithRetryPolicy(client.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, RetryNonIdempotent: true}))
+	*attempts = nil
+	if _, err := c.Login(context.Background(), "admin", "password"); err != nil {
+		t.Errorf("Login() with RetryNonIdempotent unexpected error: %v", err)
+	}
+}
+
+func TestClient_Ready(t *testing.T) {
+	tests := []struct {
+		name       string
+		check      services.Checker
+		wantErr    error
+		wantErrMsg string
+		wantReady  bool
+	}{
+		{"ready", func(ctx context.Context) error { return nil }, nil, "", true},
+		{"not ready", func(ctx context.Context) error { return errors.New("connection refused") }, client.ErrNotReady, "failed checks: database", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := services.NewHealthService("test-service", "test", time.Now(), nil)
+			health.RegisterCheck("database", tt.check)
+			server := httptest.NewServer(http.HandlerFunc(handlers.NewHealthHandler(health).Ready))
+			t.Cleanup(server.Close)
+
+			resp, err := client.NewClient(server.URL).Ready(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Ready() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantErrMsg) {
+				t.Errorf("Ready() error = %v, want it to contain %q", err, tt.wantErrMsg)
+			}
+			if resp == nil {
+				t.Fatal("Ready() response = nil, want the parsed checks")
+			}
+			if resp.Ready != tt.wantReady {
+				t.Errorf("Ready = %v, want %v", resp.Ready, tt.wantReady)
+			}
+			if len(resp.Checks) != 1 || resp.Checks[0].Name != "database" || resp.Checks[0].Healthy != tt.wantReady {
+				t.Errorf("Checks = %+v, want one database check with healthy=%v", resp.Checks, tt.wantReady)
+			}
+		})
+	}
+}
+
+// slowServer answers only after delay, or gives up when the client
+// disconnects first.
+func slowServer(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			response.JSON(w, http.StatusOK, map[string]string{"status": "healthy"})
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Cancel_MidRequest(t *testing.T) {
+	c := client.NewClient(slowServer(t, 5*time.Second).URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := c.Health(ctx)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Health() error = %v, want %v", err, context.Canceled)
+	}
+	var timeoutErr *client.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		t.Errorf("Health() error = %v, want a cancellation rather than a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Health() returned after %v, want prompt return on cancel", elapsed)
+	}
+}
+
+func TestClient_Cancel_DuringRetryBackoff(t *testing.T) {
+	server, attempts := rateLimitedServer(t, 1, "10")
+	c := client.NewClient(server.URL, client.WithRetryPolicy(client.RetryPolicy{MaxRetries: 1, MaxDelay: time.Minute}))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := c.Health(ctx)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Health() error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Health() returned after %v, want prompt return on cancel", elapsed)
+	}
+	if len(*attempts) != 1 {
+		t.Errorf("attempts = %d, want 1", len(*attempts))
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	server := slowServer(t, 5*time.Second)
+
+	tests := []struct {
+		name string
+		opts []client.Option
+		ctx  func() (context.Context, context.CancelFunc)
+	}{
+		{
+			name: "context deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+		},
+		{
+			name: "http client timeout",
+			opts: []client.Option{client.WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond})},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := client.NewClient(server.URL, tt.opts...)
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			start := time.Now()
+			_, err := c.Health(ctx)
+
+			var timeoutErr *client.TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("Health() error = %v, want *client.TimeoutError", err)
+			}
+			if timeoutErr.Method != http.MethodGet || timeoutErr.Path != "/health" {
+				t.Errorf("TimeoutError = %s %s, want GET /health", timeoutErr.Method, timeoutErr.Path)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Health() error = %v, want a match for %v", err, context.DeadlineExceeded)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Health() returned after %v, want prompt return on timeout", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// blockingHandler holds every request until release is closed and signals
+// each request it started on started.
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{started: make(chan struct{}, 16), release: make(chan struct{})}
+}
+
+func (h *blockingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.started <- struct{}{}
+	<-h.release
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// saturate starts n requests on handler and waits until all of them are
+// being served. The returned channel yields their status codes.
+func saturate(t *testing.T, handler http.Handler, blocking *blockingHandler, n int) <-chan int {
+	t.Helper()
+
+	codes := make(chan int, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			codes <- rec.Code
+		}()
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case <-blocking.started:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of %d requests started", i, n)
+		}
+	}
+	return codes
+}
+
+func assertRejected(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	var envelope response.ErrorEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+	}
+	if envelope.Error.Code != response.CodeServiceUnavailable {
+		t.Errorf("code = %s, want %s", envelope.Error.Code, response.CodeServiceUnavailable)
+	}
+}
+
+func TestLimitConcurrency_RejectsWhenFull(t *testing.T) {
+	blocking := newBlockingHandler()
+	handler := middleware.LimitConcurrency(2, blocking)
+	codes := saturate(t, handler, blocking, 2)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertRejected(t, rec)
+
+	close(blocking.release)
+	for i := 0; i < 2; i++ {
+		if code := <-codes; code != http.StatusNoContent {
+			t.Errorf("in-flight request status = %d, want %d", code, http.StatusNoContent)
+		}
+	}
+
+	// Completed requests free their slots.
+	codes = saturate(t, handler, blocking, 2)
+	for i := 0; i < 2; i++ {
+		if code := <-codes; code != http.StatusNoContent {
+			t.Errorf("status after release = %d, want %d", code, http.StatusNoContent)
+		}
+	}
+}
+
+func TestLimitConcurrencyWait(t *testing.T) {
+	t.Run("times out while full", func(t *testing.T) {
+		blocking := newBlockingHandler()
+		defer close(blocking.release)
+		handler := middleware.LimitConcurrencyWait(1, 50*time.Millisecond, blocking)
+		saturate(t, handler, blocking, 1)
+
+		start := time.Now()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assertRejected(t, rec)
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("rejected after %v, want it to wait 50ms", elapsed)
+		}
+	})
+
+	t.Run("served when a slot frees up", func(t *testing.T) {
+		blocking := newBlockingHandler()
+		handler := middleware.LimitConcurrencyWait(1, time.Second, blocking)
+		codes := saturate(t, handler, blocking, 1)
+
+		done := make(chan int, 1)
+		go func() {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			done <- rec.Code
+		}()
+		time.Sleep(20 * time.Millisecond)
+		close(blocking.release)
+
+		if code := <-codes; code != http.StatusNoContent {
+			t.Errorf("first status = %d, want %d", code, http.StatusNoContent)
+		}
+		<-blocking.started
+		if code := <-done; code != http.StatusNoContent {
+			t.Errorf("waiting request status = %d, want %d", code, http.StatusNoContent)
+		}
+	})
+
+	t.Run("gives up when the client goes away", func(t *testing.T) {
+		blocking := newBlockingHandler()
+		defer close(blocking.release)
+		handler := middleware.LimitConcurrencyWait(1, time.Minute, blocking)
+		saturate(t, handler, blocking, 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+		assertRejected(t, rec)
+	})
+}
+
+func TestLimitConcurrency_DisabledWithoutLimit(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := middleware.LimitConcurrency(0, next)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRouter_ConcurrencyLimitExemptsProbes(t *testing.T) {
+	deps := newTestDependencies()
+	deps.MaxConcurrentRequests = 1
+	handler := router.NewRouter(deps)
+
+	// Hold the only slot with a request whose body never finishes.
+	body, writer := io.Pipe()
+	defer writer.Close()
+	held := make(chan struct{})
+	go func() {
+		defer close(held)
+		req := httptest.NewRequest(http.MethodPost, "/login", body)
+		req.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	// Wait until the request holds the slot.
+	deadline := time.Now().Add(time.Second)
+	for {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+		if rec.Code == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the limit was never reached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for _, path := range []string{"/health", "/readyz"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, http.StatusOK)
+		}
+	}
+
+	writer.Close()
+	<-held
+}
@@ -0,0 +1,145 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/config"
+)
+
+const sampleConfigYAML = `
+port: 9090
+service_name: auth-api
+app_env: production
+jwt_secret: file-secret-of-at-least-32-bytes
+access_token_ttl: 15m
+prefix_probes: true
+max_body_bytes: 2048
+cors_allowed_origins:
+  - https://app.example.com
+  - https://admin.example.com
+`
+
+const sampleConfigJSON = `{
+  "PORT": 9091,
+  "SERVICE_NAME": "auth-api",
+  "ACCESS_TOKEN_TTL": "20m",
+  "PREFIX_PROBES": true,
+  "CORS_ALLOWED_ORIGINS": "https://app.example.com"
+}`
+
+// writeConfigFile writes content to a file with the given name in a
+// temporary directory and returns its path.
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadFromFile_YAML(t *testing.T) {
+	clearConfigEnv(t)
+
+	cfg, err := config.LoadFromFile(writeConfigFile(t, "config.yaml", sampleConfigYAML))
+	if err != nil {
+		t.Fatalf("LoadFromFile() unexpected error: %v", err)
+	}
+	if cfg.Port != "9090" {
+		t.Errorf("Port = %q, want %q", cfg.Port, "9090")
+	}
+	if cfg.ServiceName != "auth-api" {
+		t.Errorf("ServiceName = %q, want %q", cfg.ServiceName, "auth-api")
+	}
+	if !cfg.IsProduction() || cfg.JWTSecret != "file-secret-of-at-least-32-bytes" {
+		t.Errorf("Environment, JWTSecret = %q, %q, want production, file-secret-of-at-least-32-bytes", cfg.Environment, cfg.JWTSecret)
+	}
+	if cfg.AccessTokenTTL != 15*time.Minute {
+		t.Errorf("AccessTokenTTL = %v, want %v", cfg.AccessTokenTTL, 15*time.Minute)
+	}
+	if !cfg.PrefixProbes {
+		t.Error("PrefixProbes = false, want true")
+	}
+	if cfg.MaxBodyBytes != 2048 {
+		t.Errorf("MaxBodyBytes = %d, want %d", cfg.MaxBodyBytes, 2048)
+	}
+	if got := strings.Join(cfg.CORSAllowedOrigins, "|"); got != "https://app.example.com|https://admin.example.com" {
+		t.Errorf("CORSAllowedOrigins = %v", cfg.CORSAllowedOrigins)
+	}
+	if cfg.RefreshTokenTTL != config.DefaultRefreshTokenTTL {
+		t.Errorf("RefreshTokenTTL = %v, want default %v", cfg.RefreshTokenTTL, config.DefaultRefreshTokenTTL)
+	}
+}
+
+func TestLoadFromFile_JSON(t *testing.T) {
+	clearConfigEnv(t)
+
+	cfg, err := config.LoadFromFile(writeConfigFile(t, "config.json", sampleConfigJSON))
+	if err != nil {
+		t.Fatalf("LoadFromFile() unexpected error: %v", err)
+	}
+	if cfg.Port != "9091" {
+		t.Errorf("Port = %q, want %q", cfg.Port, "9091")
+	}
+	if cfg.AccessTokenTTL != 20*time.Minute {
+		t.Errorf("AccessTokenTTL = %v, want %v", cfg.AccessTokenTTL, 20*time.Minute)
+	}
+	if !cfg.PrefixProbes {
+		t.Error("PrefixProbes = false, want true")
+	}
+	if got := strings.Join(cfg.CORSAllowedOrigins, "|"); got != "https://app.example.com" {
+		t.Errorf("CORSAllowedOrigins = %v", cfg.CORSAllowedOrigins)
+	}
+}
+
+func TestLoadFromFile_EnvOverridesFile(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("PORT", "7070")
+	t.Setenv("ACCESS_TOKEN_TTL", "5m")
+
+	cfg, err := config.LoadFromFile(writeConfigFile(t, "config.yml", sampleConfigYAML))
+	if err != nil {
+		t.Fatalf("LoadFromFile() unexpected error: %v", err)
+	}
+	if cfg.Port != "7070" {
+		t.Errorf("Port = %q, want env value %q", cfg.Port, "7070")
+	}
+	if cfg.AccessTokenTTL != 5*time.Minute {
+		t.Errorf("AccessTokenTTL = %v, want env value %v", cfg.AccessTokenTTL, 5*time.Minute)
+	}
+	if cfg.ServiceName != "auth-api" {
+		t.Errorf("ServiceName = %q, want file value %q", cfg.ServiceName, "auth-api")
+	}
+}
+
+func TestLoadFromFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr error
+	}{
+		{"invalid merged port", "config.yaml", "port: 0\n", config.ErrInvalidPort},
+		{"production without secret", "config.json", `{"app_env": "production"}`, config.ErrJWTSecretRequired},
+		{"invalid duration", "config.yaml", "access_token_ttl: soon\n", config.ErrInvalidDuration},
+		{"unknown key", "config.yaml", "port: 9090\nprot: 9091\n", config.ErrUnknownConfigKey},
+		{"nested value", "config.json", `{"smtp": {"host": "mail"}}`, config.ErrInvalidConfigValue},
+		{"unsupported format", "config.toml", "port = 9090\n", config.ErrUnsupportedConfigFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearConfigEnv(t)
+
+			if _, err := config.LoadFromFile(writeConfigFile(t, tt.file, tt.content)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadFromFile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
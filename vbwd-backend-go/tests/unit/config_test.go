@@ -0,0 +1,526 @@
+package unit
+
+import (
+	"errors"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/config"
+)
+
+// configEnvKeys lists every variable read by config.Load.
+var configEnvKeys = []string{
+	"PORT",
+	"SERVICE_NAME",
+	"JWT_SECRET",
+	"JWT_ALGORITHM",
+	"JWT_PRIVATE_KEY_FILE",
+	"JWT_PUBLIC_KEY_FILE",
+	"JWT_VERIFICATION_KEY_FILES",
+	"APP_ENV",
+	"SHUTDOWN_TIMEOUT",
+	"CORS_ALLOWED_ORIGINS",
+	"ACCESS_TOKEN_TTL",
+	"REFRESH_TOKEN_TTL",
+	"TOKEN_LEEWAY",
+	"KEY_ROTATION_GRACE",
+	"ISSUE_REFRESH_TOKENS",
+	"LOG_LEVEL",
+	"REVOCATION_FAILURE_POLICY",
+	"SESSION_STORE_TIMEOUT",
+	"RESET_TOKEN_TTL",
+	"IDEMPOTENCY_TTL",
+	"USER_CACHE_CAPACITY",
+	"USER_CACHE_TTL",
+	"ROUTE_PREFIX",
+	"PREFIX_PROBES",
+	"ENABLE_REGISTRATION",
+	"TRUSTED_PROXIES",
+	"REDIS_ADDR",
+	"REDIS_PASSWORD",
+	"SMTP_HOST",
+	"SMTP_PORT",
+	"SMTP_USERNAME",
+	"SMTP_PASSWORD",
+	"SMTP_FROM",
+	"OIDC_PROVIDER_NAME",
+	"OIDC_DISCOVERY_URL",
+	"OIDC_CLIENT_ID",
+	"OIDC_CLIENT_SECRET",
+	"OIDC_REDIRECT_URL",
+	"TLS_CERT_FILE",
+	"TLS_KEY_FILE",
+	"HSTS_MAX_AGE",
+	"REDIRECT_HTTPS",
+	"READINESS_CACHE_TTL",
+	"REQUEST_TIMEOUT",
+	"LOGIN_MAX_FAILURES_PER_IP",
+	"LOGIN_IP_BLOCK_WINDOW",
+	"DISK_CHECK_PATH",
+	"MIN_FREE_DISK_BYTES",
+	"MIN_FREE_MEMORY_BYTES",
+	"MAX_BODY_BYTES",
+	"READINESS_HISTORY_SIZE",
+	"MAX_CONCURRENT_REQUESTS",
+	"CONCURRENCY_WAIT",
+	"SEED_USERS_FILE",
+	"BCRYPT_COST",
+	"PASSWORD_HASHER",
+	"READ_HEADER_TIMEOUT",
+	"READ_TIMEOUT",
+	"WRITE_TIMEOUT",
+	"IDLE_TIMEOUT",
+}
+
+// clearConfigEnv isolates config tests from the caller's environment.
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range configEnvKeys {
+		t.Setenv(key, "")
+	}
+}
+
+func TestConfigLoad_Defaults(t *testing.T) {
+	clearConfigEnv(t)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.Port != config.DefaultPort {
+		t.Errorf("Port = %q, want %q", cfg.Port, config.DefaultPort)
+	}
+	if cfg.ServiceName != config.DefaultServiceName {
+		t.Errorf("ServiceName = %q, want %q", cfg.ServiceName, config.DefaultServiceName)
+	}
+	if cfg.Environment != config.DefaultEnvironment {
+		t.Errorf("Environment = %q, want %q", cfg.Environment, config.DefaultEnvironment)
+	}
+	if cfg.JWTSecret == "" || !cfg.JWTSecretEphemeral {
+		t.Errorf("JWTSecret = %q, ephemeral %t, want a generated development secret", cfg.JWTSecret, cfg.JWTSecretEphemeral)
+	}
+	if cfg.ShutdownTimeout != config.DefaultShutdownTimeout {
+		t.Errorf("ShutdownTimeout = %v, want %v", cfg.ShutdownTimeout, config.DefaultShutdownTimeout)
+	}
+	if cfg.BcryptCost != config.DefaultBcryptCost {
+		t.Errorf("BcryptCost = %d, want %d", cfg.BcryptCost, config.DefaultBcryptCost)
+	}
+	if cfg.MaxBodyBytes != config.DefaultMaxBodyBytes {
+		t.Errorf("MaxBodyBytes = %d, want %d", cfg.MaxBodyBytes, config.DefaultMaxBodyBytes)
+	}
+	if !cfg.IssueRefreshTokens {
+		t.Error("IssueRefreshTokens = false, want true")
+	}
+	if !cfg.EnableRegistration {
+		t.Error("EnableRegistration = false, want true")
+	}
+	if cfg.Addr() != ":8082" {
+		t.Errorf("Addr() = %q, want %q", cfg.Addr(), ":8082")
+	}
+}
+
+func TestConfigLoad_Overrides(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("PORT", "9090")
+	t.Setenv("SERVICE_NAME", "auth-api")
+	t.Setenv("JWT_SECRET", "super-secret-of-at-least-32-bytes")
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("SHUTDOWN_TIMEOUT", "30s")
+	t.Setenv("WRITE_TIMEOUT", "45s")
+	t.Setenv("ACCESS_TOKEN_TTL", "15m")
+	t.Setenv("TOKEN_LEEWAY", "5s")
+	t.Setenv("ISSUE_REFRESH_TOKENS", "false")
+	t.Setenv("ENABLE_REGISTRATION", "false")
+	t.Setenv("REDIS_ADDR", "redis:6379")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com, https://admin.example.com,")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.Port != "9090" {
+		t.Errorf("Port = %q, want %q", cfg.Port, "9090")
+	}
+	if cfg.ServiceName != "auth-api" {
+		t.Errorf("ServiceName = %q, want %q", cfg.ServiceName, "auth-api")
+	}
+	if cfg.JWTSecret != "super-secret-of-at-least-32-bytes" || cfg.JWTSecretEphemeral {
+		t.Errorf("JWTSecret = %q, ephemeral %t, want the configured secret", cfg.JWTSecret, cfg.JWTSecretEphemeral)
+	}
+	if cfg.ShutdownTimeout != 30*time.Second {
+		t.Errorf("ShutdownTimeout = %v, want %v", cfg.ShutdownTimeout, 30*time.Second)
+	}
+	if cfg.WriteTimeout != 45*time.Second {
+		t.Errorf("WriteTimeout = %v, want %v", cfg.WriteTimeout, 45*time.Second)
+	}
+	if cfg.RequestTimeout != config.DefaultRequestTimeout {
+		t.Errorf("RequestTimeout = %v, want %v", cfg.RequestTimeout, config.DefaultRequestTimeout)
+	}
+	if cfg.ReadHeaderTimeout != config.DefaultReadHeaderTimeout {
+		t.Errorf("ReadHeaderTimeout = %v, want %v", cfg.ReadHeaderTimeout, config.DefaultReadHeaderTimeout)
+	}
+	if got := strings.Join(cfg.CORSAllowedOrigins, "|"); got != "https://app.example.com|https://admin.example.com" {
+		t.Errorf("CORSAllowedOrigins = %v", cfg.CORSAllowedOrigins)
+	}
+	if cfg.RedisAddr != "redis:6379" {
+		t.Errorf("RedisAddr = %q, want %q", cfg.RedisAddr, "redis:6379")
+	}
+	if cfg.AccessTokenTTL != 15*time.Minute {
+		t.Errorf("AccessTokenTTL = %v, want %v", cfg.AccessTokenTTL, 15*time.Minute)
+	}
+	if cfg.RefreshTokenTTL != config.DefaultRefreshTokenTTL {
+		t.Errorf("RefreshTokenTTL = %v, want %v", cfg.RefreshTokenTTL, config.DefaultRefreshTokenTTL)
+	}
+	if cfg.TokenLeeway != 5*time.Second {
+		t.Errorf("TokenLeeway = %v, want %v", cfg.TokenLeeway, 5*time.Second)
+	}
+	if cfg.IssueRefreshTokens {
+		t.Error("IssueRefreshTokens = true, want false")
+	}
+	if cfg.EnableRegistration {
+		t.Error("EnableRegistration = true, want false")
+	}
+	if !cfg.IsProduction() {
+		t.Error("IsProduction() = false, want true")
+	}
+}
+
+func TestConfigLoad_InvalidPort(t *testing.T) {
+	for _, port := range []string{"http", "0", "70000"} {
+		t.Run(port, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv("PORT", port)
+
+			if _, err := config.Load(); !errors.Is(err, config.ErrInvalidPort) {
+				t.Errorf("Load() error = %v, want %v", err, config.ErrInvalidPort)
+			}
+		})
+	}
+}
+
+func TestConfigLoad_JWTSecret(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		secret      string
+		wantErr     error
+	}{
+		{"production without secret", "production", "", config.ErrJWTSecretRequired},
+		{"staging without secret", "staging", "", config.ErrJWTSecretRequired},
+		{"production with short secret", "production", "super-secret", config.ErrJWTSecretTooShort},
+		{"staging with short secret", "staging", strings.Repeat("s", config.MinJWTSecretLength-1), config.ErrJWTSecretTooShort},
+		{"production with long secret", "production", strings.Repeat("s", config.MinJWTSecretLength), nil},
+		{"development with short secret", "development", "super-secret", config.ErrJWTSecretTooShort},
+		{"default environment with short secret", "", "secret", config.ErrJWTSecretTooShort},
+		{"development with long secret", "development", strings.Repeat("s", config.MinJWTSecretLength), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv("APP_ENV", tt.environment)
+			t.Setenv("JWT_SECRET", tt.secret)
+
+			cfg, err := config.Load()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (cfg.JWTSecret != tt.secret || cfg.JWTSecretEphemeral) {
+				t.Errorf("JWTSecret = %q, ephemeral %t, want %q", cfg.JWTSecret, cfg.JWTSecretEphemeral, tt.secret)
+			}
+		})
+	}
+}
+
+func TestConfigLoad_DevelopmentGeneratesEphemeralSecret(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("APP_ENV", "development")
+
+	first, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	second, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if !first.JWTSecretEphemeral {
+		t.Error("JWTSecretEphemeral = false, want true")
+	}
+	if len(first.JWTSecret) < config.MinJWTSecretLength {
+		t.Errorf("len(JWTSecret) = %d, want at least %d", len(first.JWTSecret), config.MinJWTSecretLength)
+	}
+	if first.JWTSecret == second.JWTSecret {
+		t.Error("two loads generated the same secret, want a random one per process")
+	}
+}
+
+func TestConfigLoad_JWTAlgorithm(t *testing.T) {
+	tests := []struct {
+		name       string
+		algorithm  string
+		privateKey string
+		wantErr    error
+	}{
+		{name: "default", wantErr: nil},
+		{name: "rs256 with key", algorithm: "RS256", privateKey: "jwt.pem", wantErr: nil},
+		{name: "lowercase", algorithm: "rs256", privateKey: "jwt.pem", wantErr: nil},
+		{name: "rs256 without key", algorithm: "RS256", wantErr: config.ErrJWTPrivateKeyRequired},
+		{name: "unknown", algorithm: "none", wantErr: config.ErrInvalidJWTAlgorithm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv("JWT_ALGORITHM", tt.algorithm)
+			t.Setenv("JWT_PRIVATE_KEY_FILE", tt.privateKey)
+
+			if _, err := config.Load(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigLoad_SMTPRequiresFrom(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+
+	if _, err := config.Load(); !errors.Is(err, config.ErrSMTPFromRequired) {
+		t.Errorf("Load() error = %v, want %v", err, config.ErrSMTPFromRequired)
+	}
+}
+
+func TestConfigLoad_OIDCRequiresClient(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("OIDC_DISCOVERY_URL", "https://accounts.example.com/.well-known/openid-configuration")
+	t.Setenv("OIDC_CLIENT_ID", "vbwd")
+
+	if _, err := config.Load(); !errors.Is(err, config.ErrOIDCIncomplete) {
+		t.Errorf("Load() error = %v, want %v", err, config.ErrOIDCIncomplete)
+	}
+}
+
+func TestConfigLoad_TLSPair(t *testing.T) {
+	tests := []struct {
+		name    string
+		cert    string
+		key     string
+		wantErr error
+		wantTLS bool
+	}{
+		{name: "neither", wantErr: nil, wantTLS: false},
+		{name: "both", cert: "cert.pem", key: "key.pem", wantErr: nil, wantTLS: true},
+		{name: "cert only", cert: "cert.pem", wantErr: config.ErrTLSPairIncomplete},
+		{name: "key only", key: "key.pem", wantErr: config.ErrTLSPairIncomplete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv("TLS_CERT_FILE", tt.cert)
+			t.Setenv("TLS_KEY_FILE", tt.key)
+
+			cfg, err := config.Load()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.TLSEnabled() != tt.wantTLS {
+				t.Errorf("TLSEnabled() = %t, want %t", cfg.TLSEnabled(), tt.wantTLS)
+			}
+		})
+	}
+}
+
+func TestConfigLoad_InvalidPrefixProbes(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("PREFIX_PROBES", "maybe")
+
+	if _, err := config.Load(); !errors.Is(err, config.ErrInvalidBool) {
+		t.Errorf("Load() error = %v, want %v", err, config.ErrInvalidBool)
+	}
+}
+
+func TestConfigLoad_PasswordHasher(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("PASSWORD_HASHER", "argon2id")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.PasswordHasher != "argon2id" {
+		t.Errorf("PasswordHasher = %q, want %q", cfg.PasswordHasher, "argon2id")
+	}
+}
+
+func TestConfigLoad_InvalidShutdownTimeout(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("SHUTDOWN_TIMEOUT", "soon")
+
+	if _, err := config.Load(); !errors.Is(err, config.ErrInvalidDuration) {
+		t.Errorf("Load() error = %v, want %v", err, config.ErrInvalidDuration)
+	}
+}
+
+func TestConfigLoad_InvalidMaxBodyBytes(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("MAX_BODY_BYTES", "lots")
+
+	if _, err := config.Load(); !errors.Is(err, config.ErrInvalidNumber) {
+		t.Errorf("Load() error = %v, want %v", err, config.ErrInvalidNumber)
+	}
+}
+
+func TestConfigLoad_Numbers(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr error
+	}{
+		{"MAX_CONCURRENT_REQUESTS", "0", nil},
+		{"MIN_FREE_DISK_BYTES", "0", nil},
+		{"MIN_FREE_MEMORY_BYTES", "0", nil},
+		{"MIN_FREE_DISK_BYTES", "1073741824", nil},
+		{"MAX_CONCURRENT_REQUESTS", "-1", config.ErrInvalidNumber},
+		{"MIN_FREE_MEMORY_BYTES", "-1", config.ErrInvalidNumber},
+		{"MAX_BODY_BYTES", "0", config.ErrInvalidNumber},
+		{"SMTP_PORT", "0", config.ErrInvalidNumber},
+		{"BCRYPT_COST", "0", config.ErrInvalidNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv(tt.key, tt.value)
+
+			if _, err := config.Load(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigLoad_Durations(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr error
+	}{
+		{"REQUEST_TIMEOUT", "0s", nil},
+		{"HSTS_MAX_AGE", "0s", nil},
+		{"CONCURRENCY_WAIT", "0s", nil},
+		{"TOKEN_LEEWAY", "0s", nil},
+		{"READINESS_CACHE_TTL", "0s", nil},
+		{"USER_CACHE_TTL", "0s", nil},
+		{"READ_TIMEOUT", "30s", nil},
+		{"TOKEN_LEEWAY", "-1s", config.ErrInvalidDuration},
+		{"SHUTDOWN_TIMEOUT", "0s", config.ErrInvalidDuration},
+		{"READ_HEADER_TIMEOUT", "0s", config.ErrInvalidDuration},
+		{"READ_TIMEOUT", "0s", config.ErrInvalidDuration},
+		{"WRITE_TIMEOUT", "0s", config.ErrInvalidDuration},
+		{"IDLE_TIMEOUT", "0s", config.ErrInvalidDuration},
+		{"ACCESS_TOKEN_TTL", "0s", config.ErrInvalidDuration},
+		{"REFRESH_TOKEN_TTL", "0s", config.ErrInvalidDuration},
+		{"SESSION_STORE_TIMEOUT", "0s", config.ErrInvalidDuration},
+		{"KEY_ROTATION_GRACE", "0s", config.ErrInvalidDuration},
+		{"LOGIN_IP_BLOCK_WINDOW", "0s", config.ErrInvalidDuration},
+		{"RESET_TOKEN_TTL", "0s", config.ErrInvalidDuration},
+		{"IDEMPOTENCY_TTL", "0s", config.ErrInvalidDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv(tt.key, tt.value)
+
+			if _, err := config.Load(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigLoad_RevocationFailurePolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr error
+	}{
+		{"", "fail_closed", nil},
+		{"fail_open", "fail_open", nil},
+		{"FAIL_CLOSED", "fail_closed", nil},
+		{"ignore", "", config.ErrInvalidRevocationPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv("REVOCATION_FAILURE_POLICY", tt.value)
+
+			cfg, err := config.Load()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.RevocationFailurePolicy != tt.want {
+				t.Errorf("RevocationFailurePolicy = %q, want %q", cfg.RevocationFailurePolicy, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigLoad_LogLevel(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    slog.Level
+		wantErr error
+	}{
+		{"", slog.LevelInfo, nil},
+		{"debug", slog.LevelDebug, nil},
+		{"WARN", slog.LevelWarn, nil},
+		{"verbose", 0, config.ErrInvalidLogLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			clearConfigEnv(t)
+			t.Setenv("LOG_LEVEL", tt.value)
+
+			cfg, err := config.Load()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.LogLevel != tt.want {
+				t.Errorf("LogLevel = %v, want %v", cfg.LogLevel, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigLoad_TrustedProxies(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1,2001:db8::/32")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	want := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.0.2.1/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+	if !slices.Equal(cfg.TrustedProxies, want) {
+		t.Errorf("TrustedProxies = %v, want %v", cfg.TrustedProxies, want)
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "proxy.internal")
+	if _, err := config.Load(); !errors.Is(err, config.ErrInvalidTrustedProxy) {
+		t.Errorf("Load() error = %v, want %v", err, config.ErrInvalidTrustedProxy)
+	}
+}
@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+)
+
+func TestRequireJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		wantStatus  int
+	}{
+		{"missing content type", "", http.StatusUnsupportedMediaType},
+		{"form post", "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"plain text", "text/plain", http.StatusUnsupportedMediaType},
+		{"json", "application/json", http.StatusOK},
+		{"json with charset", "application/json; charset=utf-8", http.StatusOK},
+		{"json with uppercase charset", "Application/JSON; charset=UTF-8", http.StatusOK},
+		{"json with other charset", "application/json; charset=latin1", http.StatusUnsupportedMediaType},
+		{"malformed", "application/json;;", http.StatusUnsupportedMediaType},
+	}
+
+	handler := middleware.RequireJSON(okHandler)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{}`))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRouter_LoginRejectsFormPost(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("username=admin&password=password"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+
+	newTestRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnsupportedMediaType)
+	}
+	if !strings.Contains(rec.Body.String(), "UNSUPPORTED_MEDIA_TYPE") {
+		t.Errorf("body = %s, want UNSUPPORTED_MEDIA_TYPE code", rec.Body.String())
+	}
+}
@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+)
+
+func TestCORS_Preflight(t *testing.T) {
+	called := false
+	handler := middleware.CORS([]string{"https://app.example.com"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/login", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if called {
+		t.Error("preflight reached the wrapped handler")
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q", got)
+	}
+	if rec.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Error("Access-Control-Allow-Methods missing")
+	}
+	if rec.Header().Get("Access-Control-Allow-Headers") == "" {
+		t.Error("Access-Control-Allow-Headers missing")
+	}
+}
+
+func TestCORS_Origins(t *testing.T) {
+	tests := []struct {
+		name            string
+		allowed         []string
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{"exact match", []string{"https://app.example.com"}, "https://app.example.com", "https://app.example.com", "true"},
+		{"disallowed origin", []string{"https://app.example.com"}, "https://evil.example.com", "", ""},
+		{"wildcard", []string{"*"}, "https://any.example.com", "*", ""},
+		{"exact match wins over wildcard", []string{"*", "https://app.example.com"}, "https://app.example.com", "https://app.example.com", "true"},
+		{"no origin header", []string{"*"}, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.CORS(tt.allowed)(http.HandlerFunc(okHandler))
+
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+		})
+	}
+}
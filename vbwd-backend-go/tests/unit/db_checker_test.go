@@ -0,0 +1,72 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// stubPinger simulates a database ping taking delay before returning err.
+type stubPinger struct {
+	delay time.Duration
+	err   error
+}
+
+func (p stubPinger) PingContext(ctx context.Context) error {
+	select {
+	case <-time.After(p.delay):
+		return p.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestDBChecker_Healthy(t *testing.T) {
+	check := services.NewDBChecker(stubPinger{}, time.Second)
+
+	if err := check(context.Background()); err != nil {
+		t.Errorf("check() unexpected error: %v", err)
+	}
+}
+
+func TestDBChecker_PingError(t *testing.T) {
+	check := services.NewDBChecker(stubPinger{err: errors.New("connection refused")}, time.Second)
+
+	err := check(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("check() error = %v, want ping error", err)
+	}
+}
+
+func TestDBChecker_SlowPingTimesOut(t *testing.T) {
+	check := services.NewDBChecker(stubPinger{delay: time.Second}, 20*time.Millisecond)
+
+	start := time.Now()
+	err := check(context.Background())
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("check() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err != nil && !strings.Contains(err.Error(), "after") {
+		t.Errorf("check() error %q does not record latency", err)
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("check() took %v, want it bounded by the timeout", elapsed)
+	}
+}
+
+func TestDBChecker_HonorsCallerDeadline(t *testing.T) {
+	check := services.NewDBChecker(stubPinger{delay: time.Second}, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := check(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("check() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,75 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// newJSONRequest returns a POST request to path carrying body as JSON.
+func newJSONRequest(path, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestDecodeJSON_Success(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req, ok := handlers.DecodeJSON[models.LoginRequest](rec, newJSONRequest("/login", `{"username":"admin","password":"password"}`))
+
+	if !ok {
+		t.Fatalf("DecodeJSON() ok = false (body: %s)", rec.Body.String())
+	}
+	if req.Username != "admin" || req.Password != "password" {
+		t.Errorf("DecodeJSON() = %+v, want the decoded credentials", req)
+	}
+}
+
+func TestDecodeJSON_Failures(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantMessage string
+	}{
+		{"missing content type", "", `{}`, http.StatusUnsupportedMediaType, "Content-Type must be application/json"},
+		{"form content type", "application/x-www-form-urlencoded", `{}`, http.StatusUnsupportedMediaType, "Content-Type must be application/json"},
+		{"too large", "application/json", `{"username":"` + strings.Repeat("a", int(handlers.MaxJSONBodyBytes)) + `"}`, http.StatusRequestEntityTooLarge, "Request body too large"},
+		{"unknown field", "application/json", `{"username":"admin","password":"password","remember":true}`, http.StatusBadRequest, `Unknown field "remember"`},
+		{"empty body", "application/json", ``, http.StatusBadRequest, "Request body is required"},
+		{"syntax error", "application/json; charset=utf-8", `{"username": admin}`, http.StatusBadRequest, "Malformed JSON at offset 14"},
+		{"wrong field type", "application/json", `{"username":42}`, http.StatusBadRequest, `Field "username" expected type string`},
+		{"not an object", "application/json", `["admin"]`, http.StatusBadRequest, "Request body must be a JSON object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			if _, ok := handlers.DecodeJSON[models.LoginRequest](rec, req); ok {
+				t.Fatal("DecodeJSON() ok = true, want false")
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var body response.ErrorEnvelope
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if body.Error.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", body.Error.Message, tt.wantMessage)
+			}
+		})
+	}
+}
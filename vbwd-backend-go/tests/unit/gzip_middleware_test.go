@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+)
+
+func TestGzip(t *testing.T) {
+	large := strings.Repeat(`{"id":"1","username":"admin","role":"admin"},`, 100)
+	small := `{"ok":true}`
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		body           string
+		wantGzip       bool
+	}{
+		{"large body gzip accepted", "gzip, deflate", large, true},
+		{"large body gzip not accepted", "", large, false},
+		{"large body gzip refused", "gzip;q=0", large, false},
+		{"small body gzip accepted", "gzip", small, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Length", strconv.Itoa(len(tt.body)))
+				w.WriteHeader(http.StatusOK)
+				io.WriteString(w, tt.body)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want %q", got, "Accept-Encoding")
+			}
+
+			body := rec.Body.String()
+			if tt.wantGzip {
+				if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+					t.Fatalf("Content-Encoding = %q, want gzip", got)
+				}
+				if got := rec.Header().Get("Content-Length"); got != "" {
+					t.Errorf("Content-Length = %q, want it removed", got)
+				}
+				reader, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader: %v", err)
+				}
+				decoded, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatalf("read gzip body: %v", err)
+				}
+				body = string(decoded)
+			} else {
+				if got := rec.Header().Get("Content-Encoding"); got != "" {
+					t.Errorf("Content-Encoding = %q, want none", got)
+				}
+				if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(len(tt.body)) {
+					t.Errorf("Content-Length = %q, want %d", got, len(tt.body))
+				}
+			}
+			if body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestGzip_PanicReachesRecover(t *testing.T) {
+	captureDefaultLogger(t)
+	handler := middleware.Recover(middleware.Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		panic("boom")
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,201 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+func TestHealthHandler_Ready(t *testing.T) {
+	tests := []struct {
+		name       string
+		checkErr   error
+		wantStatus int
+	}{
+		{"passing check", nil, http.StatusOK},
+		{"failing check", errors.New("down"), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := services.NewHealthService("test-service", "test", time.Now(), nil)
+			service.RegisterCheck("dependency", func(ctx context.Context) error { return tt.checkErr })
+			handler := handlers.NewHealthHandler(service)
+
+			rec := httptest.NewRecorder()
+			handler.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHealthHandler_Maintenance(t *testing.T) {
+	service := services.NewHealthService("test-service", "test", time.Now(), nil)
+	handler := handlers.NewHealthHandler(service)
+
+	probe := func() (string, int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.Health(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var health models.HealthResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &health); err != nil {
+			t.Fatalf("decode health: %v", err)
+		}
+
+		rec = httptest.NewRecorder()
+		handler.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return health.Status, rec.Code
+	}
+
+	for _, step := range []struct {
+		enabled    bool
+		wantStatus string
+		wantReady  int
+	}{
+		{false, models.StatusHealthy, http.StatusOK},
+		{true, models.StatusMaintenance, http.StatusServiceUnavailable},
+		{false, models.StatusHealthy, http.StatusOK},
+	} {
+		service.SetMaintenance(step.enabled)
+
+		status, ready := probe()
+		if status != step.wantStatus {
+			t.Errorf("maintenance=%t: health status = %q, want %q", step.enabled, status, step.wantStatus)
+		}
+		if ready != step.wantReady {
+			t.Errorf("maintenance=%t: readyz status = %d, want %d", step.enabled, ready, step.wantReady)
+		}
+	}
+}
+
+func TestRouter_AdminMaintenance(t *testing.T) {
+	handler := newTestRouter()
+	token := loginForToken(t, handler, "admin", "password")
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/admin/maintenance", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	ready := func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rec.Code
+	}
+
+	if rec := send(`{}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("missing flag status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if rec := send(`{"enabled":true}`); rec.Code != http.StatusOK {
+		t.Fatalf("enable status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("readyz in maintenance = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if rec := send(`{"enabled":false}`); rec.Code != http.StatusOK {
+		t.Fatalf("disable status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if code := ready(); code != http.StatusOK {
+		t.Errorf("readyz after maintenance = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestHealthHandler_History(t *testing.T) {
+	service := services.NewHealthService("test-service", "test", time.Now(), nil)
+	service.RegisterCheck("dependency", func(ctx context.Context) error { return errors.New("down") })
+	handler := handlers.NewHealthHandler(service)
+
+	for i := 0; i < 2; i++ {
+		handler.Ready(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	}
+
+	rec := httptest.NewRecorder()
+	handler.History(rec, httptest.NewRequest(http.MethodGet, "/healthz/history", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var history models.ReadinessHistoryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	if history.Capacity != services.DefaultReadinessHistorySize || len(history.Events) != 2 {
+		t.Fatalf("history = %+v, want 2 events with the default capacity", history)
+	}
+	for i, event := range history.Events {
+		if event.Ready || strings.Join(event.FailedChecks, ",") != "dependency" {
+			t.Errorf("Events[%d] = %+v, want a failed dependency check", i, event)
+		}
+	}
+}
+
+func TestHealthHandler_ETag(t *testing.T) {
+	service := services.NewHealthService("test-service", "test", time.Now(), nil)
+	handler := handlers.NewHealthHandler(service)
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.Health(rec, req)
+		return rec
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Body.Len() == 0 {
+		t.Fatalf("first status = %d with %d body bytes, want 200 with a body", first.Code, first.Body.Len())
+	}
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("ETag = %q, want a weak tag", etag)
+	}
+	if second := get(""); second.Header().Get("ETag") != etag {
+		t.Errorf("ETag changed between calls: %q, then %q", etag, second.Header().Get("ETag"))
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{"matching tag", etag, http.StatusNotModified},
+		{"strong form of the tag", strings.TrimPrefix(etag, "W/"), http.StatusNotModified},
+		{"tag in a list", `"other", ` + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"other tag", `W/"other"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(tt.ifNoneMatch)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Code == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("304 body = %q, want empty", rec.Body.String())
+			}
+			if rec.Header().Get("ETag") != etag {
+				t.Errorf("ETag = %q, want %q", rec.Header().Get("ETag"), etag)
+			}
+		})
+	}
+
+	service.SetMaintenance(true)
+	if rec := get(etag); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("after status change: status = %d, ETag = %q, want 200 with a new tag", rec.Code, rec.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,387 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+func TestHealthService_GetHealthStatus(t *testing.T) {
+	service := services.NewHealthService("test-service", "1.2.3", time.Now(), nil)
+
+	before := time.Now().UTC()
+	status := service.GetHealthStatus(context.Background())
+	after := time.Now().UTC()
+
+	if status.Status != "healthy" {
+		t.Errorf("Status = %q, want %q", status.Status, "healthy")
+	}
+	if status.Service != "test-service" {
+		t.Errorf("Service = %q, want %q", status.Service, "test-service")
+	}
+	if status.Version != "1.2.3" {
+		t.Errorf("Version = %q, want %q", status.Version, "1.2.3")
+	}
+	if status.Timestamp.Before(before) || status.Timestamp.After(after) {
+		t.Errorf("Timestamp %v not within [%v, %v]", status.Timestamp, before, after)
+	}
+}
+
+func TestHealthService_TimestampUsesClock(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC))
+	service := services.NewHealthService("test-service", "1.2.3", clock.Now(), clock)
+
+	if got := service.GetHealthStatus(context.Background()).Timestamp; !got.Equal(clock.Now()) {
+		t.Errorf("Timestamp = %v, want %v", got, clock.Now())
+	}
+}
+
+func TestHealthService_UptimeGrows(t *testing.T) {
+	start := time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start.Add(10 * time.Second))
+	service := services.NewHealthService("test-service", "1.2.3", start, clock)
+
+	first := service.GetHealthStatus(context.Background())
+	clock.Advance(5 * time.Second)
+	second := service.GetHealthStatus(context.Background())
+
+	if first.UptimeSeconds != 10 {
+		t.Errorf("first UptimeSeconds = %d, want 10", first.UptimeSeconds)
+	}
+	if second.UptimeSeconds != 15 {
+		t.Errorf("second UptimeSeconds = %d, want 15", second.UptimeSeconds)
+	}
+}
+
+func TestHealthService_GetReadiness_NoChecks(t *testing.T) {
+	service := services.NewHealthService("test-service", "1.2.3", time.Now(), nil)
+
+	readiness := service.GetReadiness(context.Background())
+	if !readiness.Ready {
+		t.Error("Ready = false, want true with no checks")
+	}
+	if len(readiness.Checks) != 0 {
+		t.Errorf("len(Checks) = %d, want 0", len(readiness.Checks))
+	}
+}
+
+func TestHealthService_GetReadiness_AggregatesChecks(t *testing.T) {
+	service := services.NewHealthService("test-service", "1.2.3", time.Now(), nil)
+	service.RegisterCheck("cache", func(ctx context.Context) error { return nil })
+	service.RegisterCheck("database", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	readiness := service.GetReadiness(context.Background())
+
+	if readiness.Ready {
+		t.Error("Ready = true, want false when a check fails")
+	}
+	want := []models.CheckResult{
+		{Name: "cache", Healthy: true},
+		{Name: "database", Healthy: false, Error: "connection refused"},
+	}
+	if len(readiness.Checks) != len(want) {
+		t.Fatalf("len(Checks) = %d, want %d", len(readiness.Checks), len(want))
+	}
+	for i, check := range readiness.Checks {
+		if check.DurationMS < 0 {
+			t.Errorf("Checks[%d].DurationMS = %v, want >= 0", i, check.DurationMS)
+		}
+		check.DurationMS = 0
+		if check != want[i] {
+			t.Errorf("Checks[%d] = %+v, want %+v", i, check, want[i])
+		}
+	}
+}
+
+func TestHealthService_GetReadiness_RecordsDurations(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC))
+	service := services.NewHealthService("test-service", "1.2.3", clock.Now(), clock)
+	service.RegisterCheck("database", func(ctx context.Context) error {
+		clock.Advance(25 * time.Millisecond)
+		return nil
+	})
+	service.RegisterCheck("cache", func(ctx context.Context) error {
+		clock.Advance(40 * time.Millisecond)
+		return errors.New("connection refused")
+	})
+
+	readiness := service.GetReadiness(context.Background())
+
+	// The checks run concurrently on a shared clock, so each one sees at
+	// least its own advance and at most both.
+	for _, tt := range []struct {
+		name    string
+		healthy bool
+		min     float64
+	}{
+		{"database", true, 25},
+		{"cache", false, 40},
+	} {
+		var found bool
+		for _, check := range readiness.Checks {
+			if check.Name != tt.name {
+				continue
+			}
+			found = true
+			if check.Healthy != tt.healthy {
+				t.Errorf("%s Healthy = %t, want %t", tt.name, check.Healthy, tt.healthy)
+			}
+			if check.DurationMS < tt.min || check.DurationMS > 65 {
+				t.Errorf("%s DurationMS = %v, want between %v and 65", tt.name, check.DurationMS, tt.min)
+			}
+		}
+		if !found {
+			t.Errorf("no result for check %s", tt.name)
+		}
+	}
+}
+
+func TestHealthService_GetReadiness_PassesContextToChecks(t *testing.T) {
+	service := services.NewHealthService("test-service", "1.2.3", time.Now(), nil)
+	service.RegisterCheck("database", func(ctx context.Context) error { return ctx.Err() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if readiness := service.GetReadiness(ctx); readiness.Ready {
+		t.Error("Ready = true, want false when the context is cancelled")
+	}
+}
+
+func TestHealthService_GetReadiness_RunsChecksConcurrentlyWithTimeouts(t *testing.T) {
+	service := services.NewHealthService("test-service", "1.2.3", time.Now(), nil)
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	service.RegisterCheckWithTimeout("fast", func(ctx context.Context) error { return nil }, time.Second)
+	service.RegisterCheckWithTimeout("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, 50*time.Millisecond)
+	service.RegisterCheckWithTimeout("stuck", func(ctx context.Context) error {
+		// Ignores its context; the run must not wait for it.
+		<-release
+		return nil
+	}, 50*time.Millisecond)
+	service.RegisterCheckWithTimeout("failing", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}, time.Second)
+
+	start := time.Now()
+	readiness := service.GetReadiness(context.Background())
+	elapsed := time.Since(start)
+
+	if readiness.Ready {
+		t.Error("Ready = true, want false")
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("GetReadiness() took %v, want the slow checks bounded by their 50ms timeouts", elapsed)
+	}
+
+	want := []models.CheckResult{
+		{Name: "fast", Healthy: true},
+		{Name: "slow", Healthy: false, Reason: models.CheckReasonTimeout, Error: "check timed out"},
+		{Name: "stuck", Healthy: false, Reason: models.CheckReasonTimeout, Error: "check timed out"},
+		{Name: "failing", Healthy: false, Error: "connection refused"},
+	}
+	if len(readiness.Checks) != len(want) {
+		t.Fatalf("len(Checks) = %d, want %d", len(readiness.Checks), len(want))
+	}
+	for i, check := range readiness.Checks {
+		if check.DurationMS < 0 {
+			t.Errorf("Checks[%d].DurationMS = %v, want >= 0", i, check.DurationMS)
+		}
+		check.DurationMS = 0
+		if check != want[i] {
+			t.Errorf("Checks[%d] = %+v, want %+v", i, check, want[i])
+		}
+	}
+}
+
+func TestHealthService_GetReadiness_OverallDeadline(t *testing.T) {
+	service := services.NewHealthService("test-service", "1.2.3", time.Now(), nil)
+	service.SetReadinessTimeout(50 * time.Millisecond)
+	service.RegisterCheckWithTimeout("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, time.Minute)
+
+	start := time.Now()
+	readiness := service.GetReadiness(context.Background())
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("GetReadiness() took %v, want it bounded by the 50ms readiness timeout", elapsed)
+	}
+	if got := readiness.Checks[0].Reason; got != models.CheckReasonTimeout {
+		t.Errorf("Reason = %q, want %q", got, models.CheckReasonTimeout)
+	}
+}
+
+func TestHealthService_Maintenance(t *testing.T) {
+	service := services.NewHealthService("test-service", "1.2.3", time.Now(), nil)
+	service.RegisterCheck("database", func(ctx context.Context) error { return nil })
+
+	service.SetMaintenance(true)
+	if !service.Maintenance() {
+		t.Error("Maintenance() = false, want true")
+	}
+	if status := service.GetHealthStatus(context.Background()).Status; status != models.StatusMaintenance {
+		t.Errorf("Status = %q, want %q", status, models.StatusMaintenance)
+	}
+	readiness := service.GetReadiness(context.Background())
+	if readiness.Ready || !readiness.Maintenance {
+		t.Errorf("readiness = %+v, want not ready in maintenance", readiness)
+	}
+
+	service.SetMaintenance(false)
+	if readiness := service.GetReadiness(context.Background()); !readiness.Ready || readiness.Maintenance {
+		t.Errorf("readiness = %+v, want ready after maintenance", readiness)
+	}
+}
+
+func TestHealthService_GetReadiness_Cache(t *testing.T) {
+	clock := newFakeClock(time.Now())
+	service := services.NewHealthService("test-service", "1.2.3", clock.Now(), clock)
+	service.SetReadinessCacheTTL(2 * time.Second)
+
+	var runs atomic.Int32
+	service.RegisterCheck("database", func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	steps := []struct {
+		advance  time.Duration
+		wantRuns int32
+	}{
+		{0, 1},
+		{time.Second, 1},
+		{999 * time.Millisecond, 1},
+		{time.Millisecond, 2},
+		{time.Second, 2},
+	}
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		if !service.GetReadiness(context.Background()).Ready {
+			t.Fatalf("step %d: not ready", i)
+		}
+		if got := runs.Load(); got != step.wantRuns {
+			t.Errorf("step %d: checks ran %d times, want %d", i, got, step.wantRuns)
+		}
+	}
+}
+
+func TestHealthService_GetReadiness_CacheBypassedByMaintenance(t *testing.T) {
+	clock := newFakeClock(time.Now())
+	service := services.NewHealthService("test-service", "1.2.3", clock.Now(), clock)
+	service.SetReadinessCacheTTL(time.Minute)
+
+	var runs atomic.Int32
+	service.RegisterCheck("database", func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	if !service.GetReadiness(context.Background()).Ready {
+		t.Fatal("not ready before maintenance")
+	}
+
+	service.SetMaintenance(true)
+	if readiness := service.GetReadiness(context.Background()); readiness.Ready || !readiness.Maintenance {
+		t.Errorf("readiness = %+v, want cached result bypassed in maintenance", readiness)
+	}
+
+	service.SetMaintenance(false)
+	if !service.GetReadiness(context.Background()).Ready {
+		t.Error("not ready after maintenance")
+	}
+	if got := runs.Load(); got != 2 {
+		t.Errorf("checks ran %d times, want 2 (cache cleared when leaving maintenance)", got)
+	}
+}
+
+func TestHealthService_GetReadiness_NoCacheByDefault(t *testing.T) {
+	service := services.NewHealthService("test-service", "1.2.3", time.Now(), newFakeClock(time.Now()))
+
+	var runs atomic.Int32
+	service.RegisterCheck("database", func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	service.GetReadiness(context.Background())
+	service.GetReadiness(context.Background())
+	if got := runs.Load(); got != 2 {
+		t.Errorf("checks ran %d times, want 2", got)
+	}
+}
+
+func TestHealthService_History(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC))
+	service := services.NewHealthService("test-service", "1.2.3", clock.Now(), clock)
+	service.SetHistorySize(3)
+
+	var failing atomic.Bool
+	service.RegisterCheck("database", func(ctx context.Context) error {
+		if failing.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	if history := service.History(); history.Capacity != 3 || len(history.Events) != 0 {
+		t.Fatalf("initial history = %+v, want empty with capacity 3", history)
+	}
+
+	// Five evaluations alternate between ready and failing, one second apart.
+	for i := 0; i < 5; i++ {
+		failing.Store(i%2 == 1)
+		service.GetReadiness(context.Background())
+		clock.Advance(time.Second)
+	}
+
+	history := service.History()
+	if history.Capacity != 3 {
+		t.Errorf("Capacity = %d, want 3", history.Capacity)
+	}
+	start := time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC)
+	want := []models.ReadinessEvent{
+		{Ready: true, Timestamp: start.Add(2 * time.Second)},
+		{Ready: false, Timestamp: start.Add(3 * time.Second), FailedChecks: []string{"database"}},
+		{Ready: true, Timestamp: start.Add(4 * time.Second)},
+	}
+	if len(history.Events) != len(want) {
+		t.Fatalf("len(Events) = %d, want %d", len(history.Events), len(want))
+	}
+	for i, event := range history.Events {
+		if event.Ready != want[i].Ready || !event.Timestamp.Equal(want[i].Timestamp) || strings.Join(event.FailedChecks, ",") != strings.Join(want[i].FailedChecks, ",") {
+			t.Errorf("Events[%d] = %+v, want %+v", i, event, want[i])
+		}
+	}
+}
+
+func TestHealthService_History_SkipsCachedResults(t *testing.T) {
+	clock := newFakeClock(time.Now())
+	service := services.NewHealthService("test-service", "1.2.3", clock.Now(), clock)
+	service.SetReadinessCacheTTL(time.Minute)
+	service.RegisterCheck("database", func(ctx context.Context) error { return nil })
+
+	service.GetReadiness(context.Background())
+	service.GetReadiness(context.Background())
+	service.SetMaintenance(true)
+	service.GetReadiness(context.Background())
+
+	events := service.History().Events
+	if len(events) != 2 {
+		t.Fatalf("len(Events) = %d, want 2 (one evaluation, one maintenance response)", len(events))
+	}
+	if !events[0].Ready || events[1].Ready || !events[1].Maintenance {
+		t.Errorf("Events = %+v, want a ready evaluation followed by maintenance", events)
+	}
+}
@@ -0,0 +1,90 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/pkg/i18n"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+func TestNegotiate(t *testing.T) {
+	supported := []string{"de", "en", "fr"}
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"de", "de"},
+		{"de-AT", "de"},
+		{"ja, fr;q=0.8, de;q=0.5", "fr"},
+		{"fr;q=0, de;q=0.1", "de"},
+		{"ja", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := i18n.Negotiate(tt.header, supported); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	handler := newTestRouter()
+
+	tests := []struct {
+		name         string
+		language     string
+		wantLanguage string
+		wantMessage  string
+	}{
+		{"supported locale", "de-DE,de;q=0.9", "de", "Authentifizierung erforderlich"},
+		{"unsupported locale", "ja", "en", "Missing bearer token"},
+		{"no header", "", "en", "Missing bearer token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if tt.language != "" {
+				req.Header.Set("Accept-Language", tt.language)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Language"); got != tt.wantLanguage {
+				t.Errorf("Content-Language = %q, want %q", got, tt.wantLanguage)
+			}
+			var envelope response.ErrorEnvelope
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if envelope.Error.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", envelope.Error.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestLocalizedValidationErrors(t *testing.T) {
+	handler := newTestRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"password":"password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "fr")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if want := "Le nom d'utilisateur est obligatoire"; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("body = %s, want message %q", rec.Body.String(), want)
+	}
+}
@@ -0,0 +1,121 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// newIdempotentRegister returns the register handler wrapped with the
+// idempotency middleware, together with its user repository.
+func newIdempotentRegister(store middleware.IdempotencyStore) (http.HandlerFunc, repository.UserRepository) {
+	repo := repository.NewInMemoryUserRepository(services.DemoUser())
+	svc := services.NewAuthService(services.WithRepository(repo), services.WithTokenService(services.NewTokenService(testJWTSecret, services.TokenOptions{})), services.WithLogger(discardLogger()))
+	return middleware.Idempotency(handlers.NewAuthHandler(svc, nil).Register, store, time.Hour), repo
+}
+
+func sendRegister(handler http.HandlerFunc, key, body string) *httptest.ResponseRecorder {
+	req := newJSONRequest("/register", body)
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+// userCount returns the number of users in repo.
+func userCount(t *testing.T, repo repository.UserRepository) int {
+	t.Helper()
+	total, err := repo.Count(context.Background())
+	if err != nil {
+		t.Fatalf("Count() unexpected error: %v", err)
+	}
+	return total
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	handler, repo := newIdempotentRegister(middleware.NewInMemoryIdempotencyStore(nil))
+	body := `{"username":"alice","password":"S3cret-pass"}`
+
+	first := sendRegister(handler, "key-1", body)
+	second := sendRegister(handler, "key-1", body)
+
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want %d (body: %s)", first.Code, http.StatusCreated, first.Body.String())
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("second response = %d %s, want %d %s", second.Code, second.Body.String(), first.Code, first.Body.String())
+	}
+	if got := second.Header().Get(middleware.IdempotentReplayedHeader); got != "true" {
+		t.Errorf("%s = %q, want %q", middleware.IdempotentReplayedHeader, got, "true")
+	}
+	if got := first.Header().Get(middleware.IdempotentReplayedHeader); got != "" {
+		t.Errorf("first response %s = %q, want empty", middleware.IdempotentReplayedHeader, got)
+	}
+	if n := userCount(t, repo); n != 2 {
+		t.Errorf("users = %d, want 2 (demo user and alice)", n)
+	}
+}
+
+func TestIdempotency_WithoutKeyProcessesEveryRequest(t *testing.T) {
+	handler, _ := newIdempotentRegister(middleware.NewInMemoryIdempotencyStore(nil))
+	body := `{"username":"alice","password":"S3cret-pass"}`
+
+	if rec := sendRegister(handler, "", body); rec.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec := sendRegister(handler, "", body); rec.Code != http.StatusConflict {
+		t.Errorf("second status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestIdempotency_RejectsKeyReuseWithDifferentBody(t *testing.T) {
+	handler, repo := newIdempotentRegister(middleware.NewInMemoryIdempotencyStore(nil))
+
+	sendRegister(handler, "key-1", `{"username":"alice","password":"S3cret-pass"}`)
+	rec := sendRegister(handler, "key-1", `{"username":"bob","password":"S3cret-pass"}`)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if n := userCount(t, repo); n != 2 {
+		t.Errorf("users = %d, want 2", n)
+	}
+}
+
+func TestIdempotency_ExpiredKeyIsProcessedAgain(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC))
+	handler, _ := newIdempotentRegister(middleware.NewInMemoryIdempotencyStore(clock))
+	body := `{"username":"alice","password":"S3cret-pass"}`
+
+	sendRegister(handler, "key-1", body)
+	clock.Advance(time.Hour)
+	rec := sendRegister(handler, "key-1", body)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d (username taken by the first request)", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestIdempotency_DoesNotStoreServerErrors(t *testing.T) {
+	calls := 0
+	handler := middleware.Idempotency(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, middleware.NewInMemoryIdempotencyStore(nil), 0)
+
+	sendRegister(handler, "key-1", `{}`)
+	sendRegister(handler, "key-1", `{}`)
+
+	if calls != 2 {
+		t.Errorf("handler calls = %d, want 2", calls)
+	}
+}
@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// introspect calls POST /introspect as the admin and decodes the response.
+func introspect(t *testing.T, handler http.Handler, token string) models.IntrospectResponse {
+	t.Helper()
+
+	body, _ := json.Marshal(models.IntrospectRequest{Token: token})
+	caller := "Bearer " + loginForToken(t, handler, "admin", "password")
+	rec := sendWithCredentials(handler, http.MethodPost, "/introspect", "Authorization", caller, string(body))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /introspect status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp models.IntrospectResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode introspection: %v", err)
+	}
+	return resp
+}
+
+func TestIntrospect_ActiveToken(t *testing.T) {
+	handler := newSessionRouter()
+	token := loginForToken(t, handler, "admin", "password")
+
+	resp := introspect(t, handler, token)
+	if !resp.Active {
+		t.Fatal("Active = false, want true")
+	}
+	if resp.Subject != "1" {
+		t.Errorf("Subject = %q, want %q", resp.Subject, "1")
+	}
+	if resp.Role != "admin" {
+		t.Errorf("Role = %q, want %q", resp.Role, "admin")
+	}
+	if resp.TokenType != services.TokenTypeAccess {
+		t.Errorf("TokenType = %q, want %q", resp.TokenType, services.TokenTypeAccess)
+	}
+	if exp := time.Unix(resp.ExpiresAt, 0); exp.Before(time.Now()) {
+		t.Errorf("ExpiresAt = %v, want a future time", exp)
+	}
+}
+
+func TestIntrospect_InactiveTokens(t *testing.T) {
+	handler := newSessionRouter()
+	expired := signTestToken(t, services.Claims{
+		Username:  "admin",
+		Role:      "admin",
+		TokenType: services.TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		},
+	})
+
+	revoked := loginForToken(t, handler, "admin", "password")
+	req := httptest.NewRequest(http.MethodPost, "/logout-all", nil)
+	req.Header.Set("Authorization", "Bearer "+revoked)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("POST /logout-all status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", expired},
+		{"revoked", revoked},
+		{"malformed", "not-a-jwt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := introspect(t, handler, tt.token)
+			if resp != (models.IntrospectResponse{}) {
+				t.Errorf("introspection = %+v, want only active: false", resp)
+			}
+		})
+	}
+}
+
+func TestIntrospect_TokenRequired(t *testing.T) {
+	handler := newSessionRouter()
+
+	caller := "Bearer " + loginForToken(t, handler, "admin", "password")
+	rec := sendWithCredentials(handler, http.MethodPost, "/introspect", "Authorization", caller, `{}`)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if !strings.Contains(rec.Body.String(), models.ErrTokenRequired.Code) {
+		t.Errorf("body = %s, want code %s", rec.Body.String(), models.ErrTokenRequired.Code)
+	}
+}
+
+func TestIntrospect_RequiresClientAuth(t *testing.T) {
+	alice := models.User{ID: "2", Username: "alice", Role: models.RoleUser}
+	keys := services.NewAPIKeyService(repository.NewInMemoryAPIKeyStore(), repository.NewInMemoryUserRepository(services.DemoUser(), alice), nil)
+	deps := newTestDependencies()
+	deps.TokenService = services.NewAPIKeyTokenService(deps.TokenService, keys)
+	deps.APIKeyHandler = handlers.NewAPIKeyHandler(keys)
+	deps.IntrospectionHandler = handlers.NewIntrospectionHandler(deps.TokenService)
+	handler := router.NewRouter(deps)
+
+	token := loginForToken(t, handler, "admin", "password")
+	key := createAPIKey(t, handler, token)
+	userKey, err := keys.Create(context.Background(), alice, "ci")
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	user := signTestToken(t, services.Claims{
+		Username:         "alice",
+		Role:             models.RoleUser,
+		TokenType:        services.TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "2", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	})
+	body := `{"token":"` + token + `"}`
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{"anonymous", "Authorization", "", http.StatusUnauthorized},
+		{"invalid token", "Authorization", "Bearer not-a-jwt", http.StatusUnauthorized},
+		{"user token", "Authorization", "Bearer " + user, http.StatusForbidden},
+		{"admin token", "Authorization", "Bearer " + token, http.StatusOK},
+		{"user API key", "X-API-Key", userKey.Key, http.StatusForbidden},
+		{"admin API key", "X-API-Key", key.Key, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := sendWithCredentials(handler, http.MethodPost, "/introspect", tt.header, tt.value, body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
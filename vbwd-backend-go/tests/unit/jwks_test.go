@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// tokenKID returns the kid header of a signed token without verifying it.
+func tokenKID(t *testing.T, token string) string {
+	t.Helper()
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &services.Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified() unexpected error: %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestJWKSHandler_ServesSigningKey(t *testing.T) {
+	key := newTestRSAKey(t)
+	tokenService := services.NewRS256TokenService(key, services.TokenOptions{})
+
+	deps := newTestDependencies()
+	deps.JWKSHandler = handlers.NewJWKSHandler(tokenService.(services.KeySetProvider))
+	rec := httptest.NewRecorder()
+	router.NewRouter(deps).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var jwks models.JWKS
+	if err := json.NewDecoder(rec.Body).Decode(&jwks); err != nil {
+		t.Fatalf("decode JWKS: %v", err)
+	}
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("len(keys) = %d, want 1", len(jwks.Keys))
+	}
+
+	jwk := jwks.Keys[0]
+	if jwk.Kty != "RSA" || jwk.Use != "sig" || jwk.Alg != "RS256" {
+		t.Errorf("key = %+v, want kty RSA, use sig, alg RS256", jwk)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil || new(big.Int).SetBytes(n).Cmp(key.N) != 0 {
+		t.Errorf("n does not encode the public modulus (err %v)", err)
+	}
+	if jwk.E != "AQAB" {
+		t.Errorf("e = %q, want %q", jwk.E, "AQAB")
+	}
+
+	token, err := tokenService.Generate(models.User{ID: "1", Username: "admin"})
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+	if kid := tokenKID(t, token); kid == "" || kid != jwk.Kid {
+		t.Errorf("token kid = %q, want %q", kid, jwk.Kid)
+	}
+}
+
+func TestRouter_JWKSNotServedWithoutHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestTokenService_RS256KeyRotation(t *testing.T) {
+	oldKey := newTestRSAKey(t)
+	newKey := newTestRSAKey(t)
+	user := models.User{ID: "42", Username: "alice"}
+
+	oldToken, err := services.NewRS256TokenService(oldKey, services.TokenOptions{}).Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	rotated := services.NewRS256TokenService(newKey, services.TokenOptions{
+		VerificationKeys: []*rsa.PublicKey{&oldKey.PublicKey, &newKey.PublicKey},
+	})
+	newToken, err := rotated.Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	jwks := rotated.(services.KeySetProvider).JWKS()
+	wantKIDs := []string{services.RSAKeyID(&newKey.PublicKey), services.RSAKeyID(&oldKey.PublicKey)}
+	if len(jwks.Keys) != len(wantKIDs) {
+		t.Fatalf("len(keys) = %d, want %d", len(jwks.Keys), len(wantKIDs))
+	}
+	for i, want := range wantKIDs {
+		if jwks.Keys[i].Kid != want {
+			t.Errorf("keys[%d].kid = %q, want %q", i, jwks.Keys[i].Kid, want)
+		}
+	}
+	if kid := tokenKID(t, newToken); kid != wantKIDs[0] {
+		t.Errorf("token kid = %q, want current key %q", kid, wantKIDs[0])
+	}
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := rotated.Parse(token); err != nil {
+			t.Errorf("Parse(%s token) unexpected error: %v", name, err)
+		}
+	}
+
+	stranger, err := services.NewRS256TokenService(newTestRSAKey(t), services.TokenOptions{}).Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+	if _, err := rotated.Parse(stranger); !errors.Is(err, models.ErrInvalidToken) || !strings.Contains(err.Error(), "unknown key id") {
+		t.Errorf("Parse(unknown kid) error = %v, want unknown key id", err)
+	}
+}
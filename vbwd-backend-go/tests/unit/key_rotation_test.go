@@ -0,0 +1,183 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+const testRotationGrace = time.Hour
+
+func TestKeyRotation_HS256GraceWindow(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC))
+	service := services.NewTokenService(testJWTSecret, services.TokenOptions{AccessTTL: 24 * time.Hour, Clock: clock})
+	user := models.User{ID: "42", Username: "alice", Role: models.RoleUser}
+
+	before, err := service.Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+	first, err := service.(services.KeyRotator).Rotate(testRotationGrace)
+	if err != nil {
+		t.Fatalf("Rotate() unexpected error: %v", err)
+	}
+	if want := clock.Now().Add(testRotationGrace); !first.PreviousKeysValidUntil.Equal(want) {
+		t.Errorf("PreviousKeysValidUntil = %v, want %v", first.PreviousKeysValidUntil, want)
+	}
+
+	after, err := service.Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() after rotation unexpected error: %v", err)
+	}
+	if kid := tokenKID(t, after); kid != first.KeyID || kid == "" {
+		t.Errorf("kid = %q, want %q", kid, first.KeyID)
+	}
+
+	clock.Advance(testRotationGrace / 2)
+	second, err := service.(services.KeyRotator).Rotate(testRotationGrace)
+	if err != nil {
+		t.Fatalf("second Rotate() unexpected error: %v", err)
+	}
+	if second.KeyID == first.KeyID {
+		t.Errorf("second rotation reused key ID %q", first.KeyID)
+	}
+	latest, err := service.Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() after second rotation unexpected error: %v", err)
+	}
+
+	for _, step := range []struct {
+		name    string
+		advance time.Duration
+		token   string
+		wantErr bool
+	}{
+		{"pre-rotation token in grace", 0, before, false},
+		{"first rotated token in grace", 0, after, false},
+		{"pre-rotation token after grace", testRotationGrace / 2, before, true},
+		{"first rotated token still in its grace", 0, after, false},
+		{"first rotated token after grace", testRotationGrace / 2, after, true},
+		{"current token", 0, latest, false},
+	} {
+		clock.Advance(step.advance)
+		_, err := service.Parse(step.token)
+		if step.wantErr && !errors.Is(err, models.ErrInvalidToken) {
+			t.Errorf("%s: Parse() error = %v, want %v", step.name, err, models.ErrInvalidToken)
+		}
+		if !step.wantErr && err != nil {
+			t.Errorf("%s: Parse() unexpected error: %v", step.name, err)
+		}
+	}
+}
+
+func TestKeyRotation_RS256PublishesNewKey(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC))
+	service := services.NewRS256TokenService(newTestRSAKey(t), services.TokenOptions{AccessTTL: 24 * time.Hour, Clock: clock})
+	keys := service.(services.KeySetProvider)
+	oldKID := keys.JWKS().Keys[0].Kid
+	user := models.User{ID: "42", Username: "alice", Role: models.RoleUser}
+
+	before, err := service.Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+	rotated, err := service.(services.KeyRotator).Rotate(testRotationGrace)
+	if err != nil {
+		t.Fatalf("Rotate() unexpected error: %v", err)
+	}
+	after, err := service.Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() after rotation unexpected error: %v", err)
+	}
+	if kid := tokenKID(t, after); kid != rotated.KeyID {
+		t.Errorf("kid = %q, want %q", kid, rotated.KeyID)
+	}
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != rotated.KeyID || jwks.Keys[1].Kid != oldKID {
+		t.Errorf("JWKS during grace = %+v, want new key then old key", jwks.Keys)
+	}
+	if _, err := service.Parse(before); err != nil {
+		t.Errorf("Parse() of old token during grace unexpected error: %v", err)
+	}
+
+	clock.Advance(testRotationGrace)
+	jwks = keys.JWKS()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != rotated.KeyID {
+		t.Errorf("JWKS after grace = %+v, want only the new key", jwks.Keys)
+	}
+	if _, err := service.Parse(before); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("Parse() of old token after grace error = %v, want %v", err, models.ErrInvalidToken)
+	}
+	if _, err := service.Parse(after); err != nil {
+		t.Errorf("Parse() of new token unexpected error: %v", err)
+	}
+}
+
+func TestKeyRotation_VerifierCannotRotate(t *testing.T) {
+	key := newTestRSAKey(t)
+	verifier := services.NewRS256Verifier(&key.PublicKey, services.TokenOptions{})
+
+	if _, err := verifier.(services.KeyRotator).Rotate(testRotationGrace); !errors.Is(err, services.ErrSigningKeyUnavailable) {
+		t.Errorf("Rotate() error = %v, want %v", err, services.ErrSigningKeyUnavailable)
+	}
+}
+
+func TestRouter_RotateKey_RequiresAdmin(t *testing.T) {
+	deps := newTestDependencies()
+	rotated := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	deps.KeyRotationHandler = handlers.NewKeyRotationHandler(rotated.(services.KeyRotator), testRotationGrace)
+	handler := router.NewRouter(deps)
+	userToken := signTestToken(t, services.Claims{
+		Username:  "alice",
+		Role:      models.RoleUser,
+		TokenType: services.TokenTypeAccess,
+	})
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "anonymous", token: "", wantStatus: http.StatusUnauthorized},
+		{name: "user role", token: userToken, wantStatus: http.StatusForbidden},
+		{name: "admin", token: loginForToken(t, handler, "admin", "password"), wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/rotate-key", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var resp models.KeyRotationResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			token, err := rotated.Generate(services.DemoUser())
+			if err != nil {
+				t.Fatalf("Generate() unexpected error: %v", err)
+			}
+			if kid := tokenKID(t, token); kid != resp.KeyID {
+				t.Errorf("kid = %q, want rotated key %q", kid, resp.KeyID)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+)
+
+// syncBuffer is a buffer that goroutines other than the test may log to.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor waits until the buffer contains substr and returns its content.
+func (b *syncBuffer) waitFor(t *testing.T, substr string) string {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		got := b.String()
+		if strings.Contains(got, substr) {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("log = %q, want it to contain %q", got, substr)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// captureDefaultLogger redirects the default slog logger to an in-memory
+// JSON buffer for the duration of the test.
+func captureDefaultLogger(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	return captureDefaultLoggerAt(t, slog.LevelInfo)
+}
+
+// captureDefaultLoggerAt is captureDefaultLogger with a minimum level.
+func captureDefaultLoggerAt(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return &buf
+}
+
+func decodeLogEntry(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decode log entry %q: %v", buf.String(), err)
+	}
+	return entry
+}
+
+func TestLogging_RecordsRequestFields(t *testing.T) {
+	buf := captureDefaultLogger(t)
+
+	handler := middleware.Logging(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/register", nil)
+	req.RemoteAddr = "192.0.2.1:4321"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := decodeLogEntry(t, buf)
+	want := map[string]any{
+		"msg":         "http request",
+		"method":      "POST",
+		"path":        "/register",
+		"status":      float64(http.StatusCreated),
+		"bytes":       float64(5),
+		"remote_addr": "192.0.2.1:4321",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+	if _, ok := entry["duration"]; !ok {
+		t.Error("duration field missing")
+	}
+}
+
+func TestLogging_DefaultsStatusTo200(t *testing.T) {
+	buf := captureDefaultLogger(t)
+
+	handler := middleware.Logging(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if status := decodeLogEntry(t, buf)["status"]; status != float64(http.StatusOK) {
+		t.Errorf("status = %v, want %d", status, http.StatusOK)
+	}
+}
+
+func TestLogging_DebugLogsRedactedBodies(t *testing.T) {
+	handler := middleware.Logging(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"username":"admin","password":"hunter2"}` {
+			t.Errorf("handler read %s, want the unmodified body", body)
+		}
+		w.Write([]byte(`{"success":true,"token":"eyJhbGciOi"}`))
+	}))
+
+	tests := []struct {
+		name             string
+		level            slog.Level
+		wantRequestBody  any
+		wantResponseBody any
+	}{
+		{"info", slog.LevelInfo, nil, nil},
+		{"debug", slog.LevelDebug, `{"password":"[REDACTED]","username":"admin"}`, `{"success":true,"token":"[REDACTED]"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureDefaultLoggerAt(t, tt.level)
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"admin","password":"hunter2"}`))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "eyJhbGciOi") {
+				t.Fatalf("log leaks a secret: %s", buf.String())
+			}
+			entry := decodeLogEntry(t, buf)
+			if entry["request_body"] != tt.wantRequestBody {
+				t.Errorf("request_body = %v, want %v", entry["request_body"], tt.wantRequestBody)
+			}
+			if entry["response_body"] != tt.wantResponseBody {
+				t.Errorf("response_body = %v, want %v", entry["response_body"], tt.wantResponseBody)
+			}
+		})
+	}
+}
+
+func TestLogging_DebugOmitsUnloggableBodies(t *testing.T) {
+	buf := captureDefaultLoggerAt(t, slog.LevelDebug)
+	large := `{"data":"` + strings.Repeat("x", 8<<10) + `"}`
+
+	handler := middleware.Logging(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte("password=hunter2"))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(large)))
+
+	entry := decodeLogEntry(t, buf)
+	if body, _ := entry["request_body"].(string); !strings.HasPrefix(body, "[omitted") {
+		t.Errorf("request_body = %q, want an omission note", body)
+	}
+	if body, _ := entry["response_body"].(string); body != "[omitted: not JSON]" {
+		t.Errorf("response_body = %q, want %q", body, "[omitted: not JSON]")
+	}
+}
+
+func TestLogging_DebugLogsBodiesCompressedByGzip(t *testing.T) {
+	buf := captureDefaultLoggerAt(t, slog.LevelDebug)
+	padding := strings.Repeat("x", 2<<10)
+	handler := middleware.Logging(middleware.Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"padding":"` + padding + `","token":"eyJhbGciOi"}`))
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	if strings.Contains(buf.String(), "eyJhbGciOi") {
+		t.Fatalf("log leaks a secret: %s", buf.String())
+	}
+	entry := decodeLogEntry(t, buf)
+	want := `{"padding":"` + padding + `","token":"[REDACTED]"}`
+	if entry["response_body"] != want {
+		t.Errorf("response_body = %.80v, want the redacted uncompressed body", entry["response_body"])
+	}
+}
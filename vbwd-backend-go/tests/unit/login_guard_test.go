@@ -0,0 +1,72 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// loginFrom posts credentials to handler from the given client IP.
+func loginFrom(handler http.Handler, ip, username, password string) *httptest.ResponseRecorder {
+	body := fmt.Sprintf(`{"username":%q,"password":%q}`, username, password)
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":1234"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBlockFailedLogins_BlocksIPAcrossUsernames(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	blocker := services.NewIPBlocker(3, time.Minute, clock)
+	handler := middleware.BlockFailedLogins(blocker)(newTestAuthHandler().Login)
+
+	for i := 0; i < 3; i++ {
+		rec := loginFrom(handler, "203.0.113.7", fmt.Sprintf("user%d", i), "wrong-password")
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status = %d, want %d", i, rec.Code, http.StatusUnauthorized)
+		}
+	}
+
+	rec := loginFrom(handler, "203.0.113.7", "admin", "password")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("blocked IP: status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want %q", got, "60")
+	}
+
+	if rec := loginFrom(handler, "198.51.100.9", "admin", "password"); rec.Code != http.StatusOK {
+		t.Errorf("other IP: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	clock.Advance(time.Minute)
+	if rec := loginFrom(handler, "203.0.113.7", "admin", "password"); rec.Code != http.StatusOK {
+		t.Errorf("after block: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestIPBlocker_FailuresExpireWithWindow(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	blocker := services.NewIPBlocker(2, time.Minute, clock)
+
+	blocker.RecordFailure("203.0.113.7")
+	clock.Advance(time.Minute)
+	blocker.RecordFailure("203.0.113.7")
+
+	if got := blocker.RetryAfter("203.0.113.7"); got != 0 {
+		t.Errorf("RetryAfter() = %v, want 0 after the first failure expired", got)
+	}
+
+	blocker.RecordFailure("203.0.113.7")
+	if got := blocker.RetryAfter("203.0.113.7"); got != time.Minute {
+		t.Errorf("RetryAfter() = %v, want %v", got, time.Minute)
+	}
+}
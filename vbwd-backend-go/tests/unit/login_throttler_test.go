@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+func TestLoginThrottler_LocksAfterThreshold(t *testing.T) {
+	clock := newFakeClock(time.Now())
+	throttler := services.NewLoginThrottler(0, 0, clock)
+
+	for i := 0; i < services.DefaultMaxFailedAttempts-1; i++ {
+		throttler.RecordFailure("admin")
+	}
+	if throttler.IsLocked("admin") {
+		t.Fatal("IsLocked() = true before reaching threshold")
+	}
+
+	throttler.RecordFailure("admin")
+	if !throttler.IsLocked("admin") {
+		t.Fatal("IsLocked() = false after reaching threshold")
+	}
+	if throttler.IsLocked("other") {
+		t.Error("IsLocked() = true for unrelated username")
+	}
+
+	clock.Advance(services.DefaultLockoutWindow - time.Second)
+	if !throttler.IsLocked("admin") {
+		t.Error("IsLocked() = false before lockout window elapsed")
+	}
+
+	clock.Advance(time.Second)
+	if throttler.IsLocked("admin") {
+		t.Error("IsLocked() = true after lockout window elapsed")
+	}
+}
+
+func TestLoginThrottler_ResetClearsFailures(t *testing.T) {
+	throttler := services.NewLoginThrottler(2, time.Minute, nil)
+
+	throttler.RecordFailure("admin")
+	throttler.Reset("admin")
+	throttler.RecordFailure("admin")
+
+	if throttler.IsLocked("admin") {
+		t.Error("IsLocked() = true, want counter reset by successful login")
+	}
+}
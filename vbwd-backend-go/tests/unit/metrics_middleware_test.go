@@ -0,0 +1,127 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/dantweb/vbwd-backend-go/internal/metrics"
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+)
+
+// counterValue scrapes the default registry and returns the value of the
+// counter named name whose labels match labels, or 0 when none matches.
+func counterValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() unexpected error: %v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, pair := range metric.GetLabel() {
+				if labels[pair.GetName()] == pair.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetrics_CountsRequests(t *testing.T) {
+	handler := middleware.Metrics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.RecordRoute(r, "/metrics-test")
+		okHandler(w, r)
+	}))
+	labels := map[string]string{"method": "GET", "path": "/metrics-test", "status": "200"}
+	before := counterValue(t, "http_requests_total", labels)
+
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test", nil))
+	}
+
+	if got := counterValue(t, "http_requests_total", labels) - before; got != 3 {
+		t.Errorf("http_requests_total increment = %v, want 3", got)
+	}
+}
+
+func TestMetrics_RecordsUnmatchedPaths(t *testing.T) {
+	handler := middleware.Metrics(http.NotFoundHandler())
+	labels := map[string]string{"method": "GET", "path": metrics.UnmatchedPath, "status": "404"}
+	before := counterValue(t, "http_requests_total", labels)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no-such-route", nil))
+
+	if got := counterValue(t, "http_requests_total", labels) - before; got != 1 {
+		t.Errorf("http_requests_total increment = %v, want 1", got)
+	}
+	if got := counterValue(t, "http_requests_total", map[string]string{"path": "/no-such-route"}); got != 0 {
+		t.Errorf("http_requests_total for raw unmatched path = %v, want 0", got)
+	}
+}
+
+func TestRouter_MetricsLabelRoutePatterns(t *testing.T) {
+	handler := newTestRouter()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		labels map[string]string
+	}{
+		{"parameterised path", http.MethodDelete, "/users/metrics-random-id", map[string]string{"method": "DELETE", "path": "/users/{id}", "status": "401"}},
+		{"unknown method", "BREW", "/users/metrics-brew", map[string]string{"method": metrics.OtherMethod, "path": metrics.UnmatchedPath, "status": "405"}},
+		{"cleaned path", http.MethodGet, "/metrics-a/../health", map[string]string{"method": "GET", "path": metrics.UnmatchedPath, "status": "307"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := counterValue(t, "http_requests_total", tt.labels)
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			if got := counterValue(t, "http_requests_total", tt.labels) - before; got != 1 {
+				t.Errorf("http_requests_total%v increment = %v, want 1", tt.labels, got)
+			}
+			if got := counterValue(t, "http_requests_total", map[string]string{"path": tt.path}); got != 0 {
+				t.Errorf("http_requests_total for raw path = %v, want 0", got)
+			}
+			if tt.labels["method"] != tt.method {
+				if got := counterValue(t, "http_requests_total", map[string]string{"method": tt.method}); got != 0 {
+					t.Errorf("http_requests_total for raw method = %v, want 0", got)
+				}
+			}
+		})
+	}
+}
+
+func TestRouter_ExposesMetrics(t *testing.T) {
+	handler := newTestRouter()
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	for _, want := range []string{"http_requests_total", "http_request_duration_seconds", `path="/health"`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("body does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+// newMFARouter returns a router whose logins ask users with MFA enabled
+// for a TOTP code. Codes and tokens follow the returned clock.
+func newMFARouter() (http.Handler, *fakeClock) {
+	return newMFARouterWithAudit(nil)
+}
+
+// newMFARouterWithAudit is newMFARouter recording logins with audit.
+func newMFARouterWithAudit(audit services.AuditLogger) (http.Handler, *fakeClock) {
+	clock := newFakeClock(time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC))
+	tokens := services.NewTokenService(testJWTSecret, services.TokenOptions{Clock: clock})
+	mfa := services.NewMFAService(repository.NewInMemoryMFAStore(), "test-service", clock)
+	authService := services.NewAuthService(
+		services.WithRepository(repository.NewInMemoryUserRepository(services.DemoUser())),
+		services.WithTokenService(tokens),
+		services.WithLogger(discardLogger()),
+		services.WithMFA(mfa),
+	)
+
+	deps := newTestDependencies()
+	deps.AuthHandler = handlers.NewAuthHandler(authService, audit)
+	deps.AuthHandlerV2 = handlers.NewAuthHandlerV2(authService, tokens, audit)
+	deps.MFAHandler = handlers.NewMFAHandler(mfa, authService, tokens, audit)
+	deps.TokenService = tokens
+	return router.NewRouter(deps), clock
+}
+
+// postMFA sends a JSON POST, authenticated when token is set.
+func postMFA(handler http.Handler, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// enableMFA enrolls the admin and confirms the secret, returning it.
+func enableMFA(t *testing.T, handler http.Handler, clock *fakeClock) string {
+	t.Helper()
+
+	token := loginForToken(t, handler, "admin", "password")
+	rec := postMFA(handler, "/mfa/enroll", token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enroll status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var enrollment models.MFAEnrollResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &enrollment); err != nil {
+		t.Fatalf("decode enrollment: %v", err)
+	}
+
+	rec = postMFA(handler, "/mfa/verify", token, `{"code":"`+totpCode(t, enrollment.Secret, clock)+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("verify status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	return enrollment.Secret
+}
+
+// totpCode moves clock to the next TOTP period and returns its code, since
+// each code is only accepted once.
+func totpCode(t *testing.T, secret string, clock *fakeClock) string {
+	t.Helper()
+
+	clock.Advance(30 * time.Second)
+	code, err := totp.GenerateCode(secret, clock.Now())
+	if err != nil {
+		t.Fatalf("generate code: %v", err)
+	}
+	return code
+}
+
+// mfaChallenge logs the admin in and returns the challenge token.
+func mfaChallenge(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	rec := postMFA(handler, "/login", "", `{"username":"admin","password":"password"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp models.LoginResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode login response: %v", err)
+	}
+	if !resp.MFARequired || resp.MFAToken == "" || resp.Token != "" || resp.RefreshToken != "" {
+		t.Fatalf("login response = %+v, want only an MFA challenge", resp)
+	}
+	return resp.MFAToken
+}
+
+func assertErrorCode(t *testing.T, rec *httptest.ResponseRecorder, status int, code *models.CodedError) {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d (body: %s)", rec.Code, status, rec.Body.String())
+	}
+	var envelope response.ErrorEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+	}
+	if string(envelope.Error.Code) != code.Code {
+		t.Errorf("code = %s, want %s", envelope.Error.Code, code.Code)
+	}
+}
+
+func TestMFA_Enrollment(t *testing.T) {
+	handler, clock := newMFARouter()
+	token := loginForToken(t, handler, "admin", "password")
+
+	assertErrorCode(t, postMFA(handler, "/mfa/verify", token, `{"code":"123456"}`), http.StatusConflict, models.ErrMFANotEnrolled)
+
+	rec := postMFA(handler, "/mfa/enroll", token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enroll status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var enrollment models.MFAEnrollResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &enrollment); err != nil {
+		t.Fatalf("decode enrollment: %v", err)
+	}
+	if enrollment.Secret == "" || !strings.HasPrefix(enrollment.URL, "otpauth://totp/test-service:admin?") {
+		t.Errorf("enrollment = %+v, want a secret and an otpauth URL for test-service:admin", enrollment)
+	}
+
+	// A pending secret does not change logins yet.
+	if token := loginForToken(t, handler, "admin", "password"); token == "" {
+		t.Error("login with a pending secret returned no token")
+	}
+
+	assertErrorCode(t, postMFA(handler, "/mfa/verify", token, `{"code":"000000"}`), http.StatusUnauthorized, models.ErrInvalidMFACode)
+
+	rec = postMFA(handler, "/mfa/verify", token, `{"code":"`+totpCode(t, enrollment.Secret, clock)+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("verify status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	assertErrorCode(t, postMFA(handler, "/mfa/enroll", token, ""), http.StatusConflict, models.ErrMFAAlreadyEnabled)
+}
+
+func TestMFA_EnrollRequiresAuth(t *testing.T) {
+	handler, _ := newMFARouter()
+
+	if rec := postMFA(handler, "/mfa/enroll", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestMFA_LoginWithValidCode(t *testing.T) {
+	handler, clock := newMFARouter()
+	secret := enableMFA(t, handler, clock)
+	challenge := mfaChallenge(t, handler)
+
+	// The challenge token is not an access token.
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+challenge)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("whoami with challenge token status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec = postMFA(handler, "/mfa/login", "", `{"mfa_token":"`+challenge+`","code":"`+totpCode(t, secret, clock)+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("mfa login status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp models.LoginResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode login response: %v", err)
+	}
+	if resp.Token == "" || resp.RefreshToken == "" || resp.MFARequired {
+		t.Fatalf("mfa login response = %+v, want tokens", resp)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+resp.Token)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("whoami status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestMFA_LoginWithInvalidCode(t *testing.T) {
+	handler, clock := newMFARouter()
+	secret := enableMFA(t, handler, clock)
+	challenge := mfaChallenge(t, handler)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   *models.CodedError
+	}{
+		{"wrong code", `{"mfa_token":"` + challenge + `","code":"000000"}`, http.StatusUnauthorized, models.ErrInvalidMFACode},
+		{"malformed code", `{"mfa_token":"` + challenge + `","code":"12"}`, http.StatusUnauthorized, models.ErrInvalidMFACode},
+		{"access token instead of challenge", `{"mfa_token":"` + loginWithCode(t, handler, secret, clock) + `","code":"` + totpCode(t, secret, clock) + `"}`, http.StatusUnauthorized, models.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorCode(t, postMFA(handler, "/mfa/login", "", tt.body), tt.wantStatus, tt.wantCode)
+		})
+	}
+
+	rec := postMFA(handler, "/mfa/login", "", `{"mfa_token":"`+challenge+`"}`)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), models.ErrMFACodeRequired.Code) {
+		t.Errorf("missing code: status %d body %s, want 422 with %s", rec.Code, rec.Body.String(), models.ErrMFACodeRequired.Code)
+	}
+}
+
+// loginWithCode completes an MFA login and returns the access token.
+func loginWithCode(t *testing.T, handler http.Handler, secret string, clock *fakeClock) string {
+	t.Helper()
+
+	rec := postMFA(handler, "/mfa/login", "", `{"mfa_token":"`+mfaChallenge(t, handler)+`","code":"`+totpCode(t, secret, clock)+`"}`)
+	var resp models.LoginResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Token == "" {
+		t.Fatalf("mfa login: status %d body %s", rec.Code, rec.Body.String())
+	}
+	return resp.Token
+}
+
+func TestMFA_CodesCannotBeReplayed(t *testing.T) {
+	handler, clock := newMFARouter()
+	token := loginForToken(t, handler, "admin", "password")
+	rec := postMFA(handler, "/mfa/enroll", token, "")
+	var enrollment models.MFAEnrollResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &enrollment); err != nil {
+		t.Fatalf("decode enrollment: %v", err)
+	}
+	confirmed := totpCode(t, enrollment.Secret, clock)
+	if rec := postMFA(handler, "/mfa/verify", token, `{"code":"`+confirmed+`"}`); rec.Code != http.StatusOK {
+		t.Fatalf("verify status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	challenge := mfaChallenge(t, handler)
+	login := func(code string) *httptest.ResponseRecorder {
+		return postMFA(handler, "/mfa/login", "", `{"mfa_token":"`+challenge+`","code":"`+code+`"}`)
+	}
+	assertErrorCode(t, login(confirmed), http.StatusUnauthorized, models.ErrInvalidMFACode)
+
+	code := totpCode(t, enrollment.Secret, clock)
+	if rec := login(code); rec.Code != http.StatusOK {
+		t.Fatalf("mfa login status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	// The code of the previous period is still within the skew window but
+	// older than the accepted one.
+	clock.Advance(10 * time.Second)
+	assertErrorCode(t, login(code), http.StatusUnauthorized, models.ErrInvalidMFACode)
+	assertErrorCode(t, login(confirmed), http.StatusUnauthorized, models.ErrInvalidMFACode)
+}
+
+func TestMFA_ChallengeExpires(t *testing.T) {
+	handler, clock := newMFARouter()
+	secret := enableMFA(t, handler, clock)
+	challenge := mfaChallenge(t, handler)
+
+	clock.Advance(services.DefaultMFAChallengeTTL + time.Second)
+	rec := postMFA(handler, "/mfa/login", "", `{"mfa_token":"`+challenge+`","code":"`+totpCode(t, secret, clock)+`"}`)
+	assertErrorCode(t, rec, http.StatusUnauthorized, models.ErrInvalidToken)
+}
+
+func TestMFA_WrongCodesLockTheAccount(t *testing.T) {
+	handler, clock := newMFARouter()
+	secret := enableMFA(t, handler, clock)
+	challenge := mfaChallenge(t, handler)
+
+	for i := 0; i < services.DefaultMaxFailedAttempts; i++ {
+		postMFA(handler, "/mfa/login", "", `{"mfa_token":"`+challenge+`","code":"000000"}`)
+	}
+	rec := postMFA(handler, "/mfa/login", "", `{"mfa_token":"`+challenge+`","code":"`+totpCode(t, secret, clock)+`"}`)
+	assertErrorCode(t, rec, http.StatusLocked, models.ErrAccountLocked)
+}
+
+func TestMFA_LoginV2ReturnsChallenge(t *testing.T) {
+	handler, clock := newMFARouter()
+	enableMFA(t, handler, clock)
+
+	rec := postMFA(handler, "/v2/login", "", `{"username":"admin","password":"password"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp models.LoginResponseV2
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !resp.MFARequired || resp.MFAToken == nil || resp.Token != nil {
+		t.Fatalf("response = %+v, want only an MFA challenge", resp)
+	}
+	if want := clock.Now().Add(services.DefaultMFAChallengeTTL); !resp.MFAToken.ExpiresAt.Equal(want) {
+		t.Errorf("mfa_token expires_at = %v, want %v", resp.MFAToken.ExpiresAt, want)
+	}
+}
+
+func TestMFA_LoginAudit(t *testing.T) {
+	audit := &fakeAuditLogger{}
+	handler, clock := newMFARouterWithAudit(audit)
+	secret := enableMFA(t, handler, clock)
+	audit.logins = nil
+
+	challenge := mfaChallenge(t, handler)
+	postMFA(handler, "/v2/login", "", `{"username":"admin","password":"password"}`)
+	if len(audit.logins) != 0 {
+		t.Fatalf("challenges recorded as %+v, want no record before the code", audit.logins)
+	}
+
+	postMFA(handler, "/mfa/login", "", `{"mfa_token":"`+challenge+`","code":"000000"}`)
+	postMFA(handler, "/mfa/login", "", `{"mfa_token":"`+challenge+`","code":"`+totpCode(t, secret, clock)+`"}`)
+	postMFA(handler, "/mfa/login", "", `{"mfa_token":"forged","code":"`+totpCode(t, secret, clock)+`"}`)
+
+	want := []loginRecord{
+		{username: "admin", success: false, ip: "192.0.2.1"},
+		{username: "admin", success: true, ip: "192.0.2.1"},
+		{username: "", success: false, ip: "192.0.2.1"},
+	}
+	if len(audit.logins) != len(want) {
+		t.Fatalf("recorded %+v, want %+v", audit.logins, want)
+	}
+	for i := range want {
+		if audit.logins[i] != want[i] {
+			t.Errorf("login %d = %+v, want %+v", i, audit.logins[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+)
+
+func TestLoginRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     models.LoginRequest
+		wantErr error
+	}{
+		{"valid request", models.LoginRequest{Username: "admin", Password: "password"}, nil},
+		{"missing username", models.LoginRequest{Password: "password"}, models.ErrUsernameRequired},
+		{"missing password", models.LoginRequest{Username: "admin"}, models.ErrPasswordRequired},
+		{"empty request", models.LoginRequest{}, models.ErrUsernameRequired},
+		{"username too short", models.LoginRequest{Username: "ab", Password: "password"}, models.ErrUsernameTooShort},
+		{"email as username", models.LoginRequest{Username: "alice@example.com", Password: "password"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoginRequest_Validate_CollectsAllViolations(t *testing.T) {
+	req := models.LoginRequest{}
+
+	err := req.Validate()
+
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() error = %T, want *models.ValidationError", err)
+	}
+	if len(verr.Errors) != 2 {
+		t.Fatalf("len(Errors) = %d, want 2", len(verr.Errors))
+	}
+	if verr.Errors[0].Field != "username" || verr.Errors[1].Field != "password" {
+		t.Errorf("fields = [%s %s], want [username password]", verr.Errors[0].Field, verr.Errors[1].Field)
+	}
+	if !errors.Is(err, models.ErrUsernameRequired) || !errors.Is(err, models.ErrPasswordRequired) {
+		t.Error("errors.Is() does not match both sentinels")
+	}
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		t.Error("errors.Is() matched an unrelated sentinel")
+	}
+}
+
+func TestRegisterRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     models.RegisterRequest
+		wantErr error
+	}{
+		{"valid request", models.RegisterRequest{Username: "alice", Password: "longenough"}, nil},
+		{"missing username", models.RegisterRequest{Password: "longenough"}, models.ErrUsernameRequired},
+		{"username too short", models.RegisterRequest{Username: "al", Password: "longenough"}, models.ErrUsernameLength},
+		{"username too long", models.RegisterRequest{Username: strings.Repeat("a", 33), Password: "longenough"}, models.ErrUsernameLength},
+		{"missing password", models.RegisterRequest{Username: "alice"}, models.ErrPasswordRequired},
+		{"weak password", models.RegisterRequest{Username: "alice", Password: "short"}, models.ErrPasswordTooShort},
+		{"valid email", models.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: "longenough"}, nil},
+		{"malformed email", models.RegisterRequest{Username: "alice", Email: "not-an-email", Password: "longenough"}, models.ErrInvalidEmail},
+		{"email with display name", models.RegisterRequest{Username: "alice", Email: "Alice <alice@example.com>", Password: "longenough"}, models.ErrInvalidEmail},
+		{"email without domain dot", models.RegisterRequest{Username: "alice", Email: "alice@example", Password: "longenough"}, models.ErrInvalidEmail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStruct_FieldErrors(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name string
+		req  any
+		want []models.FieldError
+	}{
+		{"valid register", &models.RegisterRequest{Username: "alice", Email: "Alice@Example.com", Password: "S3cret-pass"}, nil},
+		{
+			name: "register violations",
+			req:  &models.RegisterRequest{Username: "ab", Email: "not-an-email", Password: "short"},
+			want: []models.FieldError{
+				models.NewFieldError("username", models.ErrUsernameLength),
+				models.NewFieldError("email", models.ErrInvalidEmail),
+				models.NewFieldError("password", models.ErrPasswordTooShort),
+			},
+		},
+		{
+			name: "register username too long",
+			req:  &models.RegisterRequest{Username: strings.Repeat("é", models.MaxUsernameLength+1), Password: "S3cret-pass"},
+			want: []models.FieldError{models.NewFieldError("username", models.ErrUsernameLength)},
+		},
+		{
+			name: "register username counts runes",
+			req:  &models.RegisterRequest{Username: strings.Repeat("é", models.MaxUsernameLength), Password: "S3cret-pass"},
+		},
+		{
+			name: "login too short",
+			req:  &models.LoginRequest{Username: "ab"},
+			want: []models.FieldError{
+				models.NewFieldError("username", models.ErrUsernameTooShort),
+				models.NewFieldError("password", models.ErrPasswordRequired),
+			},
+		},
+		{
+			name: "forgot password",
+			req:  &models.ForgotPasswordRequest{Email: "Alice <alice@example.com>"},
+			want: []models.FieldError{models.NewFieldError("email", models.ErrInvalidEmail)},
+		},
+		{
+			name: "reset password",
+			req:  &models.ResetPasswordRequest{},
+			want: []models.FieldError{
+				models.NewFieldError("token", models.ErrResetTokenRequired),
+				models.NewFieldError("new_password", models.ErrNewPasswordRequired),
+			},
+		},
+		{"maintenance set", &models.MaintenanceRequest{Enabled: &enabled}, nil},
+		{
+			name: "maintenance missing",
+			req:  &models.MaintenanceRequest{},
+			want: []models.FieldError{models.NewFieldError("enabled", models.ErrEnabledRequired)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.ValidateStruct(tt.req)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("ValidateStruct() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var verr *models.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidateStruct() error = %v, want *models.ValidationError", err)
+			}
+			if len(verr.Errors) != len(tt.want) {
+				t.Fatalf("errors = %+v, want %+v", verr.Errors, tt.want)
+			}
+			for i, want := range tt.want {
+				got := verr.Errors[i]
+				if got.Field != want.Field || got.Code != want.Code || got.Message != want.Message {
+					t.Errorf("errors[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateStruct_UnmappedTag(t *testing.T) {
+	type sample struct {
+		Count int `json:"count" validate:"gte=1"`
+	}
+
+	err := models.ValidateStruct(&sample{})
+
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 1 {
+		t.Fatalf("ValidateStruct() error = %v, want one field error", err)
+	}
+	if got := verr.Errors[0]; got.Field != "count" || got.Code != models.ErrInvalidField.Code || got.Message != "count failed the gte=1 check" {
+		t.Errorf("error = %+v, want INVALID_FIELD for count", got)
+	}
+	if !errors.Is(err, models.ErrInvalidField) {
+		t.Error("errors.Is(err, ErrInvalidField) = false, want true")
+	}
+}
+
+func TestCodedError_Sentinels(t *testing.T) {
+	tests := []struct {
+		err        *models.CodedError
+		wantCode   string
+		wantStatus int
+	}{
+		{models.ErrInvalidCredentials, "INVALID_CREDENTIALS", http.StatusUnauthorized},
+		{models.ErrUserNotFound, "USER_NOT_FOUND", http.StatusNotFound},
+		{models.ErrUsernameRequired, "USERNAME_REQUIRED", http.StatusBadRequest},
+		{models.ErrPasswordRequired, "PASSWORD_REQUIRED", http.StatusBadRequest},
+		{models.ErrRefreshTokenRequired, "REFRESH_TOKEN_REQUIRED", http.StatusBadRequest},
+		{models.ErrUsernameLength, "USERNAME_LENGTH", http.StatusBadRequest},
+		{models.ErrPasswordTooShort, "PASSWORD_TOO_SHORT", http.StatusBadRequest},
+		{models.ErrUserExists, "USER_EXISTS", http.StatusConflict},
+		{models.ErrAccountLocked, "ACCOUNT_LOCKED", http.StatusLocked},
+		{models.ErrInvalidToken, "INVALID_TOKEN", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCode, func(t *testing.T) {
+			if tt.err.ErrorCode() != tt.wantCode {
+				t.Errorf("ErrorCode() = %q, want %q", tt.err.ErrorCode(), tt.wantCode)
+			}
+			if tt.err.HTTPStatus() != tt.wantStatus {
+				t.Errorf("HTTPStatus() = %d, want %d", tt.err.HTTPStatus(), tt.wantStatus)
+			}
+
+			wrapped := fmt.Errorf("context: %w", tt.err)
+			if !errors.Is(wrapped, tt.err) {
+				t.Error("errors.Is() = false for wrapped sentinel")
+			}
+		})
+	}
+}
+
+func TestToDTO_OmitsPassword(t *testing.T) {
+	user := models.User{ID: "7", Username: "alice", Password: "$2a$10$secret-hash", Role: models.RoleUser}
+
+	data, err := json.Marshal(models.ToDTO(user))
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if _, ok := fields["password"]; ok {
+		t.Errorf("DTO JSON has a password key: %s", data)
+	}
+	want := map[string]any{"id": "7", "username": "alice", "role": models.RoleUser}
+	if len(fields) != len(want) {
+		t.Errorf("DTO JSON = %s, want only %v", data, want)
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Errorf("%s = %v, want %v", key, fields[key], value)
+		}
+	}
+}
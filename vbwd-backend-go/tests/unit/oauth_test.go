@@ -0,0 +1,352 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// newFakeOIDCServer serves discovery, token and UserInfo endpoints. The
+// code "good-code" is exchanged for an access token whose UserInfo is
+// userInfo; other codes are rejected.
+func newFakeOIDCServer(t *testing.T, userInfo map[string]any) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"userinfo_endpoint":      srv.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "authorization_code" || r.FormValue("code") != "good-code" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		if user, pass, _ := r.BasicAuth(); user != "client-id" || pass != "client-secret" {
+			t.Errorf("token request client = %q/%q, want client-id/client-secret", user, pass)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"provider-access-token","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer provider-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(userInfo)
+	})
+	return srv
+}
+
+// newOAuthRouter returns a router with an OIDC provider named "example"
+// backed by srv and a local user alice@example.com, whom the identity
+// "ext-42" is linked to. The auth service is further configured by opts.
+func newOAuthRouter(t *testing.T, srv *httptest.Server, opts ...services.AuthOption) (http.Handler, services.TokenService) {
+	t.Helper()
+
+	provider, err := services.NewOIDCProvider(context.Background(), services.OIDCConfig{
+		Name:         "example",
+		DiscoveryURL: srv.URL + "/.well-known/openid-configuration",
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "https://api.example.com/auth/example/callback",
+		HTTPClient:   srv.Client(),
+	})
+	if err != nil {
+		t.Fatalf("NewOIDCProvider() unexpected error: %v", err)
+	}
+
+	links := repository.NewInMemoryIdentityLinkStore()
+	if err := links.Link(context.Background(), models.IdentityLink{Provider: "example", Subject: "ext-42", UserID: "7", Username: "alice"}); err != nil {
+		t.Fatalf("Link() unexpected error: %v", err)
+	}
+
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	authService := services.NewAuthService(append([]services.AuthOption{
+		services.WithIdentityLinks(links),
+		services.WithRepository(repository.NewInMemoryUserRepository(
+			services.DemoUser(),
+			models.User{ID: "7", Username: "alice", Email: "alice@example.com", Role: models.RoleUser},
+		)),
+		services.WithTokenService(tokenService),
+		services.WithLogger(discardLogger()),
+	}, opts...)...)
+
+	deps := newTestDependencies()
+	deps.OAuthHandler = handlers.NewOAuthHandler(authService, provider)
+	deps.TokenService = tokenService
+	return router.NewRouter(deps), tokenService
+}
+
+// startOAuthLogin calls the login endpoint and returns the state cookie
+// and the state sent to the provider.
+func startOAuthLogin(t *testing.T, handler http.Handler, srv *httptest.Server) (*http.Cookie, string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/example/login", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want %d", rec.Code, http.StatusFound)
+	}
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("parse Location: %v", err)
+	}
+	if got := location.Scheme + "://" + location.Host + location.Path; got != srv.URL+"/authorize" {
+		t.Errorf("redirect = %q, want %q", got, srv.URL+"/authorize")
+	}
+	query := location.Query()
+	if query.Get("client_id") != "client-id" || query.Get("response_type") != "code" {
+		t.Errorf("redirect query = %v, want client_id and response_type=code", query)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %v, want one HttpOnly state cookie", cookies)
+	}
+	if cookies[0].Value != query.Get("state") {
+		t.Errorf("cookie state = %q, want redirect state %q", cookies[0].Value, query.Get("state"))
+	}
+	return cookies[0], query.Get("state")
+}
+
+// oauthCallback calls the callback endpoint with query and cookie.
+func oauthCallback(handler http.Handler, query string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/auth/example/callback?"+query, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOAuth_CallbackIssuesLocalToken(t *testing.T) {
+	srv := newFakeOIDCServer(t, map[string]any{"sub": "ext-42", "email": "Alice@Example.com", "email_verified": true})
+	handler, tokenService := newOAuthRouter(t, srv)
+	cookie, state := startOAuthLogin(t, handler, srv)
+
+	rec := oauthCallback(handler, "code=good-code&state="+url.QueryEscape(state), cookie)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("callback status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var resp models.LoginResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode login response: %v", err)
+	}
+	claims, err := tokenService.Parse(resp.Token)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if claims.Subject != "7" || claims.Username != "alice" {
+		t.Errorf("token subject, username = %q, %q, want 7, alice", claims.Subject, claims.Username)
+	}
+	if resp.RefreshToken == "" {
+		t.Error("RefreshToken is empty")
+	}
+}
+
+func TestOAuth_CallbackRequiresMFA(t *testing.T) {
+	ctx := context.Background()
+	mfa := services.NewMFAService(repository.NewInMemoryMFAStore(), "test-service", nil)
+	enrollment, err := mfa.Enroll(ctx, "7", "alice")
+	if err != nil {
+		t.Fatalf("Enroll() unexpected error: %v", err)
+	}
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+	if err != nil {
+		t.Fatalf("generate code: %v", err)
+	}
+	if err := mfa.Confirm(ctx, "7", code); err != nil {
+		t.Fatalf("Confirm() unexpected error: %v", err)
+	}
+
+	srv := newFakeOIDCServer(t, map[string]any{"sub": "ext-42", "email": "alice@example.com", "email_verified": true})
+	handler, _ := newOAuthRouter(t, srv, services.WithMFA(mfa))
+	cookie, state := startOAuthLogin(t, handler, srv)
+
+	rec := oauthCallback(handler, "code=good-code&state="+url.QueryEscape(state), cookie)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("callback status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp models.LoginResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode login response: %v", err)
+	}
+	if !resp.MFARequired || resp.MFAToken == "" || resp.Token != "" || resp.RefreshToken != "" {
+		t.Errorf("callback response = %+v, want only an MFA challenge", resp)
+	}
+}
+
+func TestOAuth_CallbackFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		userInfo   map[string]any
+		query      func(state string) string
+		withCookie bool
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "state mismatch",
+			userInfo:   map[string]any{"sub": "ext-42", "email": "alice@example.com", "email_verified": true},
+			query:      func(string) string { return "code=good-code&state=forged" },
+			withCookie: true,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing state cookie",
+			userInfo:   map[string]any{"sub": "ext-42", "email": "alice@example.com", "email_verified": true},
+			query:      func(state string) string { return "code=good-code&state=" + url.QueryEscape(state) },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejected code",
+			userInfo:   map[string]any{"sub": "ext-42", "email": "alice@example.com", "email_verified": true},
+			query:      func(state string) string { return "code=bad-code&state=" + url.QueryEscape(state) },
+			withCookie: true,
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "not linked",
+			userInfo:   map[string]any{"sub": "ext-43", "email": "mallory@example.com", "email_verified": true},
+			query:      func(state string) string { return "code=good-code&state=" + url.QueryEscape(state) },
+			withCookie: true,
+			wantStatus: http.StatusForbidden,
+			wantCode:   models.ErrIdentityNotLinked.Code,
+		},
+		{
+			name:       "email of an account",
+			userInfo:   map[string]any{"sub": "ext-44", "email": "alice@example.com", "email_verified": true},
+			query:      func(state string) string { return "code=good-code&state=" + url.QueryEscape(state) },
+			withCookie: true,
+			wantStatus: http.StatusForbidden,
+			wantCode:   models.ErrIdentityNotLinked.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFakeOIDCServer(t, tt.userInfo)
+			handler, _ := newOAuthRouter(t, srv)
+			cookie, state := startOAuthLogin(t, handler, srv)
+			if !tt.withCookie {
+				cookie = nil
+			}
+
+			rec := oauthCallback(handler, tt.query(state), cookie)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("callback status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantCode != "" && !strings.Contains(rec.Body.String(), tt.wantCode) {
+				t.Errorf("body = %s, want code %s", rec.Body.String(), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOAuth_UnknownProvider(t *testing.T) {
+	srv := newFakeOIDCServer(t, nil)
+	handler, _ := newOAuthRouter(t, srv)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/other/login", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+// startOAuthLink calls the link endpoint with bearer and returns the state
+// and link cookies and the state sent to the provider.
+func startOAuthLink(t *testing.T, handler http.Handler, bearer string) ([]*http.Cookie, string) {
+	t.Helper()
+
+	rec := sendWithCredentials(handler, http.MethodPost, "/auth/example/link", "Authorization", "Bearer "+bearer, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("link status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp models.OAuthLinkResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode link response: %v", err)
+	}
+	location, err := url.Parse(resp.AuthorizationURL)
+	if err != nil {
+		t.Fatalf("parse authorization_url: %v", err)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("cookies = %v, want the state and link cookies", cookies)
+	}
+	return cookies, location.Query().Get("state")
+}
+
+func TestOAuth_Link(t *testing.T) {
+	srv := newFakeOIDCServer(t, map[string]any{"sub": "ext-50", "email": "admin@example.com", "email_verified": true})
+	handler, tokenService := newOAuthRouter(t, srv)
+	login := func() *httptest.ResponseRecorder {
+		cookie, state := startOAuthLogin(t, handler, srv)
+		return oauthCallback(handler, "code=good-code&state="+url.QueryEscape(state), cookie)
+	}
+	link := func(user models.User) *httptest.ResponseRecorder {
+		bearer, err := tokenService.Generate(user)
+		if err != nil {
+			t.Fatalf("Generate() unexpected error: %v", err)
+		}
+		cookies, state := startOAuthLink(t, handler, bearer)
+		req := httptest.NewRequest(http.MethodGet, "/auth/example/callback?code=good-code&state="+url.QueryEscape(state), nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := sendWithCredentials(handler, http.MethodPost, "/auth/example/link", "Authorization", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous link status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	assertErrorCode(t, login(), http.StatusForbidden, models.ErrIdentityNotLinked)
+
+	if rec := link(services.DemoUser()); rec.Code != http.StatusNoContent {
+		t.Fatalf("link callback status = %d, want %d (body: %s)", rec.Code, http.StatusNoContent, rec.Body.String())
+	}
+	rec := login()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp models.LoginResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode login response: %v", err)
+	}
+	if claims, err := tokenService.Parse(resp.Token); err != nil || claims.Subject != "1" {
+		t.Errorf("token claims = %+v, %v, want the admin user", claims, err)
+	}
+
+	alice := models.User{ID: "7", Username: "alice", Role: models.RoleUser}
+	assertErrorCode(t, link(alice), http.StatusConflict, models.ErrIdentityLinked)
+}
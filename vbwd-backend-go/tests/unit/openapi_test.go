@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/openapi"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+)
+
+func TestSchemaFor_DerivesPropertiesFromJSONTags(t *testing.T) {
+	type sample struct {
+		Name     string   `json:"name"`
+		Count    int64    `json:"count,omitempty"`
+		Tags     []string `json:"tags"`
+		Ignored  string   `json:"-"`
+		internal string
+	}
+
+	schema := openapi.SchemaFor(sample{internal: ""})
+
+	if schema.Type != "object" {
+		t.Errorf("Type = %q, want %q", schema.Type, "object")
+	}
+	want := map[string]openapi.Schema{
+		"name":  {Type: "string"},
+		"count": {Type: "integer", Format: "int64"},
+		"tags":  {Type: "array", Items: &openapi.Schema{Type: "string"}},
+	}
+	if len(schema.Properties) != len(want) {
+		t.Fatalf("Properties = %v, want %d entries", schema.Properties, len(want))
+	}
+	for name, prop := range want {
+		if got := schema.Properties[name]; got == nil || !reflect.DeepEqual(*got, prop) {
+			t.Errorf("Properties[%q] = %+v, want %+v", name, got, prop)
+		}
+	}
+	if wantRequired := []string{"name", "tags"}; !reflect.DeepEqual(schema.Required, wantRequired) {
+		t.Errorf("Required = %v, want %v", schema.Required, wantRequired)
+	}
+}
+
+func TestRouter_ServesOpenAPIDocument(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	if doc.OpenAPI != "3.0.3" {
+		t.Errorf("openapi = %q, want %q", doc.OpenAPI, "3.0.3")
+	}
+
+	for path, method := range map[string]string{"/health": "get", "/login": "post", "/password/forgot": "post", "/password/reset": "post"} {
+		if _, ok := doc.Paths[path][method]; !ok {
+			t.Errorf("paths[%q] has no %s operation", path, method)
+		}
+	}
+
+	wantProperties := map[string][]string{
+		"LoginRequest":   {"username", "password"},
+		"LoginResponse":  {"success", "message", "token", "refresh_token"},
+		"HealthResponse": {"status", "timestamp", "service", "version", "uptime_seconds"},
+	}
+	for name, properties := range wantProperties {
+		schema, ok := doc.Components.Schemas[name]
+		if !ok {
+			t.Errorf("components.schemas has no %s", name)
+			continue
+		}
+		for _, property := range properties {
+			if _, ok := schema.Properties[property]; !ok {
+				t.Errorf("%s has no property %q", name, property)
+			}
+		}
+	}
+}
+
+func TestRouter_OpenAPIDocumentsEveryRoute(t *testing.T) {
+	tests := []struct {
+		name         string
+		prefix       string
+		prefixProbes bool
+	}{
+		{"no prefix", "", false},
+		{"prefix", "/api/v1", false},
+		{"prefix with PrefixProbes", "/api/v1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The optional handlers are only registered, never called, so
+			// zero values register every route.
+			deps := newTestDependencies()
+			deps.JWKSHandler = &handlers.JWKSHandler{}
+			deps.OAuthHandler = &handlers.OAuthHandler{}
+			deps.KeyRotationHandler = &handlers.KeyRotationHandler{}
+			deps.IntrospectionHandler = &handlers.IntrospectionHandler{}
+			deps.MFAHandler = &handlers.MFAHandler{}
+			deps.SessionHandler = &handlers.SessionHandler{}
+			deps.APIKeyHandler = &handlers.APIKeyHandler{}
+			deps.RoutePrefix = tt.prefix
+			deps.PrefixProbes = tt.prefixProbes
+			r := router.NewRouter(deps)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.prefix+"/openapi.json", nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			var doc openapi.Document
+			if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+				t.Fatalf("decode document: %v", err)
+			}
+
+			for _, route := range r.Routes() {
+				if _, ok := doc.Paths[route.Pattern][strings.ToLower(route.Method)]; !ok {
+					t.Errorf("document lacks %s %s", route.Method, route.Pattern)
+				}
+			}
+		})
+	}
+}
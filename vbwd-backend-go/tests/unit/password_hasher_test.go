@@ -0,0 +1,172 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+func TestNewBcryptHasher_RejectsOutOfRangeCost(t *testing.T) {
+	for _, cost := range []int{0, bcrypt.MinCost - 1, bcrypt.MaxCost + 1} {
+		if _, err := services.NewBcryptHasher(cost); !errors.Is(err, services.ErrInvalidBcryptCost) {
+			t.Errorf("NewBcryptHasher(%d) error = %v, want %v", cost, err, services.ErrInvalidBcryptCost)
+		}
+	}
+}
+
+func TestBcryptHasher_HashUsesCost(t *testing.T) {
+	hasher, err := services.NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() unexpected error: %v", err)
+	}
+
+	hash, err := hasher.Hash("S3cret-pass")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+	if cost, _ := bcrypt.Cost([]byte(hash)); cost != bcrypt.MinCost {
+		t.Errorf("hash cost = %d, want %d", cost, bcrypt.MinCost)
+	}
+	assertCompare(t, hasher, hash, "S3cret-pass", true)
+	assertCompare(t, hasher, hash, "wrong", false)
+}
+
+// assertCompare checks hasher.Compare(hash, password) against want.
+func assertCompare(t *testing.T, hasher services.Hasher, hash, password string, want bool) {
+	t.Helper()
+	ok, err := hasher.Compare(hash, password)
+	if err != nil {
+		t.Fatalf("Compare(%q) unexpected error: %v", password, err)
+	}
+	if ok != want {
+		t.Errorf("Compare(%q) = %t, want %t", password, ok, want)
+	}
+}
+
+// testArgon2Params keeps Argon2id fast in tests.
+var testArgon2Params = services.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHasher_RoundTrip(t *testing.T) {
+	hasher, err := services.NewArgon2idHasher(testArgon2Params)
+	if err != nil {
+		t.Fatalf("NewArgon2idHasher() unexpected error: %v", err)
+	}
+
+	hash, err := hasher.Hash("S3cret-pass")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("hash = %q, want encoded argon2id parameters", hash)
+	}
+	assertCompare(t, hasher, hash, "S3cret-pass", true)
+	assertCompare(t, hasher, hash, "wrong", false)
+
+	other, err := hasher.Hash("S3cret-pass")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+	if other == hash {
+		t.Error("Hash() returned identical hashes, want a random salt")
+	}
+}
+
+func TestNewArgon2idHasher_RejectsZeroParams(t *testing.T) {
+	params := testArgon2Params
+	params.Iterations = 0
+	if _, err := services.NewArgon2idHasher(params); !errors.Is(err, services.ErrInvalidArgon2Params) {
+		t.Errorf("NewArgon2idHasher() error = %v, want %v", err, services.ErrInvalidArgon2Params)
+	}
+}
+
+func TestHasher_VerifiesOtherAlgorithm(t *testing.T) {
+	bcryptHasher, err := services.NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() unexpected error: %v", err)
+	}
+	argonHasher, err := services.NewArgon2idHasher(testArgon2Params)
+	if err != nil {
+		t.Fatalf("NewArgon2idHasher() unexpected error: %v", err)
+	}
+
+	bcryptHash, err := bcryptHasher.Hash("S3cret-pass")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+	argonHash, err := argonHasher.Hash("S3cret-pass")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+
+	// A legacy bcrypt hash keeps working after switching to Argon2id, and
+	// the reverse.
+	assertCompare(t, argonHasher, bcryptHash, "S3cret-pass", true)
+	assertCompare(t, argonHasher, bcryptHash, "wrong", false)
+	assertCompare(t, bcryptHasher, argonHash, "S3cret-pass", true)
+	assertCompare(t, bcryptHasher, argonHash, "wrong", false)
+}
+
+func TestHasher_RejectsUnknownHash(t *testing.T) {
+	hasher, err := services.NewArgon2idHasher(testArgon2Params)
+	if err != nil {
+		t.Fatalf("NewArgon2idHasher() unexpected error: %v", err)
+	}
+
+	for _, hash := range []string{"plaintext", "$argon2id$v=19$m=abc$salt$key", "$scrypt$ln=15$abc$def"} {
+		if _, err := hasher.Compare(hash, "S3cret-pass"); !errors.Is(err, services.ErrUnsupportedHash) {
+			t.Errorf("Compare(%q) error = %v, want %v", hash, err, services.ErrUnsupportedHash)
+		}
+	}
+}
+
+func TestNewHasher(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		wantType  string
+		wantErr   error
+	}{
+		{services.HasherBcrypt, "*services.BcryptHasher", nil},
+		{services.HasherArgon2id, "*services.Argon2idHasher", nil},
+		{"md5", "", services.ErrUnknownHasher},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			hasher, err := services.NewHasher(tt.algorithm, bcrypt.MinCost)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewHasher() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && fmt.Sprintf("%T", hasher) != tt.wantType {
+				t.Errorf("NewHasher() type = %T, want %s", hasher, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestBcryptHasher_LowerCostIsFaster(t *testing.T) {
+	timeHash := func(cost int) time.Duration {
+		hasher, err := services.NewBcryptHasher(cost)
+		if err != nil {
+			t.Fatalf("NewBcryptHasher(%d) unexpected error: %v", cost, err)
+		}
+		start := time.Now()
+		if _, err := hasher.Hash("S3cret-pass"); err != nil {
+			t.Fatalf("Hash() unexpected error: %v", err)
+		}
+		return time.Since(start)
+	}
+
+	fast := timeHash(bcrypt.MinCost)
+	slow := timeHash(bcrypt.DefaultCost)
+
+	// Each cost step doubles the work, so DefaultCost is 64x MinCost.
+	if fast*4 >= slow {
+		t.Errorf("hash at cost %d took %v, at cost %d %v; want the lower cost clearly faster", bcrypt.MinCost, fast, bcrypt.DefaultCost, slow)
+	}
+}
@@ -0,0 +1,73 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := services.PasswordPolicy{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	tests := []struct {
+		name          string
+		password      string
+		wantViolation string
+	}{
+		{"too short", "Ab1!", "at least 10 characters"},
+		{"missing upper", "abcdefgh1!", "an upper-case letter"},
+		{"missing lower", "ABCDEFGH1!", "a lower-case letter"},
+		{"missing digit", "Abcdefghi!", "a digit"},
+		{"missing symbol", "Abcdefghi1", "a symbol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if !errors.Is(err, models.ErrWeakPassword) {
+				t.Fatalf("Validate() error = %v, want %v", err, models.ErrWeakPassword)
+			}
+			if !strings.Contains(err.Error(), tt.wantViolation) {
+				t.Errorf("Validate() error = %q, want it to mention %q", err, tt.wantViolation)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_Validate_ListsEveryFailedRule(t *testing.T) {
+	err := services.DefaultPasswordPolicy().Validate("abc")
+
+	var weak *models.WeakPasswordError
+	if !errors.As(err, &weak) {
+		t.Fatalf("Validate() error = %T, want *models.WeakPasswordError", err)
+	}
+	if len(weak.Violations) != 3 {
+		t.Errorf("Violations = %v, want length, upper-case and digit", weak.Violations)
+	}
+}
+
+func TestPasswordPolicy_Validate_Compliant(t *testing.T) {
+	policy := services.PasswordPolicy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+
+	if err := policy.Validate("Correct-H0rse"); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestAuthService_Register_EnforcesPasswordPolicy(t *testing.T) {
+	service := newTestAuthService(services.NewTokenService(testJWTSecret, services.TokenOptions{}))
+
+	if _, err := service.Register(context.Background(), "alice", "", "alllowercase"); !errors.Is(err, models.ErrWeakPassword) {
+		t.Errorf("Register() error = %v, want %v", err, models.ErrWeakPassword)
+	}
+}
@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+)
+
+func TestPasswordResetHandler_Forgot(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"registered email", `{"email":"alice@example.com"}`, http.StatusOK},
+		{"unknown email", `{"email":"nobody@example.com"}`, http.StatusOK},
+		{"missing email", `{}`, http.StatusUnprocessableEntity},
+		{"malformed email", `{"email":"alice"}`, http.StatusUnprocessableEntity},
+	}
+
+	var okBody string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _ := newTestPasswordResetService(t, nil)
+			rec := httptest.NewRecorder()
+			handlers.NewPasswordResetHandler(svc).Forgot(rec, newJSONRequest("/password/forgot", tt.body))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			if okBody == "" {
+				okBody = rec.Body.String()
+			} else if rec.Body.String() != okBody {
+				t.Errorf("body = %q, want %q for every email", rec.Body.String(), okBody)
+			}
+		})
+	}
+}
+
+func TestPasswordResetHandler_Reset(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"unknown token", `{"token":"nope","new_password":"S3cret-pass"}`, http.StatusBadRequest},
+		{"missing fields", `{}`, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _ := newTestPasswordResetService(t, nil)
+			rec := httptest.NewRecorder()
+			handlers.NewPasswordResetHandler(svc).Reset(rec, newJSONRequest("/password/reset", tt.body))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
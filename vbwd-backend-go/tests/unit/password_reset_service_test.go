@@ -0,0 +1,281 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+const resetTestEmail = "alice@example.com"
+
+// newTestPasswordResetService returns a reset service over a repository
+// holding alice, whose email is resetTestEmail, together with the
+// repository and the clock driving token expiry. A nil notifier drops
+// notifications; delivery failures are logged to discardLogger().
+func newTestPasswordResetService(t *testing.T, notifier services.Notifier) (services.PasswordResetService, repository.UserRepository, *fakeClock) {
+	t.Helper()
+	return newTestPasswordResetServiceWithLogger(t, notifier, discardLogger())
+}
+
+// newTestPasswordResetServiceWithLogger is newTestPasswordResetService
+// logging to logger.
+func newTestPasswordResetServiceWithLogger(t *testing.T, notifier services.Notifier, logger *slog.Logger) (services.PasswordResetService, repository.UserRepository, *fakeClock) {
+	t.Helper()
+
+	hash, err := services.DefaultBcryptHasher().Hash("old-Passw0rd")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+	repo := repository.NewInMemoryUserRepository(models.User{
+		ID:       "42",
+		Username: "alice",
+		Email:    resetTestEmail,
+		Password: hash,
+		Role:     models.RoleUser,
+	})
+	clock := newFakeClock(time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC))
+	svc := services.NewPasswordResetService(repo, repository.NewInMemoryResetTokenStore(), nil, services.DefaultPasswordPolicy(), 15*time.Minute, notifier, clock, logger, nil, nil)
+	return svc, repo, clock
+}
+
+func TestPasswordResetService_RequestReset_GeneratesToken(t *testing.T) {
+	svc, _, _ := newTestPasswordResetService(t, nil)
+	ctx := context.Background()
+
+	first, err := svc.RequestReset(ctx, "Alice@Example.com")
+	if err != nil {
+		t.Fatalf("RequestReset() unexpected error: %v", err)
+	}
+	if len(first) < 40 {
+		t.Errorf("token = %q, want at least 40 characters", first)
+	}
+
+	second, err := svc.RequestReset(ctx, resetTestEmail)
+	if err != nil {
+		t.Fatalf("RequestReset() unexpected error: %v", err)
+	}
+	if first == second {
+		t.Error("RequestReset() returned the same token twice")
+	}
+	if err := svc.ResetPassword(ctx, first, "S3cret-pass"); !errors.Is(err, models.ErrInvalidResetToken) {
+		t.Errorf("ResetPassword(superseded token) error = %v, want %v", err, models.ErrInvalidResetToken)
+	}
+}
+
+// fakeNotifier records password reset notifications, which the service
+// delivers in the background. A non-nil release blocks every delivery
+// until it is closed.
+type fakeNotifier struct {
+	sent    chan sentReset
+	err     error
+	release chan struct{}
+}
+
+func newFakeNotifier(err error) *fakeNotifier {
+	return &fakeNotifier{sent: make(chan sentReset, 10), err: err}
+}
+
+type sentReset struct {
+	email string
+	token string
+}
+
+func (n *fakeNotifier) SendPasswordReset(ctx context.Context, email, token string) error {
+	if n.release != nil {
+		<-n.release
+	}
+	n.sent <- sentReset{email: email, token: token}
+	return n.err
+}
+
+// next waits for the next delivered notification.
+func (n *fakeNotifier) next(t *testing.T) sentReset {
+	t.Helper()
+	select {
+	case sent := <-n.sent:
+		return sent
+	case <-time.After(time.Second):
+		t.Fatal("no notification delivered")
+		return sentReset{}
+	}
+}
+
+func TestPasswordResetService_RequestReset_NotifiesUser(t *testing.T) {
+	notifier := newFakeNotifier(nil)
+	svc, _, _ := newTestPasswordResetService(t, notifier)
+
+	token, err := svc.RequestReset(context.Background(), "Alice@Example.com")
+	if err != nil {
+		t.Fatalf("RequestReset() unexpected error: %v", err)
+	}
+
+	sent := notifier.next(t)
+	if sent.email != resetTestEmail {
+		t.Errorf("email = %q, want %q", sent.email, resetTestEmail)
+	}
+	if sent.token == "" || sent.token != token {
+		t.Errorf("token = %q, want the issued token %q", sent.token, token)
+	}
+}
+
+func TestPasswordResetService_RequestReset_NotifierError(t *testing.T) {
+	var buf syncBuffer
+	notifier := newFakeNotifier(errors.New("relay unavailable"))
+	svc, _, _ := newTestPasswordResetServiceWithLogger(t, notifier, slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	token, err := svc.RequestReset(context.Background(), resetTestEmail)
+	if err != nil {
+		t.Fatalf("RequestReset() error = %v, want nil so the response matches unknown emails", err)
+	}
+	if token == "" {
+		t.Error("token is empty")
+	}
+
+	notifier.next(t)
+	if got := buf.waitFor(t, "relay unavailable"); !strings.Contains(got, "notify password reset") {
+		t.Errorf("log = %q, want the delivery failure", got)
+	}
+}
+
+func TestPasswordResetService_RequestReset_DoesNotWaitForDelivery(t *testing.T) {
+	notifier := newFakeNotifier(nil)
+	notifier.release = make(chan struct{})
+	defer close(notifier.release)
+	svc, _, _ := newTestPasswordResetService(t, notifier)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := svc.RequestReset(context.Background(), resetTestEmail)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("RequestReset() unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RequestReset() waited for the blocked notifier")
+	}
+}
+
+func TestPasswordResetService_RequestReset_UnknownEmail(t *testing.T) {
+	notifier := newFakeNotifier(nil)
+	svc, _, _ := newTestPasswordResetService(t, notifier)
+
+	token, err := svc.RequestReset(context.Background(), "nobody@example.com")
+	if err != nil {
+		t.Fatalf("RequestReset() unexpected error: %v", err)
+	}
+	if token != "" {
+		t.Errorf("token = %q, want empty", token)
+	}
+	if len(notifier.sent) != 0 {
+		t.Errorf("notifications = %d, want 0", len(notifier.sent))
+	}
+}
+
+func TestPasswordResetService_ResetPassword(t *testing.T) {
+	svc, repo, _ := newTestPasswordResetService(t, nil)
+	ctx := context.Background()
+
+	token, err := svc.RequestReset(ctx, resetTestEmail)
+	if err != nil {
+		t.Fatalf("RequestReset() unexpected error: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "S3cret-pass"); err != nil {
+		t.Fatalf("ResetPassword() unexpected error: %v", err)
+	}
+
+	user, err := repo.FindByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatalf("FindByUsername() unexpected error: %v", err)
+	}
+	assertCompare(t, services.DefaultBcryptHasher(), user.Password, "S3cret-pass", true)
+}
+
+func TestPasswordResetService_ResetPassword_RevokesCredentials(t *testing.T) {
+	ctx := context.Background()
+	alice := models.User{ID: "42", Username: "alice", Email: resetTestEmail, Role: models.RoleUser}
+	repo := repository.NewInMemoryUserRepository(alice)
+	sessions := services.NewSessionService(repository.NewInMemorySessionStore(), 0, nil)
+	keys := services.NewAPIKeyService(repository.NewInMemoryAPIKeyStore(), repo, nil)
+	svc := services.NewPasswordResetService(repo, repository.NewInMemoryResetTokenStore(), nil, services.DefaultPasswordPolicy(), 0, nil, nil, discardLogger(), sessions, keys)
+
+	if _, err := sessions.Start(ctx, alice.ID, "attacker"); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+	key, err := keys.Create(ctx, alice, "stolen")
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	token, err := svc.RequestReset(ctx, resetTestEmail)
+	if err != nil {
+		t.Fatalf("RequestReset() unexpected error: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "S3cret-pass"); err != nil {
+		t.Fatalf("ResetPassword() unexpected error: %v", err)
+	}
+
+	if left, err := sessions.List(ctx, alice.ID); err != nil || len(left) != 0 {
+		t.Errorf("sessions after reset = %v, %v, want none", left, err)
+	}
+	if _, err := keys.Verify(ctx, key.Key); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("Verify() after reset error = %v, want %v", err, models.ErrInvalidToken)
+	}
+}
+
+func TestPasswordResetService_ResetPassword_RejectsReuse(t *testing.T) {
+	svc, _, _ := newTestPasswordResetService(t, nil)
+	ctx := context.Background()
+
+	token, err := svc.RequestReset(ctx, resetTestEmail)
+	if err != nil {
+		t.Fatalf("RequestReset() unexpected error: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "S3cret-pass"); err != nil {
+		t.Fatalf("ResetPassword() unexpected error: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "An0ther-pass"); !errors.Is(err, models.ErrInvalidResetToken) {
+		t.Errorf("ResetPassword(reused token) error = %v, want %v", err, models.ErrInvalidResetToken)
+	}
+}
+
+func TestPasswordResetService_ResetPassword_RejectsExpired(t *testing.T) {
+	svc, _, clock := newTestPasswordResetService(t, nil)
+	ctx := context.Background()
+
+	token, err := svc.RequestReset(ctx, resetTestEmail)
+	if err != nil {
+		t.Fatalf("RequestReset() unexpected error: %v", err)
+	}
+	clock.Advance(15 * time.Minute)
+
+	if err := svc.ResetPassword(ctx, token, "S3cret-pass"); !errors.Is(err, models.ErrInvalidResetToken) {
+		t.Errorf("ResetPassword(expired token) error = %v, want %v", err, models.ErrInvalidResetToken)
+	}
+}
+
+func TestPasswordResetService_ResetPassword_WeakPasswordKeepsToken(t *testing.T) {
+	svc, _, _ := newTestPasswordResetService(t, nil)
+	ctx := context.Background()
+
+	token, err := svc.RequestReset(ctx, resetTestEmail)
+	if err != nil {
+		t.Fatalf("RequestReset() unexpected error: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "weak"); err == nil {
+		t.Fatal("ResetPassword(weak password) error = nil, want policy violation")
+	}
+	if err := svc.ResetPassword(ctx, token, "S3cret-pass"); err != nil {
+		t.Errorf("ResetPassword() after policy violation unexpected error: %v", err)
+	}
+}
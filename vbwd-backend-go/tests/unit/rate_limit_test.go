@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// testTrustedProxies trusts the private 10.0.0.0/8 range as proxies.
+var testTrustedProxies = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestRateLimit_AllowsBurstThenRejects(t *testing.T) {
+	const burst = 3
+	handler := middleware.RateLimit(okHandler, 0.001, burst)
+
+	for i := 0; i < burst+2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		rec := httptest.NewRecorder()
+
+		handler(rec, req)
+
+		want := http.StatusOK
+		if i >= burst {
+			want = http.StatusTooManyRequests
+		}
+		if rec.Code != want {
+			t.Errorf("request %d: status = %d, want %d", i+1, rec.Code, want)
+		}
+	}
+}
+
+func TestRateLimit_KeysByForwardedClientIP(t *testing.T) {
+	handler := middleware.TrustProxies(testTrustedProxies)(middleware.RateLimit(okHandler, 0.001, 1))
+
+	send := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send("203.0.113.1"); code != http.StatusOK {
+		t.Errorf("first client: status = %d, want %d", code, http.StatusOK)
+	}
+	if code := send("203.0.113.2, 10.0.0.2"); code != http.StatusOK {
+		t.Errorf("second client: status = %d, want %d", code, http.StatusOK)
+	}
+	if code := send("203.0.113.1"); code != http.StatusTooManyRequests {
+		t.Errorf("first client again: status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		trusted   []netip.Prefix
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{"remote addr", testTrustedProxies, "192.0.2.10:1234", "", "192.0.2.10"},
+		{"no trusted proxies", nil, "10.0.0.1:1234", "198.51.100.7", "10.0.0.1"},
+		{"untrusted peer", testTrustedProxies, "192.0.2.10:1234", "198.51.100.7", "192.0.2.10"},
+		{"forwarded single", testTrustedProxies, "10.0.0.1:1234", "198.51.100.7", "198.51.100.7"},
+		{"forwarded chain", testTrustedProxies, "10.0.0.1:1234", "198.51.100.7, 10.0.0.2", "198.51.100.7"},
+		{"spoofed leftmost", testTrustedProxies, "10.0.0.1:1234", "1.2.3.4, 198.51.100.7", "198.51.100.7"},
+		{"only proxies", testTrustedProxies, "10.0.0.1:1234", "10.0.0.3, 10.0.0.2", "10.0.0.3"},
+		{"malformed hop", testTrustedProxies, "10.0.0.1:1234", "198.51.100.7, garbage, 10.0.0.2", "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			var got string
+			middleware.TrustProxies(tt.trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = middleware.ClientIP(r)
+			})).ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIP_WithoutTrustProxiesIgnoresForwardedFor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.10:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+
+	if got := middleware.ClientIP(req); got != "192.0.2.10" {
+		t.Errorf("ClientIP() = %q, want %q", got, "192.0.2.10")
+	}
+}
+
+func TestBlockFailedLogins_RotatingForwardedForStillBlocked(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		trusted []netip.Prefix
+		remote  string
+	}{
+		{"direct client", nil, "203.0.113.7:1234"},
+		{"behind trusted proxy", testTrustedProxies, "10.0.0.1:1234"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			blocker := services.NewIPBlocker(3, time.Minute, newFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+			handler := middleware.TrustProxies(tt.trusted)(middleware.BlockFailedLogins(blocker)(newTestAuthHandler().Login))
+
+			var rec *httptest.ResponseRecorder
+			for i := 0; i < 4; i++ {
+				req := newJSONRequest("/login", `{"username":"admin","password":"wrong-password"}`)
+				req.RemoteAddr = tt.remote
+				// The attacker spoofs a new address on every attempt; the
+				// trusted proxy appends the real one.
+				req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d, 203.0.113.7", i))
+				rec = httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+			}
+			if rec.Code != http.StatusTooManyRequests {
+				t.Errorf("fourth attempt status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+)
+
+func TestRecover_PanicBeforeWrite(t *testing.T) {
+	logs := captureDefaultLogger(t)
+
+	handler := middleware.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("database password is hunter2")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(rec.Body.String(), "hunter2") {
+		t.Errorf("response leaks panic value: %s", rec.Body.String())
+	}
+
+	entry := decodeLogEntry(t, logs)
+	if entry["panic"] != "database password is hunter2" {
+		t.Errorf("logged panic = %v", entry["panic"])
+	}
+	if stack, _ := entry["stack"].(string); !strings.Contains(stack, "goroutine") {
+		t.Error("stack trace missing from log entry")
+	}
+}
+
+func TestRecover_PanicAfterWrite(t *testing.T) {
+	logs := captureDefaultLogger(t)
+
+	handler := middleware.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("partial"))
+		panic("late failure")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if rec.Body.String() != "partial" {
+		t.Errorf("body = %q, want %q", rec.Body.String(), "partial")
+	}
+	if entry := decodeLogEntry(t, logs); entry["headers_written"] != true {
+		t.Errorf("headers_written = %v, want true", entry["headers_written"])
+	}
+}
+
+func TestRecover_NoPanicPassesThrough(t *testing.T) {
+	rec := httptest.NewRecorder()
+	middleware.Recover(http.HandlerFunc(okHandler)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
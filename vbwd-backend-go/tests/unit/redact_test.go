@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+)
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			"login",
+			`{"username":"admin","password":"hunter2"}`,
+			`{"password":"[REDACTED]","username":"admin"}`,
+		},
+		{
+			"change password",
+			`{"old_password":"a","new_password":"b"}`,
+			`{"new_password":"[REDACTED]","old_password":"[REDACTED]"}`,
+		},
+		{
+			"nested token object",
+			`{"success":true,"token":{"value":"eyJ","type":"Bearer"},"refresh_token":{"value":"eyJ"}}`,
+			`{"refresh_token":"[REDACTED]","success":true,"token":"[REDACTED]"}`,
+		},
+		{
+			"array of objects",
+			`[{"token":"a","id":1},{"id":2.5}]`,
+			`[{"id":1,"token":"[REDACTED]"},{"id":2.5}]`,
+		},
+		{
+			"field names ignore case",
+			`{"Password":"hunter2"}`,
+			`{"Password":"[REDACTED]"}`,
+		},
+		{
+			"no sensitive fields",
+			`{"username":"admin","count":12345678901234567890,"tags":["a",null]}`,
+			`{"count":12345678901234567890,"tags":["a",null],"username":"admin"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := middleware.RedactJSON([]byte(tt.body), middleware.SensitiveFields)
+			if err != nil {
+				t.Fatalf("RedactJSON() unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RedactJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactJSON_LeavesInputUntouched(t *testing.T) {
+	body := []byte(`{"password":"hunter2"}`)
+
+	if _, err := middleware.RedactJSON(body, []string{"password"}); err != nil {
+		t.Fatalf("RedactJSON() unexpected error: %v", err)
+	}
+	if string(body) != `{"password":"hunter2"}` {
+		t.Errorf("input modified to %s", body)
+	}
+}
+
+func TestRedactJSON_InvalidJSON(t *testing.T) {
+	for _, body := range []string{``, `password=hunter2`, `{"password":`} {
+		if got, err := middleware.RedactJSON([]byte(body), middleware.SensitiveFields); err == nil {
+			t.Errorf("RedactJSON(%q) = %s, want an error", body, got)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package unit
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+)
+
+func TestRedirectHTTPS(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		proto        string
+		tls          bool
+		wantStatus   int
+		wantLocation string
+	}{
+		{"forwarded http", http.MethodGet, "/users?offset=20", "http", false, http.StatusPermanentRedirect, "https://api.example.com/users?offset=20"},
+		{"forwarded http post", http.MethodPost, "/login", "http", false, http.StatusPermanentRedirect, "https://api.example.com/login"},
+		{"no header over plain http", http.MethodGet, "/users", "", false, http.StatusPermanentRedirect, "https://api.example.com/users"},
+		{"forwarded https", http.MethodGet, "/users", "https", false, http.StatusOK, ""},
+		{"forwarded https list", http.MethodGet, "/users", "HTTPS, http", false, http.StatusOK, ""},
+		{"direct tls", http.MethodGet, "/users", "", true, http.StatusOK, ""},
+		{"health probe", http.MethodGet, "/health", "http", false, http.StatusOK, ""},
+		{"readiness probe", http.MethodGet, "/readyz", "http", false, http.StatusOK, ""},
+		{"probe lookalike", http.MethodGet, "/healthz", "http", false, http.StatusPermanentRedirect, "https://api.example.com/healthz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.RedirectHTTPS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(tt.method, "http://api.example.com"+tt.target, nil)
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestRouter_RedirectHTTPSWithPrefixedProbes(t *testing.T) {
+	deps := newTestDependencies()
+	deps.RedirectHTTPS = true
+	deps.RoutePrefix = "/api/v1"
+	deps.PrefixProbes = true
+	handler := router.NewRouter(deps)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/api/v1/health", http.StatusOK},
+		{"/api/v1/version", http.StatusPermanentRedirect},
+		{"/health", http.StatusPermanentRedirect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("X-Forwarded-Proto", "http")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
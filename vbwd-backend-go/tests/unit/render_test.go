@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+func TestRender_Negotiation(t *testing.T) {
+	payload := models.UserDTO{ID: "1", Username: "admin", Role: "admin"}
+
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+	}{
+		{"no accept header", "", "application/json"},
+		{"json", "application/json", "application/json"},
+		{"any", "*/*", "application/json"},
+		{"msgpack", "application/x-msgpack", "application/x-msgpack"},
+		{"text", "text/plain", "text/plain; charset=utf-8"},
+		{"text wildcard", "text/*", "text/plain; charset=utf-8"},
+		{"quality decides", "application/json;q=0.5, application/x-msgpack;q=0.9", "application/x-msgpack"},
+		{"unsupported falls back to json", "application/xml", "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			response.Render(rec, req, http.StatusOK, payload)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Fatalf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+
+			switch tt.wantContentType {
+			case "application/json":
+				var got models.UserDTO
+				if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got != payload {
+					t.Errorf("JSON body = %+v (err %v), want %+v", got, err, payload)
+				}
+			case "application/x-msgpack":
+				var got map[string]interface{}
+				if err := msgpack.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+					t.Fatalf("decode msgpack: %v", err)
+				}
+				if got["id"] != "1" || got["username"] != "admin" || got["role"] != "admin" {
+					t.Errorf("msgpack body = %v, want the json field names", got)
+				}
+			default:
+				if want := "{ID:1 Username:admin Email: Role:admin}\n"; rec.Body.String() != want {
+					t.Errorf("text body = %q, want %q", rec.Body.String(), want)
+				}
+			}
+		})
+	}
+}
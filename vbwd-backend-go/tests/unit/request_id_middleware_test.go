@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+)
+
+// requestIDCapture records the request ID seen by the wrapped handler.
+func requestIDCapture(got *string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*got = middleware.RequestIDFromContext(r.Context())
+	})
+}
+
+func TestRequestID_EchoesProvidedID(t *testing.T) {
+	var seen string
+	handler := middleware.RequestID(requestIDCapture(&seen))
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set(middleware.RequestIDHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(middleware.RequestIDHeader); got != "abc-123" {
+		t.Errorf("%s header = %q, want %q", middleware.RequestIDHeader, got, "abc-123")
+	}
+	if seen != "abc-123" {
+		t.Errorf("RequestIDFromContext() = %q, want %q", seen, "abc-123")
+	}
+}
+
+func TestRequestID_GeneratesIDWhenAbsentOrInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+	}{
+		{name: "absent", incoming: ""},
+		{name: "control characters", incoming: "abc\ninjected"},
+		{name: "too long", incoming: strings.Repeat("a", 200)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := middleware.RequestID(requestIDCapture(&seen))
+
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			if tt.incoming != "" {
+				req.Header.Set(middleware.RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(middleware.RequestIDHeader)
+			if _, err := uuid.Parse(got); err != nil {
+				t.Errorf("%s header = %q, want a UUID", middleware.RequestIDHeader, got)
+			}
+			if seen != got {
+				t.Errorf("RequestIDFromContext() = %q, want %q", seen, got)
+			}
+		})
+	}
+}
+
+func TestLogging_IncludesRequestID(t *testing.T) {
+	buf := captureDefaultLogger(t)
+	handler := middleware.RequestID(middleware.Logging(http.HandlerFunc(okHandler)))
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set(middleware.RequestIDHeader, "abc-123")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := decodeLogEntry(t, buf)["request_id"]; got != "abc-123" {
+		t.Errorf("request_id = %v, want %q", got, "abc-123")
+	}
+}
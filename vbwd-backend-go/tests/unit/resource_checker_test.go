@@ -0,0 +1,84 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+const mib = 1 << 20
+
+// stubResourceStats reports fixed measurements: sys bytes obtained from the
+// OS under a memory limit, and diskFree bytes on every path.
+func stubResourceStats(sys uint64, limit int64, diskFree uint64, diskErr error) services.ResourceStats {
+	return services.ResourceStats{
+		ReadMemStats: func(m *runtime.MemStats) { m.Sys = sys },
+		MemoryLimit:  func() int64 { return limit },
+		DiskFree: func(path string) (uint64, error) {
+			return diskFree, diskErr
+		},
+	}
+}
+
+func TestResourceChecker(t *testing.T) {
+	thresholds := services.ResourceThresholds{
+		DiskPath:           "/data",
+		MinFreeDiskBytes:   100 * mib,
+		MinFreeMemoryBytes: 64 * mib,
+	}
+
+	tests := []struct {
+		name    string
+		stats   services.ResourceStats
+		wantErr string
+	}{
+		{"healthy", stubResourceStats(100*mib, 512*mib, 1024*mib, nil), ""},
+		{"memory breached", stubResourceStats(480*mib, 512*mib, 1024*mib, nil), "free memory 32.0MiB below 64.0MiB"},
+		{"memory over limit", stubResourceStats(600*mib, 512*mib, 1024*mib, nil), "free memory 0B below 64.0MiB"},
+		{"no memory limit", stubResourceStats(600*mib, math.MaxInt64, 1024*mib, nil), ""},
+		{"disk breached", stubResourceStats(100*mib, 512*mib, 10*mib, nil), "free disk space on /data 10.0MiB below 100.0MiB"},
+		{"disk unreadable", stubResourceStats(100*mib, 512*mib, 0, errors.New("no such file")), "no such file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.NewResourceChecker(thresholds, tt.stats)(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("check() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("check() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResourceChecker_DisabledThresholds(t *testing.T) {
+	stats := stubResourceStats(600*mib, 512*mib, 0, errors.New("not called"))
+
+	if err := services.NewResourceChecker(services.ResourceThresholds{}, stats)(context.Background()); err != nil {
+		t.Errorf("check() unexpected error: %v", err)
+	}
+}
+
+func TestResourceChecker_ReadinessFailsWhenBreached(t *testing.T) {
+	health := services.NewHealthService("test-service", "test", time.Now(), nil)
+	health.RegisterCheck("resources", services.NewResourceChecker(
+		services.ResourceThresholds{DiskPath: "/", MinFreeDiskBytes: 100 * mib},
+		stubResourceStats(0, math.MaxInt64, mib, nil),
+	))
+
+	readiness := health.GetReadiness(context.Background())
+	if readiness.Ready {
+		t.Error("Ready = true, want false")
+	}
+}
@@ -0,0 +1,124 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+func TestResponse_Success(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	response.Success(rec, http.StatusCreated, map[string]string{"id": "42"})
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want %q", ct, "application/json")
+	}
+	want := `{"success":true,"data":{"id":"42"}}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
+
+func TestResponse_Error(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   string
+	}{
+		{"bad request", http.StatusBadRequest, `{"success":false,"error":{"message":"boom","code":"BAD_REQUEST"}}`},
+		{"unauthorized", http.StatusUnauthorized, `{"success":false,"error":{"message":"boom","code":"UNAUTHORIZED"}}`},
+		{"conflict", http.StatusConflict, `{"success":false,"error":{"message":"boom","code":"CONFLICT"}}`},
+		{"unmapped client error", http.StatusTeapot, `{"success":false,"error":{"message":"boom","code":"BAD_REQUEST"}}`},
+		{"internal", http.StatusInternalServerError, `{"success":false,"error":{"message":"boom","code":"INTERNAL_ERROR"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			response.Error(rec, tt.status, "boom")
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.want {
+				t.Errorf("body = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponse_ErrorWithCode(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	response.ErrorWithCode(rec, http.StatusUnauthorized, "TOKEN_EXPIRED", "Token expired")
+
+	want := `{"success":false,"error":{"message":"Token expired","code":"TOKEN_EXPIRED"}}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
+
+func TestResponse_JSONUnchanged(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	response.JSON(rec, http.StatusOK, map[string]string{"status": "healthy"})
+
+	want := `{"status":"healthy"}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
+
+func TestResponse_FromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		want       string
+	}{
+		{
+			"coded error",
+			models.ErrUserExists,
+			http.StatusConflict,
+			`{"success":false,"error":{"message":"user already exists","code":"USER_EXISTS"}}`,
+		},
+		{
+			"wrapped coded error",
+			fmt.Errorf("register: %w", models.ErrUsernameRequired),
+			http.StatusBadRequest,
+			`{"success":false,"error":{"message":"username is required","code":"USERNAME_REQUIRED"}}`,
+		},
+		{
+			"plain error",
+			errors.New("pq: connection refused"),
+			http.StatusInternalServerError,
+			`{"success":false,"error":{"message":"Internal server error","code":"INTERNAL_ERROR"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			response.FromError(rec, tt.err)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.want {
+				t.Errorf("body = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
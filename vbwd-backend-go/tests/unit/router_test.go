@@ -0,0 +1,240 @@
+package unit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/openapi"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// newTestDependencies returns the router dependencies used by
+// newTestRouter so tests can adjust individual settings.
+func newTestDependencies() router.Dependencies {
+	return router.Dependencies{
+		AuthHandler:    newTestAuthHandler(),
+		AuthHandlerV2:  newTestAuthHandlerV2(),
+		HealthHandler:  handlers.NewHealthHandler(services.NewHealthService("test-service", "test", time.Now(), nil)),
+		VersionHandler: handlers.NewVersionHandler("1.2.3", "abc1234", "2026-01-18T12:00:00Z"),
+		UserHandler:    handlers.NewUserHandler(services.NewUserService(repository.NewInMemoryUserRepository(services.DemoUser()), nil, nil)),
+		PasswordResetHandler: handlers.NewPasswordResetHandler(services.NewPasswordResetService(
+			repository.NewInMemoryUserRepository(services.DemoUser()), repository.NewInMemoryResetTokenStore(), nil, services.DefaultPasswordPolicy(), 0, nil, nil, nil, nil, nil,
+		)),
+		TokenService: services.NewTokenService(testJWTSecret, services.TokenOptions{}),
+		OpenAPI:      openapi.New("test-service", "test"),
+	}
+}
+
+func newTestRouter() http.Handler {
+	return router.NewRouter(newTestDependencies())
+}
+
+// loginForToken logs in through the router and returns the access token.
+func loginForToken(t *testing.T, handler http.Handler, username, password string) string {
+	t.Helper()
+
+	body := fmt.Sprintf(`{"username":%q,"password":%q}`, username, password)
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var resp models.LoginResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode login response: %v", err)
+	}
+	return resp.Token
+}
+
+func TestRouter_Routes(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"health", http.MethodGet, "/health", "", http.StatusOK},
+		{"login", http.MethodPost, "/login", `{"username":"admin","password":"password"}`, http.StatusOK},
+		{"health wrong method", http.MethodPost, "/health", "", http.StatusMethodNotAllowed},
+		{"login wrong method", http.MethodGet, "/login", "", http.StatusMethodNotAllowed},
+		{"refresh wrong method", http.MethodGet, "/refresh", "", http.StatusMethodNotAllowed},
+		{"register wrong method", http.MethodPut, "/register", "", http.StatusMethodNotAllowed},
+		{"forgot password", http.MethodPost, "/password/forgot", `{"email":"nobody@example.com"}`, http.StatusOK},
+		{"reset password wrong method", http.MethodGet, "/password/reset", "", http.StatusMethodNotAllowed},
+		{"unknown route", http.MethodGet, "/nope", "", http.StatusNotFound},
+	}
+
+	handler := newTestRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRouter_RouteInventory(t *testing.T) {
+	deps := newTestDependencies()
+	deps.RoutePrefix = "/api/v1"
+	deps.SessionHandler = handlers.NewSessionHandler(services.NewSessionService(repository.NewInMemorySessionStore(), 0, nil))
+	r := router.NewRouter(deps)
+
+	got := make(map[string]bool)
+	for _, route := range r.Routes() {
+		key := route.Method + " " + route.Pattern
+		if got[key] {
+			t.Errorf("route %s listed twice", key)
+		}
+		got[key] = true
+	}
+	for _, want := range []string{
+		"GET /health",
+		"GET /readyz",
+		"GET /api/v1/version",
+		"POST /api/v1/login",
+		"POST /api/v1/v2/login",
+		"GET /api/v1/sessions",
+		"DELETE /api/v1/sessions/{id}",
+		"DELETE /api/v1/users/{id}",
+	} {
+		if !got[want] {
+			t.Errorf("Routes() lacks %s", want)
+		}
+	}
+	if got["GET /.well-known/jwks.json"] || got["POST /api/v1/introspect"] {
+		t.Error("Routes() lists routes of handlers that are not configured")
+	}
+
+	// Every listed route is matched by the mux: unmatched paths get its
+	// plain-text 404 and unmatched methods a 405.
+	placeholder := regexp.MustCompile(`\{[^}]+\}`)
+	for _, route := range r.Routes() {
+		path := placeholder.ReplaceAllString(route.Pattern, "x")
+		req := httptest.NewRequest(route.Method, path, strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code == http.StatusMethodNotAllowed || rec.Body.String() == "404 page not found\n" {
+			t.Errorf("%s %s is listed but not served: status %d", route.Method, path, rec.Code)
+		}
+	}
+}
+
+func TestRouter_RoutePrefix(t *testing.T) {
+	tests := []struct {
+		name         string
+		prefixProbes bool
+		path         string
+		wantStatus   int
+	}{
+		{"prefixed route", false, "/api/v1/version", http.StatusOK},
+		{"unprefixed route", false, "/version", http.StatusNotFound},
+		{"unprefixed probe", false, "/health", http.StatusOK},
+		{"prefixed probe", false, "/api/v1/health", http.StatusNotFound},
+		{"prefixed probe with PrefixProbes", true, "/api/v1/readyz", http.StatusOK},
+		{"unprefixed probe with PrefixProbes", true, "/readyz", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newTestDependencies()
+			deps.RoutePrefix = "api/v1/"
+			deps.PrefixProbes = tt.prefixProbes
+			rec := httptest.NewRecorder()
+
+			router.NewRouter(deps).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRouter_RoutePrefix_Login(t *testing.T) {
+	deps := newTestDependencies()
+	deps.RoutePrefix = "/api/v1"
+	handler := router.NewRouter(deps)
+
+	for path, want := range map[string]int{"/api/v1/login": http.StatusOK, "/login": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"username":"admin","password":"password"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != want {
+			t.Errorf("POST %s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestRouter_MethodNotAllowedListsAllowedMethods(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/login", nil)
+	rec := httptest.NewRecorder()
+
+	newTestRouter().ServeHTTP(rec, req)
+
+	if allow := rec.Header().Get("Allow"); allow != http.MethodPost {
+		t.Errorf("Allow = %q, want %q", allow, http.MethodPost)
+	}
+}
+
+func TestRouter_DisableRegistration(t *testing.T) {
+	tests := []struct {
+		name       string
+		disabled   bool
+		wantStatus int
+	}{
+		{"enabled", false, http.StatusCreated},
+		{"disabled", true, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newTestDependencies()
+			deps.DisableRegistration = tt.disabled
+			r := router.NewRouter(deps)
+
+			req := newJSONRequest("/register", `{"username":"newuser","password":"Password123!"}`)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			listed := false
+			for _, route := range r.Routes() {
+				listed = listed || (route.Method == http.MethodPost && route.Pattern == "/register")
+			}
+			if listed == tt.disabled {
+				t.Errorf("Routes() lists POST /register = %v, want %v", listed, !tt.disabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	handler := middleware.SecureHeaders(http.HandlerFunc(okHandler))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	want := map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"X-Frame-Options":        "DENY",
+		"Referrer-Policy":        "no-referrer",
+	}
+	for name, value := range want {
+		if got := rec.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Strict-Transport-Security = %q, want none", got)
+	}
+}
+
+func TestHSTS(t *testing.T) {
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		want   string
+	}{
+		{"one year", 365 * 24 * time.Hour, "max-age=31536000; includeSubDomains"},
+		{"one hour", time.Hour, "max-age=3600; includeSubDomains"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.HSTS(tt.maxAge)(http.HandlerFunc(okHandler))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			if got := rec.Header().Get("Strict-Transport-Security"); got != tt.want {
+				t.Errorf("Strict-Transport-Security = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouter_SecureHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		hsts     time.Duration
+		wantHSTS string
+	}{
+		{"plain http", 0, ""},
+		{"tls", time.Hour, "max-age=3600; includeSubDomains"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newTestDependencies()
+			deps.HSTSMaxAge = tt.hsts
+			handler := router.NewRouter(deps)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			if got := rec.Header().Get("X-Frame-Options"); got != "DENY" {
+				t.Errorf("X-Frame-Options = %q, want %q", got, "DENY")
+			}
+			if got := rec.Header().Get("Strict-Transport-Security"); got != tt.wantHSTS {
+				t.Errorf("Strict-Transport-Security = %q, want %q", got, tt.wantHSTS)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+func newSeededAuthService(t *testing.T, seed []models.User) (services.AuthService, services.TokenService, error) {
+	t.Helper()
+
+	tokenService := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	service, err := services.NewAuthServiceFromSeed(seed, services.WithTokenService(tokenService), services.WithLogger(discardLogger()))
+	return service, tokenService, err
+}
+
+func TestNewAuthServiceFromSeed_MultipleUsers(t *testing.T) {
+	seed := []models.User{
+		services.DemoUser(),
+		{ID: "2", Username: "alice", Password: "S3cret-pass"},
+		{Username: "bob", Password: "B0b-password", Role: models.RoleAdmin},
+	}
+
+	service, tokenService, err := newSeededAuthService(t, seed)
+	if err != nil {
+		t.Fatalf("NewAuthServiceFromSeed() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		username string
+		password string
+		wantRole string
+	}{
+		{username: "admin", password: "password", wantRole: models.RoleAdmin},
+		{username: "alice", password: "S3cret-pass", wantRole: models.RoleUser},
+		{username: "bob", password: "B0b-password", wantRole: models.RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			resp, err := service.Authenticate(context.Background(), tt.username, tt.password)
+			if err != nil {
+				t.Fatalf("Authenticate() unexpected error: %v", err)
+			}
+			claims, err := tokenService.Parse(resp.Token)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if claims.Role != tt.wantRole {
+				t.Errorf("Role = %q, want %q", claims.Role, tt.wantRole)
+			}
+			if claims.Subject == "" {
+				t.Error("Subject is empty, want a generated ID")
+			}
+		})
+	}
+}
+
+func TestNewAuthServiceFromSeed_InvalidSeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		seed    []models.User
+		wantErr error
+	}{
+		{
+			name: "duplicate username",
+			seed: []models.User{
+				{Username: "alice", Password: "S3cret-pass"},
+				{Username: "alice", Password: "An0ther-pass"},
+			},
+			wantErr: models.ErrUserExists,
+		},
+		{
+			name:    "missing username",
+			seed:    []models.User{{Password: "S3cret-pass"}},
+			wantErr: models.ErrUsernameRequired,
+		},
+		{
+			name:    "missing password",
+			seed:    []models.User{{Username: "alice"}},
+			wantErr: models.ErrPasswordRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := newSeededAuthService(t, tt.seed); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewAuthServiceFromSeed() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewAuthServiceFromSeed_EmptySeed(t *testing.T) {
+	service, _, err := newSeededAuthService(t, nil)
+	if err != nil {
+		t.Fatalf("NewAuthServiceFromSeed() unexpected error: %v", err)
+	}
+
+	if _, err := service.Authenticate(context.Background(), "admin", "password"); !errors.Is(err, models.ErrInvalidCredentials) {
+		t.Errorf("Authenticate() error = %v, want %v", err, models.ErrInvalidCredentials)
+	}
+}
+
+func TestLoadSeedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	data := `[{"id":"7","username":"alice","password":"S3cret-pass","role":"admin"}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write seed file: %v", err)
+	}
+
+	users, err := services.LoadSeedFile(path)
+	if err != nil {
+		t.Fatalf("LoadSeedFile() unexpected error: %v", err)
+	}
+	want := models.User{ID: "7", Username: "alice", Password: "S3cret-pass", Role: models.RoleAdmin}
+	if len(users) != 1 || users[0] != want {
+		t.Errorf("LoadSeedFile() = %+v, want [%+v]", users, want)
+	}
+
+	if _, err := services.LoadSeedFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadSeedFile() missing file error = nil, want error")
+	}
+}
+
+func TestImportUsers(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository(services.DemoUser())
+	hasher, err := services.NewBcryptHasher(bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("NewBcryptHasher() unexpected error: %v", err)
+	}
+	users := []models.User{
+		{Username: "alice", Email: "Alice@Example.com", Password: "S3cret-pass"},
+		{Username: "admin", Password: "S3cret-pass"},
+		{Username: "bob"},
+		{Username: "carol", Password: services.DemoUser().Password, Role: models.RoleAdmin},
+	}
+
+	imported, errs := services.ImportUsers(context.Background(), repo, users, hasher)
+	if imported != 2 {
+		t.Errorf("imported = %d, want 2", imported)
+	}
+	wantErrs := []error{models.ErrPasswordRequired, models.ErrUserExists}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("errs = %v, want %d errors", errs, len(wantErrs))
+	}
+	for i, want := range wantErrs {
+		if !errors.Is(errs[i], want) {
+			t.Errorf("errs[%d] = %v, want %v", i, errs[i], want)
+		}
+	}
+
+	alice, err := repo.FindByUsername(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("FindByUsername() unexpected error: %v", err)
+	}
+	if ok, _ := hasher.Compare(alice.Password, "S3cret-pass"); !ok {
+		t.Errorf("Password = %q, want a hash of the plaintext", alice.Password)
+	}
+	if alice.Email != "alice@example.com" || alice.Role != models.RoleUser || alice.ID == "" {
+		t.Errorf("alice = %+v, want normalized email, role user and an ID", *alice)
+	}
+
+	carol, err := repo.FindByUsername(context.Background(), "carol")
+	if err != nil {
+		t.Fatalf("FindByUsername() unexpected error: %v", err)
+	}
+	if carol.Password != services.DemoUser().Password {
+		t.Errorf("Password = %q, want the hash kept", carol.Password)
+	}
+}
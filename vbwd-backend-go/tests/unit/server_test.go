@@ -0,0 +1,265 @@
+package unit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/server"
+)
+
+func TestServer_GracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() unexpected error: %v", err)
+	}
+
+	srv := server.New(listener.Addr().String(), http.HandlerFunc(okHandler), time.Second, server.Timeouts{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, listener) }()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("GET unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() error = %v, want nil", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve() did not return after shutdown")
+	}
+}
+
+func TestServer_Timeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts server.Timeouts
+		want     server.Timeouts
+	}{
+		{
+			name:     "defaults",
+			timeouts: server.Timeouts{},
+			want:     server.DefaultTimeouts(),
+		},
+		{
+			name:     "configured",
+			timeouts: server.Timeouts{ReadHeader: time.Second, Read: 2 * time.Second, Write: 3 * time.Second, Idle: 4 * time.Second},
+			want:     server.Timeouts{ReadHeader: time.Second, Read: 2 * time.Second, Write: 3 * time.Second, Idle: 4 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := server.New(":0", http.HandlerFunc(okHandler), time.Second, tt.timeouts)
+			if got := srv.Timeouts(); got != tt.want {
+				t.Errorf("Timeouts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_TLSServesHTTP2(t *testing.T) {
+	certFile, keyFile, pool := writeSelfSignedCert(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() unexpected error: %v", err)
+	}
+
+	srv := server.New(listener.Addr().String(), http.HandlerFunc(okHandler), time.Second, server.Timeouts{})
+	srv.EnableTLS(certFile, keyFile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, listener) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://" + listener.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("GET unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if resp.ProtoMajor != 2 {
+		t.Errorf("proto = %s, want HTTP/2.0", resp.Proto)
+	}
+}
+
+// writeSelfSignedCert writes a self-signed certificate for 127.0.0.1 and its
+// key as PEM files and returns their paths with a pool trusting the cert.
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() unexpected error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() unexpected error: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+
+	pool = x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+	return certFile, keyFile, pool
+}
+
+// slowRequest is a request blocked in a slow handler.
+type slowRequest struct {
+	srv      *server.Server
+	stop     context.CancelFunc
+	done     <-chan error
+	status   <-chan int
+	finished *atomic.Bool
+}
+
+// startSlowRequest serves a handler that blocks for delay and sends one
+// request to it. It returns once the handler is running. status yields the
+// response status (or 0 when the request failed) and finished is set when
+// the handler returns.
+func startSlowRequest(t *testing.T, shutdownTimeout, delay time.Duration) slowRequest {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() unexpected error: %v", err)
+	}
+
+	started := make(chan struct{})
+	finished := &atomic.Bool{}
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusOK)
+		finished.Store(true)
+	})
+	srv := server.New(listener.Addr().String(), slow, shutdownTimeout, server.Timeouts{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, listener) }()
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("slow request did not start")
+	}
+	return slowRequest{srv: srv, stop: cancel, done: done, status: status, finished: finished}
+}
+
+func TestServer_DrainsInFlightRequests(t *testing.T) {
+	req := startSlowRequest(t, 2*time.Second, 200*time.Millisecond)
+
+	if got := req.srv.InFlight(); got != 1 {
+		t.Errorf("InFlight() = %d, want 1", got)
+	}
+	req.stop()
+
+	select {
+	case err := <-req.done:
+		if err != nil {
+			t.Errorf("Serve() error = %v, want nil", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Serve() did not return after shutdown")
+	}
+
+	if !req.finished.Load() {
+		t.Fatal("Serve() returned before the in-flight request completed")
+	}
+	if got := <-req.status; got != http.StatusOK {
+		t.Errorf("status = %d, want %d", got, http.StatusOK)
+	}
+	if got := req.srv.InFlight(); got != 0 {
+		t.Errorf("InFlight() = %d, want 0", got)
+	}
+}
+
+func TestServer_ForcesCloseAfterShutdownTimeout(t *testing.T) {
+	req := startSlowRequest(t, 50*time.Millisecond, time.Second)
+
+	start := time.Now()
+	req.stop()
+
+	select {
+	case err := <-req.done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Serve() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve() did not return after shutdown timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("shutdown took %v, want about the 50ms timeout", elapsed)
+	}
+}
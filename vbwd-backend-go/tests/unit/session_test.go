@@ -0,0 +1,316 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// newSessionRouter returns a router whose logins start tracked sessions
+// that token verification and POST /introspect check.
+func newSessionRouter() http.Handler {
+	sessions := services.NewSessionService(repository.NewInMemorySessionStore(), 0, nil)
+	tokenService := services.NewSessionTokenService(services.NewTokenService(testJWTSecret, services.TokenOptions{}), sessions, services.SessionTokenOptions{})
+	authService := services.NewAuthService(
+		services.WithRepository(repository.NewInMemoryUserRepository(services.DemoUser())),
+		services.WithTokenService(tokenService),
+		services.WithSessions(sessions),
+		services.WithLogger(discardLogger()),
+	)
+
+	deps := newTestDependencies()
+	deps.AuthHandler = handlers.NewAuthHandler(authService, nil)
+	deps.SessionHandler = handlers.NewSessionHandler(sessions)
+	deps.IntrospectionHandler = handlers.NewIntrospectionHandler(tokenService)
+	deps.TokenService = tokenService
+	return router.NewRouter(deps)
+}
+
+// listSessions calls GET /sessions with token.
+func listSessions(t *testing.T, handler http.Handler, token string) []models.SessionDTO {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /sessions status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp models.SessionListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode sessions: %v", err)
+	}
+	return resp.Sessions
+}
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	handler := newSessionRouter()
+	first := loginForToken(t, handler, "admin", "password")
+	second := loginForToken(t, handler, "admin", "password")
+
+	sessions := listSessions(t, handler, first)
+	if len(sessions) != 2 {
+		t.Fatalf("sessions = %d, want 2", len(sessions))
+	}
+	current, other := sessions[0], sessions[1]
+	if other.Current {
+		current, other = other, current
+	}
+	if !current.Current || other.Current {
+		t.Fatalf("current flags = %v, %v, want exactly one", sessions[0].Current, sessions[1].Current)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/sessions/"+other.ID, nil)
+	req.Header.Set("Authorization", "Bearer "+first)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d, want %d (body: %s)", rec.Code, http.StatusNoContent, rec.Body.String())
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"revoked session", second, http.StatusUnauthorized},
+		{"remaining session", first, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+
+	if got := listSessions(t, handler, first); len(got) != 1 || got[0].ID != current.ID {
+		t.Errorf("sessions after revoke = %+v, want only %s", got, current.ID)
+	}
+}
+
+func TestSessions_RevokeUnknown(t *testing.T) {
+	handler := newSessionRouter()
+	token := loginForToken(t, handler, "admin", "password")
+
+	req := httptest.NewRequest(http.MethodDelete, "/sessions/does-not-exist", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if !strings.Contains(rec.Body.String(), models.ErrSessionNotFound.Code) {
+		t.Errorf("body = %s, want code %s", rec.Body.String(), models.ErrSessionNotFound.Code)
+	}
+}
+
+func TestSessionService_RecordsDeviceAndPrunesIdle(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := repository.NewInMemorySessionStore()
+	sessions := services.NewSessionService(store, time.Hour, clock)
+	ctx := context.Background()
+
+	old, err := sessions.Start(ctx, "1", "curl/8.0")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if old.Device != "curl/8.0" {
+		t.Errorf("Device = %q, want %q", old.Device, "curl/8.0")
+	}
+
+	clock.Advance(2 * time.Hour)
+	current, err := sessions.Start(ctx, "1", "firefox")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if err := sessions.Validate(ctx, old.ID); !errors.Is(err, models.ErrSessionNotFound) {
+		t.Errorf("Validate(idle) = %v, want %v", err, models.ErrSessionNotFound)
+	}
+	if err := sessions.Revoke(ctx, "2", current.ID); !errors.Is(err, models.ErrSessionNotFound) {
+		t.Errorf("Revoke(other user) = %v, want %v", err, models.ErrSessionNotFound)
+	}
+}
+
+func TestSessions_LogoutAll(t *testing.T) {
+	handler := newSessionRouter()
+	tokens := []string{
+		loginForToken(t, handler, "admin", "password"),
+		loginForToken(t, handler, "admin", "password"),
+		loginForToken(t, handler, "admin", "password"),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/logout-all", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens[1])
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("POST /logout-all status = %d, want %d (body: %s)", rec.Code, http.StatusNoContent, rec.Body.String())
+	}
+
+	for i, token := range tokens {
+		req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %d: status = %d, want %d", i, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+// unavailableSessionStore fails every session check, like a store that is
+// down.
+type unavailableSessionStore struct {
+	repository.SessionStore
+}
+
+func (unavailableSessionStore) Touch(ctx context.Context, id string, at time.Time) (*models.Session, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestSessionTokenService_StoreUnavailable(t *testing.T) {
+	token := signTestToken(t, services.Claims{
+		Username:  "admin",
+		Role:      models.RoleAdmin,
+		TokenType: services.TokenTypeAccess,
+		SessionID: "session-1",
+	})
+
+	tests := []struct {
+		name        string
+		policy      services.RevocationPolicy
+		wantAllowed bool
+	}{
+		{"default", "", false},
+		{"fail closed", services.RevocationFailClosed, false},
+		{"fail open", services.RevocationFailOpen, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureDefaultLogger(t)
+			sessions := services.NewSessionService(unavailableSessionStore{}, 0, nil)
+			service := services.NewSessionTokenService(services.NewTokenService(testJWTSecret, services.TokenOptions{}), sessions, services.SessionTokenOptions{Policy: tt.policy})
+
+			claims, err := service.Parse(token)
+
+			if !tt.wantAllowed {
+				if err == nil {
+					t.Fatal("Parse() error = nil, want the token rejected")
+				}
+				if buf.Len() != 0 {
+					t.Errorf("log = %q, want no warning when failing closed", buf.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if claims.SessionID != "session-1" {
+				t.Errorf("SessionID = %q, want %q", claims.SessionID, "session-1")
+			}
+			entry := decodeLogEntry(t, buf)
+			if entry["level"] != "WARN" || entry["session_id"] != "session-1" || entry["policy"] != "fail_open" {
+				t.Errorf("log entry = %v, want a warning naming the session and policy", entry)
+			}
+		})
+	}
+}
+
+// hangingSessionStore blocks every session check until its context ends,
+// like a store that accepts connections but never answers.
+type hangingSessionStore struct {
+	repository.SessionStore
+}
+
+func (hangingSessionStore) Touch(ctx context.Context, id string, at time.Time) (*models.Session, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSessionTokenService_StoreTimeout(t *testing.T) {
+	token := signTestToken(t, services.Claims{
+		Username:  "admin",
+		Role:      models.RoleAdmin,
+		TokenType: services.TokenTypeAccess,
+		SessionID: "session-1",
+	})
+
+	tests := []struct {
+		name        string
+		policy      services.RevocationPolicy
+		wantAllowed bool
+	}{
+		{"fail closed", services.RevocationFailClosed, false},
+		{"fail open", services.RevocationFailOpen, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := services.NewSessionService(hangingSessionStore{}, 0, nil)
+			service := services.NewSessionTokenService(services.NewTokenService(testJWTSecret, services.TokenOptions{}), sessions, services.SessionTokenOptions{
+				Policy:  tt.policy,
+				Timeout: 20 * time.Millisecond,
+				Logger:  discardLogger(),
+			})
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := service.Parse(token)
+				done <- err
+			}()
+
+			select {
+			case err := <-done:
+				if tt.wantAllowed && err != nil {
+					t.Errorf("Parse() unexpected error: %v", err)
+				}
+				if !tt.wantAllowed && !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("Parse() error = %v, want %v", err, context.DeadlineExceeded)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Parse() still waiting for the hanging store")
+			}
+		})
+	}
+}
+
+func TestSessionTokenService_FailOpenStillRejectsRevoked(t *testing.T) {
+	sessions := services.NewSessionService(repository.NewInMemorySessionStore(), 0, nil)
+	service := services.NewSessionTokenService(services.NewTokenService(testJWTSecret, services.TokenOptions{}), sessions, services.SessionTokenOptions{
+		Policy: services.RevocationFailOpen,
+		Logger: discardLogger(),
+	})
+	token := signTestToken(t, services.Claims{
+		Username:  "admin",
+		Role:      models.RoleAdmin,
+		TokenType: services.TokenTypeAccess,
+		SessionID: "revoked",
+	})
+
+	if _, err := service.Parse(token); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("Parse() error = %v, want %v", err, models.ErrInvalidToken)
+	}
+}
@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dantweb/vbwd-backend-go/internal/middleware"
+	"github.com/dantweb/vbwd-backend-go/pkg/i18n"
+	"github.com/dantweb/vbwd-backend-go/pkg/response"
+)
+
+func TestTimeout(t *testing.T) {
+	fast := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "fast")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("done"))
+	})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.Write([]byte("too late"))
+	})
+
+	tests := []struct {
+		name       string
+		handler    http.Handler
+		wantStatus int
+		wantBody   string
+		wantCode   response.ErrorCode
+	}{
+		{"fast handler passes through", fast, http.StatusCreated, "done", ""},
+		{"slow handler times out", slow, http.StatusServiceUnavailable, "", response.CodeServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.Timeout(50*time.Millisecond, tt.handler)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantCode == "" {
+				if rec.Body.String() != tt.wantBody {
+					t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+				}
+				if got := rec.Header().Get("X-Handler"); got != "fast" {
+					t.Errorf("X-Handler = %q, want %q", got, "fast")
+				}
+				return
+			}
+
+			var envelope response.ErrorEnvelope
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+			}
+			if envelope.Success || envelope.Error.Code != tt.wantCode {
+				t.Errorf("envelope = %+v, want code %s", envelope, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestTimeout_PanicReachesRecover(t *testing.T) {
+	captureDefaultLogger(t)
+	handler := middleware.Recover(middleware.Timeout(time.Second, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestTimeout_PanicAfterDeadlineIsLogged(t *testing.T) {
+	var buf syncBuffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	handler := middleware.Recover(middleware.Timeout(20*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		panic("late boom")
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	got := buf.waitFor(t, "late boom")
+	if !strings.Contains(got, "panic after request timeout") || !strings.Contains(got, `"path":"/slow"`) {
+		t.Errorf("log = %q, want the late panic with the request path", got)
+	}
+}
+
+func TestTimeout_ResponseIsLocalized(t *testing.T) {
+	handler := middleware.Localize(i18n.DefaultCatalog())(middleware.Timeout(20*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("slow") {
+			<-r.Context().Done()
+			return
+		}
+		response.Error(w, http.StatusForbidden, "Access denied")
+	})))
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantCode   response.ErrorCode
+	}{
+		{"handler error", "/", http.StatusForbidden, response.CodeForbidden},
+		{"timeout", "/?slow", http.StatusServiceUnavailable, response.CodeServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Language", "de")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var envelope response.ErrorEnvelope
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+			}
+			want := i18n.DefaultCatalog().Translate("de", string(tt.wantCode), "")
+			if envelope.Error.Code != tt.wantCode || envelope.Error.Message != want {
+				t.Errorf("error = %+v, want code %s with message %q", envelope.Error, tt.wantCode, want)
+			}
+		})
+	}
+}
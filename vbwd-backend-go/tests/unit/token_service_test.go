@@ -0,0 +1,305 @@
+package unit
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+const testJWTSecret = "test-secret"
+
+// signTestToken signs arbitrary claims with the test secret, allowing tests
+// to craft expired or otherwise unusual tokens.
+func signTestToken(t *testing.T, claims services.Claims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("SignedString() unexpected error: %v", err)
+	}
+	return token
+}
+
+func TestTokenService_GenerateAndParse(t *testing.T) {
+	service := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+	user := models.User{ID: "42", Username: "alice"}
+
+	token, err := service.Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	claims, err := service.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if claims.Subject != user.ID {
+		t.Errorf("Subject = %q, want %q", claims.Subject, user.ID)
+	}
+	if claims.Username != user.Username {
+		t.Errorf("Username = %q, want %q", claims.Username, user.Username)
+	}
+	if claims.TokenType != services.TokenTypeAccess {
+		t.Errorf("TokenType = %q, want %q", claims.TokenType, services.TokenTypeAccess)
+	}
+
+	ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time)
+	if ttl != services.DefaultAccessTokenTTL {
+		t.Errorf("token lifetime = %v, want %v", ttl, services.DefaultAccessTokenTTL)
+	}
+	if claims.ExpiresAt.Before(time.Now()) {
+		t.Error("token already expired")
+	}
+}
+
+func TestTokenService_GenerateRefresh(t *testing.T) {
+	service := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+
+	token, err := service.GenerateRefresh(models.User{ID: "42", Username: "alice"})
+	if err != nil {
+		t.Fatalf("GenerateRefresh() unexpected error: %v", err)
+	}
+
+	claims, err := service.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if claims.TokenType != services.TokenTypeRefresh {
+		t.Errorf("TokenType = %q, want %q", claims.TokenType, services.TokenTypeRefresh)
+	}
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != services.DefaultRefreshTokenTTL {
+		t.Errorf("token lifetime = %v, want %v", ttl, services.DefaultRefreshTokenTTL)
+	}
+}
+
+func TestTokenService_Parse_Invalid(t *testing.T) {
+	service := services.NewTokenService(testJWTSecret, services.TokenOptions{})
+
+	foreign, err := services.NewTokenService("other-secret", services.TokenOptions{}).Generate(models.User{ID: "1", Username: "admin"})
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"malformed", "not-a-jwt"},
+		{"wrong secret", foreign},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.Parse(tt.token); !errors.Is(err, models.ErrInvalidToken) {
+				t.Errorf("Parse() error = %v, want %v", err, models.ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func TestTokenService_Options(t *testing.T) {
+	service := services.NewTokenService(testJWTSecret, services.TokenOptions{
+		AccessTTL:  5 * time.Minute,
+		RefreshTTL: 2 * time.Hour,
+	})
+	user := models.User{ID: "42", Username: "alice"}
+
+	access, err := service.Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+	refresh, err := service.GenerateRefresh(user)
+	if err != nil {
+		t.Fatalf("GenerateRefresh() unexpected error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		token string
+		want  time.Duration
+	}{{access, 5 * time.Minute}, {refresh, 2 * time.Hour}} {
+		claims, err := service.Parse(tt.token)
+		if err != nil {
+			t.Fatalf("Parse() unexpected error: %v", err)
+		}
+		if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != tt.want {
+			t.Errorf("token lifetime = %v, want %v", ttl, tt.want)
+		}
+	}
+}
+
+func TestTokenService_Parse_Leeway(t *testing.T) {
+	recentlyExpired := signTestToken(t, services.Claims{
+		Username:  "admin",
+		TokenType: services.TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-10 * time.Second)),
+		},
+	})
+
+	tests := []struct {
+		name    string
+		leeway  time.Duration
+		wantErr bool
+	}{
+		{"no leeway", 0, true},
+		{"within leeway", 30 * time.Second, false},
+		{"beyond leeway", 5 * time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := services.NewTokenService(testJWTSecret, services.TokenOptions{Leeway: tt.leeway})
+
+			_, err := service.Parse(recentlyExpired)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenService_UsesClock(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC))
+	service := services.NewTokenService(testJWTSecret, services.TokenOptions{Clock: clock})
+
+	token, err := service.Generate(models.User{ID: "42", Username: "alice"})
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	claims, err := service.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if !claims.IssuedAt.Time.Equal(clock.Now()) {
+		t.Errorf("IssuedAt = %v, want %v", claims.IssuedAt.Time, clock.Now())
+	}
+
+	clock.Advance(services.DefaultAccessTokenTTL + time.Second)
+	if _, err := service.Parse(token); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("Parse() after expiry error = %v, want %v", err, models.ErrInvalidToken)
+	}
+}
+
+// newTestRSAKey generates an RSA key for RS256 tests.
+func newTestRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() unexpected error: %v", err)
+	}
+	return key
+}
+
+func TestTokenService_RS256(t *testing.T) {
+	key := newTestRSAKey(t)
+	otherKey := newTestRSAKey(t)
+	user := models.User{ID: "42", Username: "alice", Role: models.RoleUser}
+
+	token, err := services.NewRS256TokenService(key, services.TokenOptions{}).Generate(user)
+	if err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		verifier services.TokenService
+		wantErr  bool
+	}{
+		{"matching public key", services.NewRS256Verifier(&key.PublicKey, services.TokenOptions{}), false},
+		{"wrong public key", services.NewRS256Verifier(&otherKey.PublicKey, services.TokenOptions{}), true},
+		{"hmac verifier", services.NewTokenService(testJWTSecret, services.TokenOptions{}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.verifier.Parse(token)
+			if tt.wantErr {
+				if !errors.Is(err, models.ErrInvalidToken) {
+					t.Errorf("Parse() error = %v, want %v", err, models.ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if claims.Subject != user.ID {
+				t.Errorf("Subject = %q, want %q", claims.Subject, user.ID)
+			}
+		})
+	}
+}
+
+func TestTokenService_RS256VerifierCannotSign(t *testing.T) {
+	verifier := services.NewRS256Verifier(&newTestRSAKey(t).PublicKey, services.TokenOptions{})
+
+	if _, err := verifier.Generate(models.User{ID: "1"}); !errors.Is(err, services.ErrSigningKeyUnavailable) {
+		t.Errorf("Generate() error = %v, want %v", err, services.ErrSigningKeyUnavailable)
+	}
+}
+
+func TestTokenService_RS256RejectsHMACToken(t *testing.T) {
+	verifier := services.NewRS256Verifier(&newTestRSAKey(t).PublicKey, services.TokenOptions{})
+	token := signTestToken(t, services.Claims{TokenType: services.TokenTypeAccess})
+
+	if _, err := verifier.Parse(token); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("Parse() error = %v, want %v", err, models.ErrInvalidToken)
+	}
+}
+
+func TestLoadRSAKeys(t *testing.T) {
+	key := newTestRSAKey(t)
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "jwt.pem")
+	publicPath := filepath.Join(dir, "jwt.pub.pem")
+	garbagePath := filepath.Join(dir, "garbage.pem")
+	writeTestFile(t, privatePath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	writeTestFile(t, publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	writeTestFile(t, garbagePath, []byte("not a key"))
+
+	privateKey, err := services.LoadRSAPrivateKey(privatePath)
+	if err != nil {
+		t.Fatalf("LoadRSAPrivateKey() unexpected error: %v", err)
+	}
+	publicKey, err := services.LoadRSAPublicKey(publicPath)
+	if err != nil {
+		t.Fatalf("LoadRSAPublicKey() unexpected error: %v", err)
+	}
+	if !privateKey.Equal(key) || !publicKey.Equal(&key.PublicKey) {
+		t.Error("loaded keys do not match the written key")
+	}
+
+	if _, err := services.LoadRSAPrivateKey(garbagePath); err == nil {
+		t.Error("LoadRSAPrivateKey(garbage) error = nil, want error")
+	}
+	if _, err := services.LoadRSAPublicKey(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("LoadRSAPublicKey(missing) error = nil, want error")
+	}
+}
+
+func writeTestFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/handlers"
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/router"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+// newTestUserHandler creates a UserHandler over n users named user000..
+// whose password fields hold a recognisable fake hash.
+func newTestUserHandler(n int) *handlers.UserHandler {
+	users := make([]models.User, 0, n)
+	for i := 0; i < n; i++ {
+		users = append(users, models.User{
+			ID:       fmt.Sprint(i),
+			Username: fmt.Sprintf("user%03d", i),
+			Password: "$2a$10$fake-hash-must-not-leak",
+			Role:     models.RoleUser,
+		})
+	}
+	return handlers.NewUserHandler(services.NewUserService(repository.NewInMemoryUserRepository(users...), nil, nil))
+}
+
+type userPage struct {
+	Items  []map[string]any `json:"items"`
+	Total  int              `json:"total"`
+	Offset int              `json:"offset"`
+	Limit  int              `json:"limit"`
+}
+
+func TestUserHandler_List_Pagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantOffset int
+		wantLimit  int
+		wantItems  int
+		wantFirst  string
+	}{
+		{name: "defaults", query: "", wantOffset: 0, wantLimit: models.DefaultPageLimit, wantItems: models.DefaultPageLimit, wantFirst: "user000"},
+		{name: "offset and limit", query: "?offset=10&limit=5", wantOffset: 10, wantLimit: 5, wantItems: 5, wantFirst: "user010"},
+		{name: "limit clamped", query: "?limit=1000", wantOffset: 0, wantLimit: models.MaxPageLimit, wantItems: models.MaxPageLimit, wantFirst: "user000"},
+		{name: "offset past end", query: "?offset=500", wantOffset: 500, wantLimit: models.DefaultPageLimit, wantItems: 0},
+	}
+
+	handler := newTestUserHandler(150)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.List(rec, httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			var page userPage
+			if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if page.Total != 150 {
+				t.Errorf("total = %d, want 150", page.Total)
+			}
+			if page.Offset != tt.wantOffset || page.Limit != tt.wantLimit {
+				t.Errorf("offset, limit = %d, %d, want %d, %d", page.Offset, page.Limit, tt.wantOffset, tt.wantLimit)
+			}
+			if len(page.Items) != tt.wantItems {
+				t.Fatalf("len(items) = %d, want %d", len(page.Items), tt.wantItems)
+			}
+			if tt.wantItems > 0 && page.Items[0]["username"] != tt.wantFirst {
+				t.Errorf("items[0].username = %v, want %q", page.Items[0]["username"], tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestUserHandler_List_InvalidParameters(t *testing.T) {
+	for _, query := range []string{"?offset=-1", "?offset=abc", "?limit=0", "?limit=-5"} {
+		rec := httptest.NewRecorder()
+		newTestUserHandler(1).List(rec, httptest.NewRequest(http.MethodGet, "/users"+query, nil))
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("GET /users%s status = %d, want %d", query, rec.Code, http.StatusUnprocessableEntity)
+		}
+	}
+}
+
+func TestUserHandler_List_OmitsPasswords(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestUserHandler(3).List(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	body := rec.Body.String()
+	if strings.Contains(body, "fake-hash") || strings.Contains(body, "password") {
+		t.Errorf("body leaks password data: %s", body)
+	}
+}
+
+func TestRouter_Users_RequiresAdmin(t *testing.T) {
+	handler := newTestRouter()
+	userToken := signTestToken(t, services.Claims{
+		Username:  "alice",
+		Role:      models.RoleUser,
+		TokenType: services.TokenTypeAccess,
+	})
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "anonymous", token: "", wantStatus: http.StatusUnauthorized},
+		{name: "user role", token: userToken, wantStatus: http.StatusForbidden},
+		{name: "admin", token: loginForToken(t, handler, "admin", "password"), wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRouter_DeleteUser(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository(services.DemoUser(), models.User{ID: "2", Username: "alice", Password: "hash", Role: models.RoleUser})
+	deps := newTestDependencies()
+	deps.UserHandler = handlers.NewUserHandler(services.NewUserService(repo, nil, nil))
+	handler := router.NewRouter(deps)
+	token := loginForToken(t, handler, "admin", "password")
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		wantCode   string
+	}{
+		{"success", "2", http.StatusNoContent, ""},
+		{"already deleted", "2", http.StatusNotFound, models.ErrUserNotFound.Code},
+		{"not found", "does-not-exist", http.StatusNotFound, models.ErrUserNotFound.Code},
+		{"self delete", "1", http.StatusConflict, models.ErrCannotDeleteSelf.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/users/"+tt.id, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantCode != "" && !strings.Contains(rec.Body.String(), tt.wantCode) {
+				t.Errorf("body = %s, want code %s", rec.Body.String(), tt.wantCode)
+			}
+		})
+	}
+
+	if _, err := repo.FindByUsername(context.Background(), "alice"); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("FindByUsername(alice) error = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if _, err := repo.FindByUsername(context.Background(), "admin"); err != nil {
+		t.Errorf("FindByUsername(admin) unexpected error: %v", err)
+	}
+}
+
+func TestRouter_DeleteUserRevokesCredentials(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository(services.DemoUser(), models.User{ID: "2", Username: "alice", Password: services.DemoUser().Password, Role: models.RoleUser})
+	sessions := services.NewSessionService(repository.NewInMemorySessionStore(), 0, nil)
+	keys := services.NewAPIKeyService(repository.NewInMemoryAPIKeyStore(), repo, nil)
+	tokenService := services.NewSessionTokenService(services.NewTokenService(testJWTSecret, services.TokenOptions{}), sessions, services.SessionTokenOptions{})
+	tokenService = services.NewAPIKeyTokenService(tokenService, keys)
+	authService := services.NewAuthService(
+		services.WithRepository(repo),
+		services.WithTokenService(tokenService),
+		services.WithSessions(sessions),
+		services.WithLogger(discardLogger()),
+	)
+
+	deps := newTestDependencies()
+	deps.AuthHandler = handlers.NewAuthHandler(authService, nil)
+	deps.UserHandler = handlers.NewUserHandler(services.NewUserService(repo, sessions, keys))
+	deps.APIKeyHandler = handlers.NewAPIKeyHandler(keys)
+	deps.TokenService = tokenService
+	handler := router.NewRouter(deps)
+
+	admin := loginForToken(t, handler, "admin", "password")
+	alice := loginForToken(t, handler, "alice", "password")
+	key := createAPIKey(t, handler, alice)
+
+	if rec := sendWithCredentials(handler, http.MethodDelete, "/users/2", "Authorization", "Bearer "+admin, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d, want %d (body: %s)", rec.Code, http.StatusNoContent, rec.Body.String())
+	}
+
+	if rec := sendWithCredentials(handler, http.MethodGet, "/whoami", "Authorization", "Bearer "+alice, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("token issued before delete: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := sendWithCredentials(handler, http.MethodGet, "/whoami", "X-API-Key", key.Key, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("API key created before delete: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if revoked, err := keys.RevokeAll(context.Background(), "2"); err != nil || revoked != 0 {
+		t.Errorf("RevokeAll() = %d, %v, want the keys already deleted", revoked, err)
+	}
+	if rec := sendWithCredentials(handler, http.MethodGet, "/whoami", "Authorization", "Bearer "+admin, ""); rec.Code != http.StatusOK {
+		t.Errorf("admin token: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dantweb/vbwd-backend-go/internal/models"
+	"github.com/dantweb/vbwd-backend-go/internal/repository"
+	"github.com/dantweb/vbwd-backend-go/internal/services"
+)
+
+func TestInMemoryUserRepository_CreateAndFind(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository()
+	user := models.User{ID: "7", Username: "alice", Password: "hash"}
+
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	found, err := repo.FindByUsername(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("FindByUsername() unexpected error: %v", err)
+	}
+	if *found != user {
+		t.Errorf("FindByUsername() = %+v, want %+v", *found, user)
+	}
+
+	if err := repo.Create(context.Background(), user); !errors.Is(err, models.ErrUserExists) {
+		t.Errorf("Create() duplicate error = %v, want %v", err, models.ErrUserExists)
+	}
+}
+
+func TestInMemoryUserRepository_FindByUsername_NotFound(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository()
+
+	if _, err := repo.FindByUsername(context.Background(), "nobody"); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("FindByUsername() error = %v, want %v", err, models.ErrUserNotFound)
+	}
+}
+
+func TestInMemoryUserRepository_HonorsCancellation(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository(services.DemoUser())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := repo.FindByUsername(ctx, "admin"); !errors.Is(err, context.Canceled) {
+		t.Errorf("FindByUsername() error = %v, want %v", err, context.Canceled)
+	}
+	if err := repo.Create(ctx, models.User{ID: "7", Username: "alice"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Create() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := repo.Count(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Count() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestInMemoryUserRepository_BulkCreate(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository(services.DemoUser())
+	users := []models.User{
+		{ID: "2", Username: "alice", Email: "alice@example.com", Password: "hash"},
+		{ID: "3", Username: "admin", Password: "hash"},
+		{ID: "4", Username: "bob", Password: "hash"},
+		{ID: "5", Username: "alice", Password: "hash"},
+		{ID: "6", Username: "carol", Email: "alice@example.com", Password: "hash"},
+	}
+
+	imported, errs := repo.BulkCreate(context.Background(), users)
+	if imported != 2 {
+		t.Errorf("imported = %d, want 2", imported)
+	}
+
+	wantErrs := []error{models.ErrUserExists, models.ErrUserExists, models.ErrEmailExists}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("errs = %v, want %d errors", errs, len(wantErrs))
+	}
+	for i, want := range wantErrs {
+		if !errors.Is(errs[i], want) {
+			t.Errorf("errs[%d] = %v, want %v", i, errs[i], want)
+		}
+	}
+
+	if total, _ := repo.Count(context.Background()); total != 3 {
+		t.Errorf("total users = %d, want 3", total)
+	}
+}
+
+func TestInMemoryUserRepository_Count(t *testing.T) {
+	repo := repository.NewInMemoryUserRepository(services.DemoUser())
+	ctx := context.Background()
+
+	for _, step := range []struct {
+		name   string
+		change func() error
+		want   int
+	}{
+		{"seeded", func() error { return nil }, 1},
+		{"create", func() error {
+			return repo.Create(ctx, models.User{ID: "7", Username: "alice", Password: "hash"})
+		}, 2},
+		{"delete", func() error { return repo.Delete(ctx, "7") }, 1},
+	} {
+		if err := step.change(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		total, err := repo.Count(ctx)
+		if err != nil {
+			t.Fatalf("%s: Count() unexpected error: %v", step.name, err)
+		}
+		if total != step.want {
+			t.Errorf("%s: Count() = %d, want %d", step.name, total, step.want)
+		}
+	}
+}
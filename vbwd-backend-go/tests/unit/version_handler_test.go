@@ -0,0 +1,38 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouter_Version(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	want := map[string]string{
+		"version":    "1.2.3",
+		"commit":     "abc1234",
+		"build_time": "2026-01-18T12:00:00Z",
+	}
+	for key, value := range want {
+		if body[key] != value {
+			t.Errorf("%s = %q, want %q", key, body[key], value)
+		}
+	}
+	if body["go_version"] == "" {
+		t.Error("go_version is empty")
+	}
+	if len(body) != 4 {
+		t.Errorf("body has %d fields, want 4: %v", len(body), body)
+	}
+}